@@ -20,6 +20,10 @@ import (
 	"well_go/internal/core/runtime"
 	"well_go/internal/core/snowflake"
 	"well_go/internal/middleware"
+	"well_go/internal/model"
+	"well_go/internal/pkg/jwtkey"
+	"well_go/internal/pkg/pool"
+	"well_go/internal/pkg/util"
 	"well_go/internal/repository"
 	"well_go/internal/service"
 	"well_go/internal/service/seo"
@@ -62,28 +66,88 @@ func main() {
 
 	// 5. 初始化缓存配置
 	cacheConfig := &config.CacheConfig{
-		L1Cap: cfg.Cache.L1Cap,
-		L2TTL: cfg.Cache.L2TTL,
+		L1Cap:       cfg.Cache.L1Cap,
+		L2TTL:       cfg.Cache.L2TTL,
+		BusChannel:  cfg.Cache.BusChannel,
+		NegativeTTL: cfg.Cache.NegativeTTL,
+		TTLJitter:   cfg.Cache.TTLJitter,
 	}
 
+	// L1 跨实例失效广播（多副本部署时各节点 L1 一致）
+	nodeID, _ := util.GenerateRandomString(8)
+	l1Bus := pool.InitBus(redisClient, cacheConfig.BusChannel, nodeID)
+	busCtx, busCancel := context.WithCancel(context.Background())
+	defer busCancel()
+	go l1Bus.Run(busCtx, func(err error) {
+		logger.Warn("l1 bus message invalid", logger.String("error", err.Error()))
+	})
+	logger.Info("L1 invalidation bus started", logger.String("node", nodeID), logger.String("channel", cacheConfig.BusChannel))
+
 	// 6. 初始化 Snowflake
 	if err := snowflake.Init(&cfg.Snowflake); err != nil {
 		logger.Error("Failed to init snowflake", logger.String("error", err.Error()))
 		os.Exit(1)
 	}
 
+	// JWT 签名密钥（启动时校验算法与密钥匹配）
+	jwtKeys, err := jwtkey.Load(jwtkey.Options{
+		Algorithm:  cfg.JWT.Algorithm,
+		Secret:     cfg.JWT.Secret,
+		KeyID:      cfg.JWT.KeyID,
+		PrivateKey: cfg.JWT.PrivateKey,
+		PublicKeys: cfg.JWT.PublicKeys,
+	})
+	if err != nil {
+		logger.Error("Failed to load jwt keys", logger.String("error", err.Error()))
+		os.Exit(1)
+	}
+	logger.Info("JWT keys loaded", logger.String("alg", jwtKeys.Algorithm()), logger.String("kid", cfg.JWT.KeyID))
+
 	// 7. 初始化 Repository
 	threadRepo := repository.NewThreadRepository(database.Get())
 	forumRepo := repository.NewForumRepository(database.Get())
 	tagRepo := repository.NewTagRepository(database.Get())
 	threadTagRepo := repository.NewThreadTagRepository(database.Get())
 	userRepo := repository.NewUserRepository(database.Get())
+	postRepo := repository.NewPostRepository(database.Get())
+	forumAccessRepo := repository.NewForumAccessRepository(database.Get())
+	threadRevisionRepo := repository.NewThreadRevisionRepository(database.Get())
+	roleRepo := repository.NewRoleRepository(database.Get())
+	auditRepo := repository.NewAuditLogRepository(database.Get())
 
 	// 8. 初始化 Service
-	threadSvc := service.NewThreadService(threadRepo, redisClient, cacheConfig)
-	forumSvc := service.NewForumService(forumRepo, redisClient, cacheConfig)
-	tagSvc := service.NewTagService(tagRepo, threadTagRepo, redisClient, cacheConfig)
-	userSvc := service.NewUserService(userRepo, redisClient, cacheConfig, &cfg.JWT)
+	threadSvc := service.NewThreadService(threadRepo, threadRevisionRepo, threadTagRepo, tagRepo, redisClient, cacheConfig)
+	forumSvc := service.NewForumService(forumRepo, forumAccessRepo, redisClient, cacheConfig)
+	tagSvc := service.NewTagService(tagRepo, threadRepo, threadTagRepo, repository.NewTagAliasRepository(database.Get()), redisClient, cacheConfig)
+	tokenSvc := service.NewTokenService(userRepo, redisClient, &cfg.JWT, jwtKeys)
+	auditSvc := service.NewAuditService(auditRepo)
+	loginGuard := service.NewLoginGuard(redisClient, &cfg.Login, auditSvc)
+	userSvc := service.NewUserService(userRepo, redisClient, cacheConfig, tokenSvc, loginGuard)
+	rbacSvc := service.NewRBACService(roleRepo, userRepo, forumRepo, redisClient, cacheConfig)
+	postSvc := service.NewPostService(postRepo, threadRepo, threadSvc, forumSvc, redisClient, cacheConfig)
+	searchSvc := service.NewSearchService(threadRepo, threadTagRepo, redisClient, nodeID)
+	threadSvc.SetIndexer(searchSvc)
+	trashSvc := service.NewTrashService(threadRepo, forumRepo, tagRepo, threadSvc, forumSvc, tagSvc,
+		time.Duration(cfg.Trash.RetentionDays)*24*time.Hour)
+	go trashSvc.RunPurger(busCtx, time.Duration(cfg.Trash.PurgeInterval)*time.Second)
+
+	// 相关标签定时重算
+	tagSvc.SetRelatedInterval(time.Duration(cfg.Tag.RelatedInterval) * time.Second)
+	go tagSvc.RunRelatedRefresher(busCtx, time.Duration(cfg.Tag.RelatedInterval)*time.Second)
+
+	// 搜索索引：订阅其它实例的增量变更，后台全量构建
+	go searchSvc.Run(busCtx, func(err error) {
+		logger.Warn("search sync failed", logger.String("error", err.Error()))
+	})
+	go func() {
+		start := time.Now()
+		n, err := searchSvc.Rebuild(busCtx)
+		if err != nil {
+			logger.Error("Failed to build search index", logger.String("error", err.Error()))
+			return
+		}
+		logger.Info("Search index built", logger.Int("docs", n), logger.Duration("cost", time.Since(start)))
+	}()
 
 	// 9. Runtime 预热
 	rtConfig := &runtime.RuntimeConfig{
@@ -94,20 +158,35 @@ func main() {
 		logger.Error("Failed to init runtime", logger.String("error", err.Error()))
 	}
 	logger.Info("Runtime warmup: " + runtime.WarmUpLog())
+	// 自动补全标签列表定时刷新
+	go runtime.Get().RunTagRefresher(busCtx, tagSvc, time.Duration(cfg.Tag.SuggestRefresh)*time.Second)
 
 	// 10. 初始化 Handler
 	threadV1Handler := v1.NewThreadHandler(threadSvc, tagSvc, userSvc)
 	threadMgtHandler := mgt.NewThreadHandler(threadSvc, tagSvc)
 	cacheMgtHandler := mgt.NewCacheHandler(threadSvc)
 
+	postV1Handler := v1.NewPostHandler(postSvc, threadSvc, userSvc)
+	postMgtHandler := mgt.NewPostMgtHandler(postSvc, threadSvc)
+
 	forumV1Handler := v1.NewForumHandler(forumSvc)
 	forumMgtHandler := mgt.NewForumMgtHandler(forumSvc)
 
-	tagV1Handler := v1.NewTagHandler(tagSvc)
+	tagV1Handler := v1.NewTagHandler(tagSvc, threadSvc, userSvc)
 	tagMgtHandler := mgt.NewTagMgtHandler(tagSvc)
 
 	userV1Handler := v1.NewUserHandler(userSvc)
+	searchV1Handler := v1.NewSearchHandler(searchSvc, userSvc)
+	searchMgtHandler := mgt.NewSearchMgtHandler(searchSvc)
 	userMgtHandler := mgt.NewUserMgtHandler(userSvc)
+	authMgtHandler := mgt.NewAuthMgtHandler(userSvc, tokenSvc, loginGuard)
+	auditMgtHandler := mgt.NewAuditMgtHandler(auditSvc)
+	trashMgtHandler := mgt.NewTrashMgtHandler(trashSvc)
+	roleMgtHandler := mgt.NewRoleMgtHandler(rbacSvc)
+
+	// 管理接口权限校验（JWTMW 之后）
+	authz := middleware.NewAuthorizer(rbacSvc)
+	jwtMW := middleware.JWTMW(jwtKeys, tokenSvc)
 
 	// 11. SEO 服务初始化
 	baseURL := cfg.App.BaseURL
@@ -129,6 +208,37 @@ func main() {
 	robotsSvc := seo.NewRobotsService(robotsConfig)
 	canonicalSvc := seo.NewCanonicalService(baseURL)
 
+	// 主题上下线回调：版块计数缓存、sitemap、IndexNow（仅在发布那一刻触发）
+	threadSvc.AddPublishHook(forumSvc.OnThreadPublish)
+	threadSvc.AddForumHook(forumSvc.OnCountersChanged)
+	tagSvc.AddTagHook(threadSvc.OnThreadTagChange)
+	threadSvc.AddPublishHook(func(ctx context.Context, thread *model.Thread, published bool) {
+		sitemapSvc.Invalidate()
+	})
+	if cfg.SEO.IndexNowKey != "" {
+		indexNowSvc := seo.NewIndexNowService(&seo.IndexNowConfig{
+			Key:         cfg.SEO.IndexNowKey,
+			KeyLocation: baseURL + "/" + cfg.SEO.IndexNowKey + ".txt",
+			Endpoint:    cfg.SEO.IndexNowEndpoint,
+			RedisKey:    "seo:indexnow",
+			RedisTTL:    24 * time.Hour,
+		}, redisClient)
+		threadSvc.AddPublishHook(func(ctx context.Context, thread *model.Thread, published bool) {
+			if published {
+				indexNowSvc.AsyncSubmit([]string{fmt.Sprintf("%s/thread/%d", baseURL, thread.Tid)})
+			}
+		})
+	}
+
+	// 定时发布调度
+	go threadSvc.RunScheduler(busCtx, time.Duration(cfg.Thread.PublishInterval)*time.Second)
+
+	// 浏览量缓冲，定时落库
+	threadSvc.SetViewDedupWindow(time.Duration(cfg.Thread.ViewDedupWindow) * time.Second)
+	go threadSvc.RunViewFlusher(busCtx, time.Duration(cfg.Thread.ViewFlushInterval)*time.Second)
+	tagSvc.SetViewDedupWindow(time.Duration(cfg.Thread.ViewDedupWindow) * time.Second)
+	go tagSvc.RunViewFlusher(busCtx, time.Duration(cfg.Thread.ViewFlushInterval)*time.Second)
+
 	// SEO Handlers
 	sitemapHandler := seo.NewHandler(sitemapSvc)
 	robotsHandler := seo.NewRobotsHandler(robotsSvc)
@@ -139,6 +249,11 @@ func main() {
 	// 12. 注册路由
 	gin.SetMode(cfg.App.Mode)
 	router := gin.New()
+	// ClientIP 用于登录失败计数与限流：只信任配置的代理转发的客户端地址
+	if err := router.SetTrustedProxies(cfg.App.TrustedProxies); err != nil {
+		logger.Error("Invalid trusted proxies", logger.String("error", err.Error()))
+		os.Exit(1)
+	}
 
 	// Middleware
 	router.Use(middleware.RecoveryMiddleware())
@@ -217,6 +332,12 @@ func main() {
 	// Metrics (跳过 IP 检查)
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
+	// JWKS：供其它服务验证 access token（跳过 IP 检查）
+	router.GET("/.well-known/jwks.json", func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, jwtKeys.JWKS())
+	})
+
 	// SEO Routes (跳过 IP 检查)
 	router.GET("/robots.txt", robotsHandler.Get)
 	router.GET("/sitemap.xml", sitemapHandler.SitemapIndex)
@@ -226,10 +347,14 @@ func main() {
 	// Public API (v1) - Public 白名单（本地/内网跳过）
 	v1Group := router.Group("/api/v1")
 	v1Group.Use(middleware.PublicWhitelistMW())
+	v1Group.Use(middleware.OptionalJWTMW(jwtKeys, tokenSvc))
 	{
 		// Thread
 		v1Group.GET("/threads", threadV1Handler.List)
+		v1Group.GET("/feed", threadV1Handler.Feed)
 		v1Group.GET("/thread/:tid", threadV1Handler.Get)
+		v1Group.GET("/thread/:tid/related", threadV1Handler.Related)
+		v1Group.GET("/thread/:tid/posts", postV1Handler.List)
 
 		// Forum
 		v1Group.GET("/forums", forumV1Handler.List)
@@ -239,56 +364,127 @@ func main() {
 		// Tag
 		v1Group.GET("/tags", tagV1Handler.List)
 		v1Group.GET("/tags/hot", tagV1Handler.Hot)
+		v1Group.GET("/tags/suggest", tagV1Handler.Suggest)
 		v1Group.GET("/tags/thread/:tid", tagV1Handler.GetByThread)
+		v1Group.GET("/tag/:slug", tagV1Handler.Get)
+		// gin 要求同一位置的参数同名：此处 :slug 实为标签 ID
+		v1Group.GET("/tag/:slug/related", tagV1Handler.Related)
 
 		// User
 		v1Group.GET("/user/:uid", userV1Handler.GetUser)
+
+		// Search
+		v1Group.GET("/search", searchV1Handler.Search)
 	}
 
 	// Management API (mgt) - 强制 IP 白名单
 	mgtGroup := router.Group("/api/mgt")
 	mgtGroup.Use(middleware.AdminWhitelistMW())
 	{
-		mgtGroup.POST("/login", func(c *gin.Context) {
-			mgt.Login(c, userSvc, &cfg.JWT)
-		})
+		mgtGroup.POST("/login", authMgtHandler.Login)
+		mgtGroup.POST("/token/refresh", authMgtHandler.Refresh)
+		mgtGroup.POST("/logout", jwtMW, authMgtHandler.Logout)
+		mgtGroup.POST("/token/revoke-all", jwtMW, authMgtHandler.RevokeAll)
 
 		userMgt := mgtGroup.Group("/user")
-		userMgt.Use(middleware.JWTMW(&cfg.JWT))
+		userMgt.Use(jwtMW)
 		{
 			userMgt.GET("/profile", userMgtHandler.GetProfile)
+			userMgt.GET("/:uid/roles", authz.RequirePermission(model.PermRoleManage), roleMgtHandler.UserRoles)
+			userMgt.POST("/:uid/roles", authz.RequirePermission(model.PermRoleManage), roleMgtHandler.Assign)
+			userMgt.DELETE("/:uid/roles/:role_id", authz.RequirePermission(model.PermRoleManage), roleMgtHandler.Revoke)
+			userMgt.POST("/:uid/sessions/revoke", authz.RequirePermission(model.PermUserManage), authMgtHandler.RevokeUser)
+			userMgt.POST("/unlock", authz.RequirePermission(model.PermUserManage), authMgtHandler.Unlock)
 		}
 
 		// 注册不需要JWT
 		mgtGroup.POST("/user/register", userMgtHandler.Register)
 
 		threadMgt := mgtGroup.Group("/thread")
-		threadMgt.Use(middleware.JWTMW(&cfg.JWT))
+		threadMgt.Use(jwtMW)
 		{
-			threadMgt.POST("", threadMgtHandler.Create)
-			threadMgt.PUT("/:tid", threadMgtHandler.Update)
-			threadMgt.DELETE("/:tid", threadMgtHandler.Delete)
+			threadMgt.POST("", authz.RequirePermission(model.PermThreadCreate), threadMgtHandler.Create)
+			threadMgt.POST("/move", authz.RequirePermission(model.PermThreadMove), threadMgtHandler.BulkMove)
+			threadMgt.PUT("/:tid", authz.RequirePermission(model.PermThreadUpdate), threadMgtHandler.Update)
+			threadMgt.DELETE("/:tid", authz.RequirePermission(model.PermThreadDelete), threadMgtHandler.Delete)
+			threadMgt.POST("/:tid/publish", authz.RequirePermission(model.PermThreadPublish), threadMgtHandler.Publish)
+			threadMgt.POST("/:tid/move", authz.RequirePermission(model.PermThreadMove), threadMgtHandler.Move)
+			threadMgt.GET("/:tid/revisions", authz.RequirePermission(model.PermThreadUpdate), threadMgtHandler.Revisions)
+			threadMgt.GET("/:tid/revisions/diff", authz.RequirePermission(model.PermThreadUpdate), threadMgtHandler.RevisionDiff)
+			threadMgt.GET("/:tid/revisions/:rev", authz.RequirePermission(model.PermThreadUpdate), threadMgtHandler.Revision)
+			threadMgt.POST("/:tid/revisions/:rev/rollback", authz.RequirePermission(model.PermThreadUpdate), threadMgtHandler.Rollback)
+		}
+
+		postMgt := mgtGroup.Group("/post")
+		postMgt.Use(jwtMW)
+		{
+			postMgt.POST("", authz.RequirePermission(model.PermPostCreate), postMgtHandler.Create)
+			postMgt.PUT("/:pid", authz.RequirePermission(model.PermPostUpdate), postMgtHandler.Update)
+			postMgt.DELETE("/:pid", authz.RequirePermission(model.PermPostDelete), postMgtHandler.Delete)
 		}
 
 		forumMgt := mgtGroup.Group("/forum")
-		forumMgt.Use(middleware.JWTMW(&cfg.JWT))
+		forumMgt.Use(jwtMW)
 		{
-			forumMgt.POST("", forumMgtHandler.Create)
-			forumMgt.PUT("/:fid", forumMgtHandler.Update)
-			forumMgt.DELETE("/:fid", forumMgtHandler.Delete)
+			forumMgt.POST("", authz.RequirePermission(model.PermForumCreate), forumMgtHandler.Create)
+			forumMgt.PUT("/order", authz.RequirePermission(model.PermForumUpdate), forumMgtHandler.Reorder)
+			forumMgt.PUT("/:fid", authz.RequirePermission(model.PermForumUpdate), forumMgtHandler.Update)
+			forumMgt.POST("/:fid/move", authz.RequirePermission(model.PermForumUpdate), forumMgtHandler.Move)
+			forumMgt.DELETE("/:fid", authz.RequirePermission(model.PermForumDelete), forumMgtHandler.Delete)
+			forumMgt.GET("/:fid/access", authz.RequirePermission(model.PermForumAccess), forumMgtHandler.GetAccess)
+			forumMgt.PUT("/:fid/access", authz.RequirePermission(model.PermForumAccess), forumMgtHandler.SetAccess)
+			forumMgt.GET("/:fid/moderators", authz.RequirePermission(model.PermRoleManage), roleMgtHandler.Moderators)
 		}
 
 		tagMgt := mgtGroup.Group("/tag")
-		tagMgt.Use(middleware.JWTMW(&cfg.JWT))
+		tagMgt.Use(jwtMW)
+		{
+			tagMgt.POST("", authz.RequirePermission(model.PermTagCreate), tagMgtHandler.Create)
+			tagMgt.DELETE("/:tag_id", authz.RequirePermission(model.PermTagDelete), tagMgtHandler.Delete)
+			tagMgt.PUT("/:tag_id/slug", authz.RequirePermission(model.PermTagUpdate), tagMgtHandler.UpdateSlug)
+			tagMgt.PUT("/:tag_id", authz.RequirePermission(model.PermTagUpdate), tagMgtHandler.Rename)
+			tagMgt.POST("/:tag_id/merge", authz.RequirePermission(model.PermTagUpdate), tagMgtHandler.Merge)
+			tagMgt.GET("/:tag_id/aliases", authz.RequirePermission(model.PermTagUpdate), tagMgtHandler.Aliases)
+			tagMgt.POST("/:tag_id/aliases", authz.RequirePermission(model.PermTagUpdate), tagMgtHandler.AddAlias)
+			tagMgt.DELETE("/:tag_id/aliases/:alias", authz.RequirePermission(model.PermTagUpdate), tagMgtHandler.RemoveAlias)
+		}
+
+		roleMgt := mgtGroup.Group("/role")
+		roleMgt.Use(jwtMW, authz.RequirePermission(model.PermRoleManage))
+		{
+			roleMgt.GET("", roleMgtHandler.List)
+			roleMgt.POST("", roleMgtHandler.Create)
+			roleMgt.PUT("/:role_id", roleMgtHandler.Update)
+			roleMgt.DELETE("/:role_id", roleMgtHandler.Delete)
+		}
+
+		trashMgt := mgtGroup.Group("/trash")
+		trashMgt.Use(jwtMW)
+		{
+			trashMgt.GET("/:type", authz.RequirePermission(model.PermTrashManage), trashMgtHandler.List)
+			trashMgt.POST("/purge", authz.RequirePermission(model.PermTrashManage), trashMgtHandler.PurgeExpired)
+			trashMgt.POST("/:type/:id/restore", authz.RequirePermission(model.PermTrashManage), trashMgtHandler.Restore)
+			trashMgt.DELETE("/:type/:id", authz.RequirePermission(model.PermTrashManage), trashMgtHandler.Purge)
+		}
+
+		searchMgt := mgtGroup.Group("/search")
+		searchMgt.Use(jwtMW)
 		{
-			tagMgt.POST("", tagMgtHandler.Create)
+			searchMgt.POST("/rebuild", authz.RequirePermission(model.PermSearchManage), searchMgtHandler.Rebuild)
+			searchMgt.GET("/status", authz.RequirePermission(model.PermSearchManage), searchMgtHandler.Status)
 		}
 
 		cacheMgt := mgtGroup.Group("/cache")
-		cacheMgt.Use(middleware.JWTMW(&cfg.JWT))
+		cacheMgt.Use(jwtMW)
+		{
+			cacheMgt.POST("/flush", authz.RequirePermission(model.PermCacheManage), cacheMgtHandler.Flush)
+			cacheMgt.POST("/prewarm", authz.RequirePermission(model.PermCacheManage), cacheMgtHandler.Prewarm)
+		}
+
+		auditMgt := mgtGroup.Group("/audit")
+		auditMgt.Use(jwtMW, authz.RequirePermission(model.PermUserManage))
 		{
-			cacheMgt.POST("/flush", cacheMgtHandler.Flush)
-			cacheMgt.POST("/prewarm", cacheMgtHandler.Prewarm)
+			auditMgt.GET("", auditMgtHandler.List)
 		}
 	}
 
@@ -333,7 +529,8 @@ func main() {
 	// 3. 关闭数据库连接
 	database.Close()
 
-	// 4. 关闭 Redis 连接
+	// 4. 停止 L1 失效订阅并关闭 Redis 连接
+	busCancel()
 	redisClient.Close()
 
 	// 5. 刷新日志
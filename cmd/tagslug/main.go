@@ -0,0 +1,69 @@
+// tagslug 为 slug 为空的历史标签回填拼音 slug
+//
+//	go run ./cmd/tagslug
+//
+// 与 API 服务读取同一份 config.yaml；可重复执行，已有 slug 的标签不受影响
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"well_go/internal/core/config"
+	"well_go/internal/core/database"
+	"well_go/internal/pkg/pool"
+	"well_go/internal/pkg/util"
+	"well_go/internal/repository"
+	"well_go/internal/service"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func main() {
+	if err := config.Init("."); err != nil {
+		fmt.Printf("Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+	cfg := config.Get()
+
+	if err := database.Init(&cfg.Database); err != nil {
+		fmt.Printf("Failed to init database: %v\n", err)
+		os.Exit(1)
+	}
+	defer database.Close()
+
+	// 回填后需要清除 Redis 及各实例 L1 中的标签缓存
+	redisClient := redis.NewClient(&redis.Options{
+		Addr:     cfg.Redis.GetRedisAddr(),
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+	defer redisClient.Close()
+
+	cacheConfig := &config.CacheConfig{
+		L1Cap:       cfg.Cache.L1Cap,
+		L2TTL:       cfg.Cache.L2TTL,
+		BusChannel:  cfg.Cache.BusChannel,
+		NegativeTTL: cfg.Cache.NegativeTTL,
+		TTLJitter:   cfg.Cache.TTLJitter,
+	}
+
+	// 运行中的 API 实例 L1 里也有旧的标签缓存：通过失效总线广播（本进程只发布不订阅）
+	nodeID, _ := util.GenerateRandomString(8)
+	pool.InitBus(redisClient, cacheConfig.BusChannel, "tagslug-"+nodeID)
+
+	tagSvc := service.NewTagService(
+		repository.NewTagRepository(database.Get()),
+		repository.NewThreadRepository(database.Get()),
+		repository.NewThreadTagRepository(database.Get()),
+		repository.NewTagAliasRepository(database.Get()),
+		redisClient, cacheConfig)
+
+	n, err := tagSvc.BackfillSlugs(context.Background())
+	if err != nil {
+		fmt.Printf("Backfill failed after %d tags: %v\n", n, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Backfilled slugs for %d tags\n", n)
+}
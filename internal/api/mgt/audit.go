@@ -0,0 +1,50 @@
+package mgt
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"well_go/internal/pkg/response"
+	"well_go/internal/service"
+)
+
+// AuditMgtHandler Audit Log Management API Handler
+type AuditMgtHandler struct {
+	svc *service.AuditService
+}
+
+// NewAuditMgtHandler 创建 AuditMgtHandler
+func NewAuditMgtHandler(svc *service.AuditService) *AuditMgtHandler {
+	return &AuditMgtHandler{svc: svc}
+}
+
+// List GET /api/mgt/audit?action=login.lockout
+func (h *AuditMgtHandler) List(c *gin.Context) {
+	page := 1
+	pageSize := 20
+
+	if p := c.Query("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+
+	if ps := c.Query("page_size"); ps != "" {
+		if parsed, err := strconv.Atoi(ps); err == nil && parsed > 0 && parsed <= 100 {
+			pageSize = parsed
+		}
+	}
+
+	list, total, err := h.svc.List(c.Request.Context(), c.Query("action"), page, pageSize)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"list":      list,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
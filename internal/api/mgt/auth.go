@@ -1,11 +1,11 @@
 package mgt
 
 import (
-	"time"
+	"errors"
+	"math"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
-	"well_go/internal/core/config"
 	"well_go/internal/pkg/response"
 	"well_go/internal/service"
 )
@@ -16,13 +16,31 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
-// LoginResponse 登录响应
-type LoginResponse struct {
-	Token string `json:"token"`
+// RefreshRequest 刷新令牌请求
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// UnlockRequest 解除登录锁定请求，username 与 ip 至少给出一个
+type UnlockRequest struct {
+	Username string `json:"username"`
+	IP       string `json:"ip"`
+}
+
+// AuthMgtHandler 登录、令牌刷新与注销
+type AuthMgtHandler struct {
+	userSvc  *service.UserService
+	tokenSvc *service.TokenService
+	guard    *service.LoginGuard
+}
+
+// NewAuthMgtHandler 创建 AuthMgtHandler
+func NewAuthMgtHandler(userSvc *service.UserService, tokenSvc *service.TokenService, guard *service.LoginGuard) *AuthMgtHandler {
+	return &AuthMgtHandler{userSvc: userSvc, tokenSvc: tokenSvc, guard: guard}
 }
 
 // Login POST /api/mgt/login
-func Login(c *gin.Context, userSvc *service.UserService, cfg *config.JWTConfig) {
+func (h *AuthMgtHandler) Login(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		response.BadRequest(c, err.Error())
@@ -30,7 +48,13 @@ func Login(c *gin.Context, userSvc *service.UserService, cfg *config.JWTConfig)
 	}
 
 	// 调用UserService进行真实验证
-	resp, err := userSvc.Login(c.Request.Context(), req.Username, req.Password)
+	resp, err := h.userSvc.Login(c.Request.Context(), req.Username, req.Password, c.ClientIP())
+	var throttled *service.LoginThrottledError
+	if errors.As(err, &throttled) {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+		response.FailWithCode(c, 429, err.Error())
+		return
+	}
 	if err != nil {
 		response.FailWithCode(c, 401, err.Error())
 		return
@@ -39,14 +63,84 @@ func Login(c *gin.Context, userSvc *service.UserService, cfg *config.JWTConfig)
 	response.Success(c, resp)
 }
 
-// generateJWT 生成JWT
-func generateJWT(uid int64, role int, cfg *config.JWTConfig) (string, error) {
-	claims := jwt.MapClaims{
-		"uid":      uid,
-		"username": "",
-		"role":     role,
-		"exp":      time.Now().Add(time.Duration(cfg.Expiry) * time.Second).Unix(),
+// Refresh POST /api/mgt/token/refresh
+// 用 refresh token 换取新的令牌对，旧 refresh token 随即作废
+func (h *AuthMgtHandler) Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	pair, err := h.tokenSvc.Refresh(c.Request.Context(), req.RefreshToken)
+	if errors.Is(err, service.ErrInvalidRefreshToken) || errors.Is(err, service.ErrRefreshTokenReused) ||
+		errors.Is(err, service.ErrUserDisabled) {
+		response.FailWithCode(c, 401, err.Error())
+		return
+	}
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, pair)
+}
+
+// Logout POST /api/mgt/logout
+// 注销当前会话，当前 access token 同时加入黑名单
+func (h *AuthMgtHandler) Logout(c *gin.Context) {
+	if err := h.tokenSvc.Logout(c.Request.Context(), GetUIDFromContext(c), c.GetString("sid"), c.GetString("jti"), c.GetInt64("exp")); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c, nil)
+}
+
+// RevokeAll POST /api/mgt/token/revoke-all
+// 撤销当前用户的全部会话（含当前会话）
+func (h *AuthMgtHandler) RevokeAll(c *gin.Context) {
+	n, err := h.tokenSvc.RevokeAll(c.Request.Context(), GetUIDFromContext(c))
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c, gin.H{"revoked": n})
+}
+
+// RevokeUser POST /api/mgt/user/:uid/sessions/revoke
+// 管理员强制下线指定用户
+func (h *AuthMgtHandler) RevokeUser(c *gin.Context) {
+	uid, err := strconv.ParseInt(c.Param("uid"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid uid")
+		return
+	}
+
+	n, err := h.tokenSvc.RevokeAll(c.Request.Context(), uid)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c, gin.H{"revoked": n})
+}
+
+// Unlock POST /api/mgt/user/unlock
+// 解除用户名或 IP 的登录锁定（同时清除失败计数）
+func (h *AuthMgtHandler) Unlock(c *gin.Context) {
+	var req UnlockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if req.Username == "" && req.IP == "" {
+		response.BadRequest(c, "username or ip required")
+		return
+	}
+
+	n, err := h.guard.Unlock(c.Request.Context(), GetUIDFromContext(c), req.Username, req.IP, c.ClientIP())
+	if err != nil {
+		response.Fail(c, err)
+		return
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(cfg.Secret))
+	response.Success(c, gin.H{"unlocked": n})
 }
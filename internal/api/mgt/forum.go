@@ -1,9 +1,11 @@
 package mgt
 
 import (
+	"errors"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"well_go/internal/core/runtime"
 	"well_go/internal/pkg/response"
 	"well_go/internal/service"
 )
@@ -37,6 +39,7 @@ func (h *ForumMgtHandler) Create(c *gin.Context) {
 		response.Fail(c, err)
 		return
 	}
+	runtime.Get().ForumsChanged(c.Request.Context())
 
 	response.Success(c, dto)
 }
@@ -66,6 +69,74 @@ func (h *ForumMgtHandler) Update(c *gin.Context) {
 		response.Fail(c, err)
 		return
 	}
+	runtime.Get().ForumsChanged(c.Request.Context())
+
+	response.Success(c, nil)
+}
+
+// MoveForumRequest 移动版块请求
+type MoveForumRequest struct {
+	Parent int  `json:"parent"` // 0 表示移为一级版块
+	Order  *int `json:"order"`  // 省略时保持原排序值
+}
+
+// Move POST /api/mgt/forum/:fid/move
+// 连同子版块一起移动，path/depth 在同一事务中重算
+func (h *ForumMgtHandler) Move(c *gin.Context) {
+	fid, err := strconv.Atoi(c.Param("fid"))
+	if err != nil {
+		response.BadRequest(c, "invalid fid")
+		return
+	}
+
+	var req MoveForumRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if err := h.svc.Move(c.Request.Context(), fid, req.Parent, req.Order); err != nil {
+		if errors.Is(err, service.ErrForumCycle) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrForumNotFound) {
+			response.NotFound(c, err.Error())
+			return
+		}
+		response.Fail(c, err)
+		return
+	}
+	runtime.Get().ForumsChanged(c.Request.Context())
+
+	response.Success(c, nil)
+}
+
+// ReorderRequest 批量排序请求
+type ReorderRequest struct {
+	Orders []struct {
+		Fid   int `json:"fid" binding:"required"`
+		Order int `json:"order"`
+	} `json:"orders" binding:"required,dive"`
+}
+
+// Reorder PUT /api/mgt/forum/order
+func (h *ForumMgtHandler) Reorder(c *gin.Context) {
+	var req ReorderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	orders := make(map[int]int, len(req.Orders))
+	for _, o := range req.Orders {
+		orders[o.Fid] = o.Order
+	}
+	if err := h.svc.Reorder(c.Request.Context(), orders); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	runtime.Get().ForumsChanged(c.Request.Context())
 
 	response.Success(c, nil)
 }
@@ -80,9 +151,63 @@ func (h *ForumMgtHandler) Delete(c *gin.Context) {
 	}
 
 	if err := h.svc.Delete(c.Request.Context(), fid); err != nil {
+		if errors.Is(err, service.ErrForumNotEmpty) || errors.Is(err, service.ErrForumHasChildren) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrForumNotFound) {
+			response.NotFound(c, err.Error())
+			return
+		}
+		response.Fail(c, err)
+		return
+	}
+	runtime.Get().ForumsChanged(c.Request.Context())
+
+	response.Success(c, nil)
+}
+
+// GetAccess GET /api/mgt/forum/:fid/access
+func (h *ForumMgtHandler) GetAccess(c *gin.Context) {
+	fid, err := strconv.Atoi(c.Param("fid"))
+	if err != nil {
+		response.BadRequest(c, "invalid fid")
+		return
+	}
+
+	list, err := h.svc.GetAccess(c.Request.Context(), fid)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, list)
+}
+
+// SetAccessRequest 设置版块权限请求（空列表表示取消限制）
+type SetAccessRequest struct {
+	Access []*service.ForumAccessDTO `json:"access"`
+}
+
+// SetAccess PUT /api/mgt/forum/:fid/access
+func (h *ForumMgtHandler) SetAccess(c *gin.Context) {
+	fid, err := strconv.Atoi(c.Param("fid"))
+	if err != nil {
+		response.BadRequest(c, "invalid fid")
+		return
+	}
+
+	var req SetAccessRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if err := h.svc.SaveAccess(c.Request.Context(), fid, req.Access); err != nil {
 		response.Fail(c, err)
 		return
 	}
+	runtime.Get().ForumAccessChanged(c.Request.Context(), fid, req.Access)
 
 	response.Success(c, nil)
 }
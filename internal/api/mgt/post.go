@@ -0,0 +1,146 @@
+package mgt
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"well_go/internal/core/runtime"
+	"well_go/internal/model"
+	"well_go/internal/pkg/response"
+	"well_go/internal/service"
+)
+
+// PostMgtHandler Post Management API Handler
+type PostMgtHandler struct {
+	svc       *service.PostService
+	threadSvc *service.ThreadService
+}
+
+// NewPostMgtHandler 创建 PostMgtHandler
+func NewPostMgtHandler(svc *service.PostService, threadSvc *service.ThreadService) *PostMgtHandler {
+	return &PostMgtHandler{svc: svc, threadSvc: threadSvc}
+}
+
+// CreatePostRequest 创建回帖请求
+type CreatePostRequest struct {
+	Tid     int64  `json:"tid" binding:"required"`
+	Message string `json:"message" binding:"required"`
+}
+
+// Create POST /api/mgt/post
+func (h *PostMgtHandler) Create(c *gin.Context) {
+	var req CreatePostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	thread, err := h.threadSvc.Get(c.Request.Context(), req.Tid)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if thread == nil {
+		response.NotFound(c, "thread not found")
+		return
+	}
+	if !runtime.Get().CheckAccess(thread.Fid, GetGIDFromContext(c), model.AccessReply) {
+		response.Forbidden(c, "no permission to reply in this forum")
+		return
+	}
+
+	dto, err := h.svc.Create(c.Request.Context(), req.Tid, GetUIDFromContext(c), req.Message)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, dto)
+}
+
+// UpdatePostRequest 更新回帖请求
+type UpdatePostRequest struct {
+	Message string `json:"message" binding:"required"`
+	Status  int    `json:"status"`
+}
+
+// Update PUT /api/mgt/post/:pid
+func (h *PostMgtHandler) Update(c *gin.Context) {
+	pid, err := strconv.ParseInt(c.Param("pid"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid pid")
+		return
+	}
+
+	var req UpdatePostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if !h.checkPost(c, pid, model.PermPostUpdate) {
+		return
+	}
+
+	if err := h.svc.Update(c.Request.Context(), pid, req.Message, req.Status); err != nil {
+		if errors.Is(err, service.ErrInvalidPostStatus) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrPostNotFound) {
+			response.NotFound(c, err.Error())
+			return
+		}
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// Delete DELETE /api/mgt/post/:pid
+func (h *PostMgtHandler) Delete(c *gin.Context) {
+	pid, err := strconv.ParseInt(c.Param("pid"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid pid")
+		return
+	}
+
+	if !h.checkPost(c, pid, model.PermPostDelete) {
+		return
+	}
+
+	if err := h.svc.Delete(c.Request.Context(), pid); err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// checkPost 按回帖所在版块与作者校验版块授予的权限，返回 false 时已写入响应
+func (h *PostMgtHandler) checkPost(c *gin.Context, pid int64, perm string) bool {
+	if !permScoped(c) {
+		return true
+	}
+	post, err := h.svc.Get(c.Request.Context(), pid)
+	if err != nil {
+		response.Fail(c, err)
+		return false
+	}
+	if post == nil {
+		response.NotFound(c, "post not found")
+		return false
+	}
+	thread, err := h.threadSvc.Get(c.Request.Context(), post.Tid)
+	if err != nil {
+		response.Fail(c, err)
+		return false
+	}
+	if thread == nil || !allowInForum(c, perm, thread.Fid, post.Uid) {
+		response.Forbidden(c, "permission denied: "+perm)
+		return false
+	}
+	return true
+}
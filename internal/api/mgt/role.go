@@ -0,0 +1,249 @@
+package mgt
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"well_go/internal/core/runtime"
+	"well_go/internal/model"
+	"well_go/internal/pkg/response"
+	"well_go/internal/service"
+)
+
+// permScoped RequirePermission 是否仅凭版块授予/作者身份放行（需按目标再校验）
+func permScoped(c *gin.Context) bool {
+	scoped, _ := c.Get("perm_scoped")
+	return scoped == true
+}
+
+// allowInForum 校验版块授予的权限（含祖先版块）或作者本人权限；全站授予时直接通过
+func allowInForum(c *gin.Context, perm string, fid int, owner int64) bool {
+	if !permScoped(c) {
+		return true
+	}
+	v, _ := c.Get("grants")
+	grants, ok := v.(*model.Grants)
+	if !ok {
+		return false
+	}
+	if grants.HasInForums(perm, runtime.Get().ForumChain(fid)) {
+		return true
+	}
+	return model.Ownable(perm) && owner > 0 && owner == GetUIDFromContext(c)
+}
+
+// hasForumPerm 当前用户在版块 fid（含祖先版块）是否拥有 perm；与路由上已校验的权限无关
+func hasForumPerm(c *gin.Context, perm string, fid int) bool {
+	v, _ := c.Get("grants")
+	grants, ok := v.(*model.Grants)
+	if !ok {
+		return false
+	}
+	return grants.HasInForums(perm, runtime.Get().ForumChain(fid))
+}
+
+// RoleMgtHandler Role Management API Handler
+type RoleMgtHandler struct {
+	svc *service.RBACService
+}
+
+// NewRoleMgtHandler 创建 RoleMgtHandler
+func NewRoleMgtHandler(svc *service.RBACService) *RoleMgtHandler {
+	return &RoleMgtHandler{svc: svc}
+}
+
+// RoleRequest 创建/修改角色请求
+type RoleRequest struct {
+	Name        string   `json:"name" binding:"required,max=32"`
+	Title       string   `json:"title" binding:"max=60"`
+	Permissions []string `json:"permissions"`
+}
+
+// List GET /api/mgt/role
+func (h *RoleMgtHandler) List(c *gin.Context) {
+	list, err := h.svc.Roles(c.Request.Context())
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c, list)
+}
+
+// Create POST /api/mgt/role
+func (h *RoleMgtHandler) Create(c *gin.Context) {
+	var req RoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	dto, err := h.svc.CreateRole(c.Request.Context(), req.Name, req.Title, req.Permissions)
+	if errors.Is(err, service.ErrInvalidPermission) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, dto)
+}
+
+// Update PUT /api/mgt/role/:role_id
+func (h *RoleMgtHandler) Update(c *gin.Context) {
+	roleID, err := strconv.Atoi(c.Param("role_id"))
+	if err != nil {
+		response.BadRequest(c, "invalid role_id")
+		return
+	}
+
+	var req RoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	dto, err := h.svc.UpdateRole(c.Request.Context(), roleID, req.Name, req.Title, req.Permissions)
+	if errors.Is(err, service.ErrInvalidPermission) || errors.Is(err, service.ErrRoleBuiltin) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrRoleNotFound) {
+		response.NotFound(c, err.Error())
+		return
+	}
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, dto)
+}
+
+// Delete DELETE /api/mgt/role/:role_id
+// 同时收回该角色的全部授予
+func (h *RoleMgtHandler) Delete(c *gin.Context) {
+	roleID, err := strconv.Atoi(c.Param("role_id"))
+	if err != nil {
+		response.BadRequest(c, "invalid role_id")
+		return
+	}
+
+	err = h.svc.DeleteRole(c.Request.Context(), roleID)
+	if errors.Is(err, service.ErrRoleBuiltin) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrRoleNotFound) {
+		response.NotFound(c, err.Error())
+		return
+	}
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// UserRoles GET /api/mgt/user/:uid/roles
+func (h *RoleMgtHandler) UserRoles(c *gin.Context) {
+	uid, err := strconv.ParseInt(c.Param("uid"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid uid")
+		return
+	}
+
+	list, err := h.svc.UserRoles(c.Request.Context(), uid)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c, list)
+}
+
+// AssignRequest 授予角色请求
+type AssignRequest struct {
+	RoleID int `json:"role_id" binding:"required"`
+	Fid    int `json:"fid"` // 0 全站；>0 仅该版块及其子版块（版主）
+}
+
+// Assign POST /api/mgt/user/:uid/roles
+func (h *RoleMgtHandler) Assign(c *gin.Context) {
+	uid, err := strconv.ParseInt(c.Param("uid"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid uid")
+		return
+	}
+
+	var req AssignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	err = h.svc.Assign(c.Request.Context(), uid, req.RoleID, req.Fid)
+	if errors.Is(err, service.ErrRoleNotScopable) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrUserNotFound) || errors.Is(err, service.ErrRoleNotFound) || errors.Is(err, service.ErrForumNotFound) {
+		response.NotFound(c, err.Error())
+		return
+	}
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// Revoke DELETE /api/mgt/user/:uid/roles/:role_id?fid=
+func (h *RoleMgtHandler) Revoke(c *gin.Context) {
+	uid, err := strconv.ParseInt(c.Param("uid"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid uid")
+		return
+	}
+	roleID, err := strconv.Atoi(c.Param("role_id"))
+	if err != nil {
+		response.BadRequest(c, "invalid role_id")
+		return
+	}
+	fid, err := strconv.Atoi(c.DefaultQuery("fid", "0"))
+	if err != nil {
+		response.BadRequest(c, "invalid fid")
+		return
+	}
+
+	ok, err := h.svc.Revoke(c.Request.Context(), uid, roleID, fid)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if !ok {
+		response.NotFound(c, "role not assigned")
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// Moderators GET /api/mgt/forum/:fid/moderators
+func (h *RoleMgtHandler) Moderators(c *gin.Context) {
+	fid, err := strconv.Atoi(c.Param("fid"))
+	if err != nil {
+		response.BadRequest(c, "invalid fid")
+		return
+	}
+
+	list, err := h.svc.ForumRoles(c.Request.Context(), fid)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c, list)
+}
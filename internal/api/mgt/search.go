@@ -0,0 +1,50 @@
+package mgt
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"well_go/internal/core/logger"
+	"well_go/internal/pkg/response"
+	"well_go/internal/service"
+)
+
+// SearchMgtHandler Search Management API Handler
+type SearchMgtHandler struct {
+	svc *service.SearchService
+}
+
+// NewSearchMgtHandler 创建 SearchMgtHandler
+func NewSearchMgtHandler(svc *service.SearchService) *SearchMgtHandler {
+	return &SearchMgtHandler{svc: svc}
+}
+
+// Rebuild POST /api/mgt/search/rebuild
+// 后台从 DB 全量重建索引，进度通过 status 查询
+func (h *SearchMgtHandler) Rebuild(c *gin.Context) {
+	if h.svc.Rebuilding() {
+		response.BadRequest(c, service.ErrSearchRebuilding.Error())
+		return
+	}
+
+	go func() {
+		start := time.Now()
+		n, err := h.svc.Rebuild(context.Background())
+		if err != nil {
+			logger.Error("search: rebuild failed", logger.String("error", err.Error()))
+			return
+		}
+		logger.Info("search: rebuild done", logger.Int("docs", n), logger.Duration("cost", time.Since(start)))
+	}()
+
+	response.SuccessWithMsg(c, nil, "search rebuild started")
+}
+
+// Status GET /api/mgt/search/status
+func (h *SearchMgtHandler) Status(c *gin.Context) {
+	response.Success(c, gin.H{
+		"docs":       h.svc.Len(),
+		"rebuilding": h.svc.Rebuilding(),
+	})
+}
@@ -1,7 +1,11 @@
 package mgt
 
 import (
+	"errors"
+	"strconv"
+
 	"github.com/gin-gonic/gin"
+	"well_go/internal/core/runtime"
 	"well_go/internal/pkg/response"
 	"well_go/internal/service"
 )
@@ -38,6 +42,215 @@ func (h *TagMgtHandler) Create(c *gin.Context) {
 	response.Success(c, dto)
 }
 
+// Delete DELETE /api/mgt/tag/:tag_id
+// 移入回收站，主题关联保留
+func (h *TagMgtHandler) Delete(c *gin.Context) {
+	tagID, err := strconv.Atoi(c.Param("tag_id"))
+	if err != nil {
+		response.BadRequest(c, "invalid tag_id")
+		return
+	}
+
+	if err := h.svc.Delete(c.Request.Context(), tagID); err != nil {
+		if errors.Is(err, service.ErrTagNotFound) {
+			response.NotFound(c, err.Error())
+			return
+		}
+		response.Fail(c, err)
+		return
+	}
+	runtime.Get().TagsChanged(c.Request.Context())
+
+	response.Success(c, nil)
+}
+
+// UpdateSlugRequest 修改 slug 请求
+type UpdateSlugRequest struct {
+	Slug string `json:"slug" binding:"required"` // 可直接填中文，自动转拼音
+}
+
+// UpdateSlug PUT /api/mgt/tag/:tag_id/slug
+func (h *TagMgtHandler) UpdateSlug(c *gin.Context) {
+	tagID, err := strconv.Atoi(c.Param("tag_id"))
+	if err != nil {
+		response.BadRequest(c, "invalid tag_id")
+		return
+	}
+
+	var req UpdateSlugRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	dto, err := h.svc.UpdateSlug(c.Request.Context(), tagID, req.Slug)
+	if errors.Is(err, service.ErrInvalidSlug) || errors.Is(err, service.ErrSlugTaken) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrTagNotFound) {
+		response.NotFound(c, err.Error())
+		return
+	}
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	runtime.Get().TagsChanged(c.Request.Context())
+
+	response.Success(c, dto)
+}
+
+// RenameRequest 重命名 Tag 请求
+type RenameTagRequest struct {
+	Name string `json:"name" binding:"required,max=30"`
+}
+
+// Rename PUT /api/mgt/tag/:tag_id
+// 仅修改名称，slug 不变
+func (h *TagMgtHandler) Rename(c *gin.Context) {
+	tagID, err := strconv.Atoi(c.Param("tag_id"))
+	if err != nil {
+		response.BadRequest(c, "invalid tag_id")
+		return
+	}
+
+	var req RenameTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	dto, err := h.svc.Rename(c.Request.Context(), tagID, req.Name)
+	if errors.Is(err, service.ErrTagNameUsed) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrTagNotFound) {
+		response.NotFound(c, err.Error())
+		return
+	}
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	runtime.Get().TagsChanged(c.Request.Context())
+
+	response.Success(c, dto)
+}
+
+// MergeTagRequest 合并 Tag 请求
+type MergeTagRequest struct {
+	Into int `json:"into" binding:"required"` // 目标标签
+}
+
+// Merge POST /api/mgt/tag/:tag_id/merge
+// 将 tag_id 并入 into，原名称成为 into 的同义词
+func (h *TagMgtHandler) Merge(c *gin.Context) {
+	tagID, err := strconv.Atoi(c.Param("tag_id"))
+	if err != nil {
+		response.BadRequest(c, "invalid tag_id")
+		return
+	}
+
+	var req MergeTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	err = h.svc.Merge(c.Request.Context(), tagID, req.Into)
+	if errors.Is(err, service.ErrMergeSelf) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrTagNotFound) {
+		response.NotFound(c, err.Error())
+		return
+	}
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	runtime.Get().TagsChanged(c.Request.Context())
+
+	response.Success(c, nil)
+}
+
+// Aliases GET /api/mgt/tag/:tag_id/aliases
+func (h *TagMgtHandler) Aliases(c *gin.Context) {
+	tagID, err := strconv.Atoi(c.Param("tag_id"))
+	if err != nil {
+		response.BadRequest(c, "invalid tag_id")
+		return
+	}
+
+	aliases, err := h.svc.Aliases(c.Request.Context(), tagID)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, aliases)
+}
+
+// AliasRequest 添加同义词请求
+type AliasRequest struct {
+	Alias string `json:"alias" binding:"required,max=30"`
+}
+
+// AddAlias POST /api/mgt/tag/:tag_id/aliases
+func (h *TagMgtHandler) AddAlias(c *gin.Context) {
+	tagID, err := strconv.Atoi(c.Param("tag_id"))
+	if err != nil {
+		response.BadRequest(c, "invalid tag_id")
+		return
+	}
+
+	var req AliasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	err = h.svc.AddAlias(c.Request.Context(), tagID, req.Alias)
+	if errors.Is(err, service.ErrTagNameUsed) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrTagNotFound) {
+		response.NotFound(c, err.Error())
+		return
+	}
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// RemoveAlias DELETE /api/mgt/tag/:tag_id/aliases/:alias
+func (h *TagMgtHandler) RemoveAlias(c *gin.Context) {
+	tagID, err := strconv.Atoi(c.Param("tag_id"))
+	if err != nil {
+		response.BadRequest(c, "invalid tag_id")
+		return
+	}
+
+	ok, err := h.svc.RemoveAlias(c.Request.Context(), tagID, c.Param("alias"))
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if !ok {
+		response.NotFound(c, "alias not found")
+		return
+	}
+
+	response.Success(c, nil)
+}
+
 // Flush POST /api/mgt/cache/flush/tag
 func (h *TagMgtHandler) Flush(c *gin.Context) {
 	if err := h.svc.FlushCache(c.Request.Context()); err != nil {
@@ -1,9 +1,13 @@
 package mgt
 
 import (
+	"errors"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"well_go/internal/core/runtime"
+	"well_go/internal/model"
 	"well_go/internal/pkg/apperr"
 	"well_go/internal/pkg/response"
 	"well_go/internal/service"
@@ -25,10 +29,12 @@ func NewThreadHandler(svc *service.ThreadService, tagSvc *service.TagService) *T
 
 // CreateRequest 创建Thread请求
 type CreateRequest struct {
-	Fid     int64    `json:"fid" binding:"required"`
-	Subject string   `json:"subject" binding:"required"`
-	Message string   `json:"message" binding:"required"`
-	Tags    []string `json:"tags"`
+	Fid       int64    `json:"fid" binding:"required"`
+	Subject   string   `json:"subject" binding:"required"`
+	Message   string   `json:"message" binding:"required"`
+	Tags      []string `json:"tags"`
+	Status    int      `json:"status"`     // 默认 0 直接发布（无 thread.publish 权限时转为待审核），见 model.Thread* 状态
+	PublishAt int      `json:"publish_at"` // status=3（定时发布）时必填，Unix 时间戳
 }
 
 // Create POST /api/mgt/thread
@@ -39,20 +45,41 @@ func (h *ThreadHandler) Create(c *gin.Context) {
 		return
 	}
 
-	// TODO: 从JWT获取真实UID
-	uid := int64(1)
+	if !runtime.Get().CheckAccess(int(req.Fid), GetGIDFromContext(c), model.AccessThread) {
+		response.Forbidden(c, "no permission to post in this forum")
+		return
+	}
+
+	status := req.Status
+	if publishesThread(model.ThreadDraft, status) && !hasForumPerm(c, model.PermThreadPublish, int(req.Fid)) {
+		status = model.ThreadPending // 无发布权限时进入待审核
+	}
 
-	dto, err := h.svc.Create(c.Request.Context(), req.Fid, uid, req.Subject, req.Message)
+	dto, err := h.svc.Create(c.Request.Context(), req.Fid, GetUIDFromContext(c), req.Subject, req.Message, status, req.PublishAt)
+	if errors.Is(err, service.ErrInvalidThreadStatus) || errors.Is(err, service.ErrInvalidPublishAt) {
+		response.BadRequest(c, err.Error())
+		return
+	}
 	if err != nil {
 		response.Fail(c, apperr.WrapError(err, apperr.CodeThreadCreateErr))
 		return
 	}
 
-	for _, tag := range req.Tags {
-		if tag == "" {
-			continue
+	if len(req.Tags) > 0 {
+		tags, err := h.tagSvc.SetThreadTags(c.Request.Context(), dto.Tid, req.Tags)
+		if err != nil {
+			// 主题已创建，仅标签未生效
+			response.Fail(c, err)
+			return
+		}
+		if len(tags.Failed) > 0 {
+			names := make([]string, 0, len(tags.Failed))
+			for _, f := range tags.Failed {
+				names = append(names, f.Name)
+			}
+			response.SuccessWithMsg(c, dto, "tags not applied: "+strings.Join(names, ", "))
+			return
 		}
-		_ = h.tagSvc.AddToThread(c.Request.Context(), dto.Tid, tag)
 	}
 
 	response.Success(c, dto)
@@ -60,9 +87,11 @@ func (h *ThreadHandler) Create(c *gin.Context) {
 
 // UpdateRequest 更新Thread请求
 type UpdateRequest struct {
-	Subject string   `json:"subject"`
-	Status  int      `json:"status"`
-	Tags    []string `json:"tags"`
+	Subject   string   `json:"subject"`
+	Message   *string  `json:"message"` // 省略时不修改内容
+	Status    *int     `json:"status"`  // 省略时不修改状态
+	PublishAt int      `json:"publish_at"`
+	Tags      []string `json:"tags"` // 目标标签集合（替换语义）；省略时不修改，[] 清空
 }
 
 // Update PUT /api/mgt/thread/:tid
@@ -79,20 +108,62 @@ func (h *ThreadHandler) Update(c *gin.Context) {
 		response.BadRequest(c, err.Error())
 		return
 	}
+	if !h.checkThread(c, tid, model.PermThreadUpdate) {
+		return
+	}
+	if req.Status != nil {
+		thread, err := h.svc.Get(c.Request.Context(), tid)
+		if err != nil {
+			response.Fail(c, err)
+			return
+		}
+		if thread == nil {
+			response.NotFound(c, "thread not found")
+			return
+		}
+		if publishesThread(thread.Status, *req.Status) && !hasForumPerm(c, model.PermThreadPublish, thread.Fid) {
+			pending := model.ThreadPending // 无发布权限时进入待审核
+			req.Status = &pending
+		}
+	}
 
-	if err := h.svc.Update(c.Request.Context(), tid, req.Subject, req.Status); err != nil {
+	if err := h.svc.Update(c.Request.Context(), tid, GetUIDFromContext(c), req.Subject, req.Message, req.Status, req.PublishAt); err != nil {
+		if errors.Is(err, service.ErrInvalidThreadStatus) || errors.Is(err, service.ErrInvalidPublishAt) || errors.Is(err, service.ErrThreadTrashed) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrThreadStatusChanged) {
+			response.FailWithCode(c, 409, err.Error())
+			return
+		}
 		response.Fail(c, err)
 		return
 	}
 
-	for _, tag := range req.Tags {
-		if tag == "" {
-			continue
-		}
-		_ = h.tagSvc.AddToThread(c.Request.Context(), tid, tag)
+	if req.Tags == nil {
+		response.Success(c, nil)
+		return
+	}
+	tags, err := h.tagSvc.SetThreadTags(c.Request.Context(), tid, req.Tags)
+	if errors.Is(err, service.ErrThreadNotFound) {
+		response.NotFound(c, err.Error())
+		return
+	}
+	if err != nil {
+		response.Fail(c, err)
+		return
 	}
 
-	response.Success(c, nil)
+	response.Success(c, gin.H{"tags": tags})
+}
+
+// publishesThread 状态变更是否会让主题上线（需 thread.publish）：
+// 改为已发布，或从未发布状态改为定时发布（到期自动上线）
+func publishesThread(from, to int) bool {
+	if from == to {
+		return false
+	}
+	return to == model.ThreadPublished || (to == model.ThreadScheduled && from != model.ThreadPublished)
 }
 
 // Delete DELETE /api/mgt/thread/:tid
@@ -104,6 +175,10 @@ func (h *ThreadHandler) Delete(c *gin.Context) {
 		return
 	}
 
+	if !h.checkThread(c, tid, model.PermThreadDelete) {
+		return
+	}
+
 	if err := h.svc.Delete(c.Request.Context(), tid); err != nil {
 		response.Fail(c, err)
 		return
@@ -111,3 +186,261 @@ func (h *ThreadHandler) Delete(c *gin.Context) {
 
 	response.Success(c, nil)
 }
+
+// MoveRequest 移动主题请求
+type MoveRequest struct {
+	Tids     []int64 `json:"tids"` // 仅批量移动使用
+	Fid      int     `json:"fid" binding:"required"`
+	Redirect bool    `json:"redirect"` // 在原版块保留跳转占位
+}
+
+// Move POST /api/mgt/thread/:tid/move
+func (h *ThreadHandler) Move(c *gin.Context) {
+	tid, err := strconv.ParseInt(c.Param("tid"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid tid")
+		return
+	}
+
+	var req MoveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	req.Tids = []int64{tid}
+	h.move(c, &req)
+}
+
+// BulkMove POST /api/mgt/thread/move
+func (h *ThreadHandler) BulkMove(c *gin.Context) {
+	var req MoveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if len(req.Tids) == 0 {
+		response.BadRequest(c, "tids is required")
+		return
+	}
+	h.move(c, &req)
+}
+
+func (h *ThreadHandler) move(c *gin.Context, req *MoveRequest) {
+	if !runtime.Get().CheckAccess(req.Fid, GetGIDFromContext(c), model.AccessThread) {
+		response.Forbidden(c, "no permission to post in this forum")
+		return
+	}
+	if !allowInForum(c, model.PermThreadMove, req.Fid, 0) {
+		response.Forbidden(c, "permission denied: "+model.PermThreadMove)
+		return
+	}
+	for _, tid := range req.Tids {
+		if !h.checkThread(c, tid, model.PermThreadMove) {
+			return
+		}
+	}
+
+	result, err := h.svc.Move(c.Request.Context(), req.Tids, req.Fid, req.Redirect)
+	if errors.Is(err, service.ErrForumNotFound) {
+		response.NotFound(c, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrMoveBatchTooLarge) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// Publish POST /api/mgt/thread/:tid/publish
+// 立即发布（审核通过/提前发布定时主题）
+func (h *ThreadHandler) Publish(c *gin.Context) {
+	tidStr := c.Param("tid")
+	tid, err := strconv.ParseInt(tidStr, 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid tid")
+		return
+	}
+
+	if !h.checkThread(c, tid, model.PermThreadPublish) {
+		return
+	}
+
+	ok, err := h.svc.Publish(c.Request.Context(), tid)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"published": ok})
+}
+
+// Revisions GET /api/mgt/thread/:tid/revisions
+func (h *ThreadHandler) Revisions(c *gin.Context) {
+	tid, err := strconv.ParseInt(c.Param("tid"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid tid")
+		return
+	}
+
+	page := 1
+	pageSize := 20
+
+	if p := c.Query("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+
+	if ps := c.Query("page_size"); ps != "" {
+		if parsed, err := strconv.Atoi(ps); err == nil && parsed > 0 && parsed <= 100 {
+			pageSize = parsed
+		}
+	}
+
+	if !h.checkThread(c, tid, model.PermThreadUpdate) {
+		return
+	}
+
+	list, total, err := h.svc.Revisions(c.Request.Context(), tid, page, pageSize)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"list":      list,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+// Revision GET /api/mgt/thread/:tid/revisions/:rev
+func (h *ThreadHandler) Revision(c *gin.Context) {
+	tid, err := strconv.ParseInt(c.Param("tid"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid tid")
+		return
+	}
+	rev, err := strconv.Atoi(c.Param("rev"))
+	if err != nil {
+		response.BadRequest(c, "invalid rev")
+		return
+	}
+
+	if !h.checkThread(c, tid, model.PermThreadUpdate) {
+		return
+	}
+
+	dto, err := h.svc.Revision(c.Request.Context(), tid, rev)
+	if errors.Is(err, service.ErrRevisionNotFound) {
+		response.NotFound(c, err.Error())
+		return
+	}
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, dto)
+}
+
+// RevisionDiff GET /api/mgt/thread/:tid/revisions/diff?from=1&to=2
+func (h *ThreadHandler) RevisionDiff(c *gin.Context) {
+	tid, err := strconv.ParseInt(c.Param("tid"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid tid")
+		return
+	}
+	from, err := strconv.Atoi(c.Query("from"))
+	if err != nil {
+		response.BadRequest(c, "invalid from")
+		return
+	}
+	to, err := strconv.Atoi(c.Query("to"))
+	if err != nil {
+		response.BadRequest(c, "invalid to")
+		return
+	}
+
+	if !h.checkThread(c, tid, model.PermThreadUpdate) {
+		return
+	}
+
+	result, err := h.svc.DiffRevisions(c.Request.Context(), tid, from, to)
+	if errors.Is(err, service.ErrRevisionNotFound) {
+		response.NotFound(c, err.Error())
+		return
+	}
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// Rollback POST /api/mgt/thread/:tid/revisions/:rev/rollback
+func (h *ThreadHandler) Rollback(c *gin.Context) {
+	tid, err := strconv.ParseInt(c.Param("tid"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid tid")
+		return
+	}
+	rev, err := strconv.Atoi(c.Param("rev"))
+	if err != nil {
+		response.BadRequest(c, "invalid rev")
+		return
+	}
+
+	if !h.checkThread(c, tid, model.PermThreadUpdate) {
+		return
+	}
+
+	dto, err := h.svc.Rollback(c.Request.Context(), tid, rev, GetUIDFromContext(c))
+	if errors.Is(err, service.ErrThreadNotFound) || errors.Is(err, service.ErrRevisionNotFound) {
+		response.NotFound(c, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrThreadTrashed) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrThreadStatusChanged) {
+		response.FailWithCode(c, 409, err.Error())
+		return
+	}
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, dto)
+}
+
+// checkThread 按主题所在版块与作者校验版块授予的权限，返回 false 时已写入响应
+func (h *ThreadHandler) checkThread(c *gin.Context, tid int64, perm string) bool {
+	if !permScoped(c) {
+		return true
+	}
+	thread, err := h.svc.Get(c.Request.Context(), tid)
+	if err != nil {
+		response.Fail(c, err)
+		return false
+	}
+	if thread == nil {
+		response.NotFound(c, "thread not found")
+		return false
+	}
+	if !allowInForum(c, perm, thread.Fid, thread.Uid) {
+		response.Forbidden(c, "permission denied: "+perm)
+		return false
+	}
+	return true
+}
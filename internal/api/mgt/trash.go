@@ -0,0 +1,132 @@
+package mgt
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"well_go/internal/core/runtime"
+	"well_go/internal/pkg/response"
+	"well_go/internal/service"
+)
+
+// TrashMgtHandler Recycle Bin Management API Handler
+type TrashMgtHandler struct {
+	svc *service.TrashService
+}
+
+// NewTrashMgtHandler 创建 TrashMgtHandler
+func NewTrashMgtHandler(svc *service.TrashService) *TrashMgtHandler {
+	return &TrashMgtHandler{svc: svc}
+}
+
+// List GET /api/mgt/trash/:type
+func (h *TrashMgtHandler) List(c *gin.Context) {
+	page := 1
+	pageSize := 20
+
+	if p := c.Query("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+
+	if ps := c.Query("page_size"); ps != "" {
+		if parsed, err := strconv.Atoi(ps); err == nil && parsed > 0 && parsed <= 100 {
+			pageSize = parsed
+		}
+	}
+
+	list, total, err := h.svc.List(c.Request.Context(), c.Param("type"), page, pageSize)
+	if errors.Is(err, service.ErrInvalidTrashType) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"list":      list,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+// Restore POST /api/mgt/trash/:type/:id/restore
+func (h *TrashMgtHandler) Restore(c *gin.Context) {
+	kind := c.Param("type")
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid id")
+		return
+	}
+
+	ok, err := h.svc.Restore(c.Request.Context(), kind, id)
+	if errors.Is(err, service.ErrInvalidTrashType) || errors.Is(err, service.ErrForumParentTrashed) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if !ok {
+		response.NotFound(c, "not in trash")
+		return
+	}
+	h.refreshRuntime(c, kind)
+
+	response.Success(c, nil)
+}
+
+// Purge DELETE /api/mgt/trash/:type/:id
+// 立即彻底删除，不可恢复
+func (h *TrashMgtHandler) Purge(c *gin.Context) {
+	kind := c.Param("type")
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid id")
+		return
+	}
+
+	ok, err := h.svc.Purge(c.Request.Context(), kind, id)
+	if errors.Is(err, service.ErrInvalidTrashType) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if !ok {
+		// 版块仍有主题/子版块时也无法清除
+		response.NotFound(c, "not in trash or still referenced")
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// PurgeExpired POST /api/mgt/trash/purge
+// 立即执行一次到期清除（与定时任务相同）
+func (h *TrashMgtHandler) PurgeExpired(c *gin.Context) {
+	result, err := h.svc.PurgeExpired(c.Request.Context())
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c, result)
+}
+
+// refreshRuntime 版块/标签恢复后刷新运行时列表（含其它实例）
+func (h *TrashMgtHandler) refreshRuntime(c *gin.Context, kind string) {
+	switch kind {
+	case service.TrashForum:
+		runtime.Get().ForumsChanged(c.Request.Context())
+	case service.TrashTag:
+		runtime.Get().TagsChanged(c.Request.Context())
+	}
+}
@@ -77,6 +77,16 @@ func (h *UserMgtHandler) GetProfile(c *gin.Context) {
 	})
 }
 
+// GetGIDFromContext 从上下文获取用户组（未登录为游客）
+func GetGIDFromContext(c *gin.Context) int {
+	if v, exists := c.Get("gid"); exists {
+		if gid, ok := v.(int); ok {
+			return gid
+		}
+	}
+	return model.GroupGuest
+}
+
 // GetUIDFromContext 从上下文获取UID
 func GetUIDFromContext(c *gin.Context) int64 {
 	if v, exists := c.Get("uid"); exists {
@@ -4,6 +4,8 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"well_go/internal/core/runtime"
+	"well_go/internal/model"
 	"well_go/internal/pkg/response"
 	"well_go/internal/service"
 )
@@ -25,7 +27,15 @@ func (h *ForumHandler) List(c *gin.Context) {
 		response.Fail(c, err)
 		return
 	}
-	response.Success(c, list)
+
+	gid := GetGIDFromContext(c)
+	readable := make([]*service.ForumDTO, 0, len(list))
+	for _, f := range list {
+		if runtime.Get().CheckAccess(f.Fid, gid, model.AccessRead) {
+			readable = append(readable, f)
+		}
+	}
+	response.Success(c, readable)
 }
 
 // Tree GET /api/v1/forums/tree
@@ -35,7 +45,20 @@ func (h *ForumHandler) Tree(c *gin.Context) {
 		response.Fail(c, err)
 		return
 	}
-	response.Success(c, tree)
+	response.Success(c, filterReadableTree(tree, GetGIDFromContext(c)))
+}
+
+// filterReadableTree 过滤无读权限的版块（连同其子版块）
+func filterReadableTree(nodes []*service.ForumTreeNode, gid int) []*service.ForumTreeNode {
+	result := make([]*service.ForumTreeNode, 0, len(nodes))
+	for _, node := range nodes {
+		if !runtime.Get().CheckAccess(node.Fid, gid, model.AccessRead) {
+			continue
+		}
+		node.Children = filterReadableTree(node.Children, gid)
+		result = append(result, node)
+	}
+	return result
 }
 
 // Get GET /api/v1/forum/:fid
@@ -57,6 +80,10 @@ func (h *ForumHandler) Get(c *gin.Context) {
 		response.NotFound(c, "forum not found")
 		return
 	}
+	if !runtime.Get().CheckAccess(fid, GetGIDFromContext(c), model.AccessRead) {
+		response.Forbidden(c, "no permission to read this forum")
+		return
+	}
 
 	response.Success(c, dto)
 }
@@ -0,0 +1,85 @@
+package v1
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"well_go/internal/core/runtime"
+	"well_go/internal/model"
+	"well_go/internal/pkg/response"
+	"well_go/internal/service"
+)
+
+// PostHandler Post API Handler
+type PostHandler struct {
+	svc       *service.PostService
+	threadSvc *service.ThreadService
+	userSvc   *service.UserService
+}
+
+// NewPostHandler 创建 PostHandler
+func NewPostHandler(svc *service.PostService, threadSvc *service.ThreadService, userSvc *service.UserService) *PostHandler {
+	return &PostHandler{svc: svc, threadSvc: threadSvc, userSvc: userSvc}
+}
+
+// List GET /api/v1/thread/:tid/posts
+func (h *PostHandler) List(c *gin.Context) {
+	tid, err := strconv.ParseInt(c.Param("tid"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid tid")
+		return
+	}
+
+	thread, err := h.threadSvc.Get(c.Request.Context(), tid)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if thread == nil || thread.Status != model.ThreadPublished {
+		response.NotFound(c, "thread not found")
+		return
+	}
+	if !runtime.Get().CheckAccess(thread.Fid, GetGIDFromContext(c), model.AccessRead) {
+		response.Forbidden(c, "no permission to read this forum")
+		return
+	}
+
+	page := 1
+	pageSize := 20
+
+	if p := c.Query("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+
+	if ps := c.Query("page_size"); ps != "" {
+		if parsed, err := strconv.Atoi(ps); err == nil && parsed > 0 && parsed <= 100 {
+			pageSize = parsed
+		}
+	}
+
+	list, total, err := h.svc.List(c.Request.Context(), tid, page, pageSize)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	uids := make([]int64, 0, len(list))
+	for _, item := range list {
+		uids = append(uids, item.Uid)
+	}
+	users, err := h.userSvc.GetUsersByIDs(c.Request.Context(), uids)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"list":      list,
+		"users":     users,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
@@ -0,0 +1,100 @@
+package v1
+
+import (
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"well_go/internal/core/runtime"
+	"well_go/internal/model"
+	"well_go/internal/pkg/response"
+	"well_go/internal/service"
+)
+
+// maxSearchQueryRunes 搜索词最大长度
+const maxSearchQueryRunes = 100
+
+// SearchHandler Search API Handler
+type SearchHandler struct {
+	svc     *service.SearchService
+	userSvc *service.UserService
+}
+
+// NewSearchHandler 创建 SearchHandler
+func NewSearchHandler(svc *service.SearchService, userSvc *service.UserService) *SearchHandler {
+	return &SearchHandler{svc: svc, userSvc: userSvc}
+}
+
+// Search GET /api/v1/search?q=&fid=&tag_id=&page=&page_size=
+func (h *SearchHandler) Search(c *gin.Context) {
+	q := c.Query("q")
+	if q == "" {
+		response.BadRequest(c, "q is required")
+		return
+	}
+	if utf8.RuneCountInString(q) > maxSearchQueryRunes {
+		response.BadRequest(c, "q is too long")
+		return
+	}
+
+	query := &service.SearchQuery{
+		Q:        q,
+		Page:     1,
+		PageSize: 20,
+	}
+	if f := c.Query("fid"); f != "" {
+		fid, err := strconv.Atoi(f)
+		if err != nil {
+			response.BadRequest(c, "invalid fid")
+			return
+		}
+		query.Fid = fid
+	}
+	if t := c.Query("tag_id"); t != "" {
+		tagID, err := strconv.Atoi(t)
+		if err != nil {
+			response.BadRequest(c, "invalid tag_id")
+			return
+		}
+		query.TagID = tagID
+	}
+	if p := c.Query("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			query.Page = parsed
+		}
+	}
+	if ps := c.Query("page_size"); ps != "" {
+		if parsed, err := strconv.Atoi(ps); err == nil && parsed > 0 && parsed <= 50 {
+			query.PageSize = parsed
+		}
+	}
+
+	gid := GetGIDFromContext(c)
+	query.Allow = func(fid int) bool {
+		return runtime.Get().CheckAccess(fid, gid, model.AccessRead)
+	}
+
+	result, err := h.svc.Search(c.Request.Context(), query)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	uids := make([]int64, 0, len(result.List))
+	for _, item := range result.List {
+		uids = append(uids, item.Uid)
+	}
+	users, err := h.userSvc.GetUsersByIDs(c.Request.Context(), uids)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"list":      result.List,
+		"users":     users,
+		"total":     result.Total,
+		"page":      query.Page,
+		"page_size": query.PageSize,
+	})
+}
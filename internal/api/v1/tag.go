@@ -1,21 +1,28 @@
 package v1
 
 import (
+	"context"
+	"errors"
 	"strconv"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
+	"well_go/internal/core/runtime"
+	"well_go/internal/model"
 	"well_go/internal/pkg/response"
 	"well_go/internal/service"
 )
 
 // TagHandler Tag API Handler
 type TagHandler struct {
-	svc *service.TagService
+	svc       *service.TagService
+	threadSvc *service.ThreadService
+	userSvc   *service.UserService
 }
 
 // NewTagHandler 创建 TagHandler
-func NewTagHandler(svc *service.TagService) *TagHandler {
-	return &TagHandler{svc: svc}
+func NewTagHandler(svc *service.TagService, threadSvc *service.ThreadService, userSvc *service.UserService) *TagHandler {
+	return &TagHandler{svc: svc, threadSvc: threadSvc, userSvc: userSvc}
 }
 
 // List GET /api/v1/tags
@@ -45,27 +52,125 @@ func (h *TagHandler) Hot(c *gin.Context) {
 	response.Success(c, list)
 }
 
-// Get GET /api/v1/tag/:tag_id
-func (h *TagHandler) Get(c *gin.Context) {
-	tagIDStr := c.Param("tag_id")
-	tagID, err := strconv.Atoi(tagIDStr)
+// maxSuggestQueryRunes 自动补全查询词最大长度
+const maxSuggestQueryRunes = 30
+
+// Suggest GET /api/v1/tags/suggest?q=
+// 标签自动补全，基于运行时标签列表（不查库）
+func (h *TagHandler) Suggest(c *gin.Context) {
+	q := c.Query("q")
+	if utf8.RuneCountInString(q) > maxSuggestQueryRunes {
+		response.BadRequest(c, "query too long")
+		return
+	}
+
+	limit := 10
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 50 {
+			limit = parsed
+		}
+	}
+
+	response.Success(c, runtime.Get().SuggestTags(q, limit))
+}
+
+// Related GET /api/v1/tag/:slug/related
+// 经常与该标签同时出现的标签（定时重算）
+func (h *TagHandler) Related(c *gin.Context) {
+	dto, err := h.svc.GetBySlug(c.Request.Context(), c.Param("slug"))
 	if err != nil {
-		response.BadRequest(c, "invalid tag_id")
+		response.Fail(c, err)
+		return
+	}
+	if dto == nil {
+		response.NotFound(c, "tag not found")
 		return
 	}
 
-	dto, err := h.svc.Get(c.Request.Context(), tagID)
+	limit := 10
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 20 {
+			limit = parsed
+		}
+	}
+
+	list, err := h.svc.Related(c.Request.Context(), dto.TagID, limit)
 	if err != nil {
 		response.Fail(c, err)
 		return
 	}
+	response.Success(c, list)
+}
 
+// Get GET /api/v1/tag/:slug
+// 标签落地页：标签信息 + 可分页、可排序的主题列表（仅当前用户组可读版块）
+func (h *TagHandler) Get(c *gin.Context) {
+	dto, err := h.svc.GetBySlug(c.Request.Context(), c.Param("slug"))
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
 	if dto == nil {
 		response.NotFound(c, "tag not found")
 		return
 	}
 
-	response.Success(c, dto)
+	page := 1
+	pageSize := 20
+
+	if p := c.Query("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+
+	if ps := c.Query("page_size"); ps != "" {
+		if parsed, err := strconv.Atoi(ps); err == nil && parsed > 0 && parsed <= 100 {
+			pageSize = parsed
+		}
+	}
+
+	query := service.FeedQuery{
+		Fids:  runtime.Get().ReadableForums(GetGIDFromContext(c), 0),
+		TagID: dto.TagID,
+		Sort:  c.DefaultQuery("sort", model.ThreadSortLastpost),
+	}
+	list, err := h.threadSvc.Feed(c.Request.Context(), query, page, pageSize)
+	if errors.Is(err, service.ErrInvalidSort) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	total, err := h.threadSvc.FeedCount(c.Request.Context(), query)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	uids := make([]int64, 0, len(list))
+	for _, item := range list {
+		uids = append(uids, item.Uid)
+	}
+	users, err := h.userSvc.GetUsersByIDs(c.Request.Context(), uids)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	// 浏览数缓冲到 Redis，定时落库（不阻塞主流程）
+	go h.svc.RecordView(context.Background(), dto.TagID, c.ClientIP())
+
+	response.Success(c, gin.H{
+		"tag":       dto,
+		"list":      list,
+		"users":     users,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
 }
 
 // GetByThread GET /api/v1/tags/thread/:tid
@@ -2,10 +2,13 @@ package v1
 
 import (
 	"context"
+	"errors"
 	"strconv"
 	"sync"
 
 	"github.com/gin-gonic/gin"
+	"well_go/internal/core/runtime"
+	"well_go/internal/model"
 	"well_go/internal/pkg/response"
 	"well_go/internal/service"
 )
@@ -36,6 +39,11 @@ func (h *ThreadHandler) List(c *gin.Context) {
 		return
 	}
 
+	if !runtime.Get().CheckAccess(fid, GetGIDFromContext(c), model.AccessRead) {
+		response.Forbidden(c, "no permission to read this forum")
+		return
+	}
+
 	page := 1
 	pageSize := 20
 
@@ -51,17 +59,114 @@ func (h *ThreadHandler) List(c *gin.Context) {
 		}
 	}
 
-	list, err := h.svc.List(c.Request.Context(), fid, page, pageSize)
+	sort := c.DefaultQuery("sort", model.ThreadSortLastpost)
+
+	// 带 cursor 参数（首页传空值）时走游标分页，否则保留页码分页
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		if sort != model.ThreadSortLastpost {
+			response.BadRequest(c, "cursor only supports sort=lastpost")
+			return
+		}
+		list, next, err := h.svc.ListAfter(c.Request.Context(), fid, cursor, pageSize)
+		if errors.Is(err, service.ErrInvalidCursor) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		if err != nil {
+			response.Fail(c, err)
+			return
+		}
+		users, err := h.listUsers(c, list)
+		if err != nil {
+			response.Fail(c, err)
+			return
+		}
+		response.Success(c, gin.H{
+			"list":        list,
+			"users":       users,
+			"next_cursor": next,
+			"page_size":   pageSize,
+		})
+		return
+	}
+
+	list, err := h.svc.List(c.Request.Context(), fid, sort, page, pageSize)
+	if errors.Is(err, service.ErrInvalidSort) {
+		response.BadRequest(c, err.Error())
+		return
+	}
 	if err != nil {
 		response.Fail(c, err)
 		return
 	}
 
-	uids := make([]int64, 0, len(list))
-	for _, item := range list {
-		uids = append(uids, item.Uid)
+	users, err := h.listUsers(c, list)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"list":      list,
+		"users":     users,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+// Feed GET /api/v1/feed
+// 跨版块主题列表：fid 为该版块及全部子版块，uid 为某用户的主题，都不传为全站；
+// 只包含当前用户组可读的版块
+func (h *ThreadHandler) Feed(c *gin.Context) {
+	page := 1
+	pageSize := 20
+
+	if p := c.Query("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+
+	if ps := c.Query("page_size"); ps != "" {
+		if parsed, err := strconv.Atoi(ps); err == nil && parsed > 0 && parsed <= 100 {
+			pageSize = parsed
+		}
+	}
+
+	root := 0
+	if f := c.Query("fid"); f != "" {
+		parsed, err := strconv.Atoi(f)
+		if err != nil || parsed <= 0 {
+			response.BadRequest(c, "invalid fid")
+			return
+		}
+		root = parsed
+	}
+
+	q := service.FeedQuery{
+		Fids: runtime.Get().ReadableForums(GetGIDFromContext(c), root),
+		Sort: c.DefaultQuery("sort", model.ThreadSortLastpost),
+	}
+	if u := c.Query("uid"); u != "" {
+		uid, err := strconv.ParseInt(u, 10, 64)
+		if err != nil || uid <= 0 {
+			response.BadRequest(c, "invalid uid")
+			return
+		}
+		q.Uid = uid
+	}
+
+	list, err := h.svc.Feed(c.Request.Context(), q, page, pageSize)
+	if errors.Is(err, service.ErrInvalidSort) {
+		response.BadRequest(c, err.Error())
+		return
 	}
-	users, err := h.userSvc.GetUsersByIDs(c.Request.Context(), uids)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	users, err := h.listUsers(c, list)
 	if err != nil {
 		response.Fail(c, err)
 		return
@@ -75,6 +180,74 @@ func (h *ThreadHandler) List(c *gin.Context) {
 	})
 }
 
+// listUsers 批量获取列表作者
+func (h *ThreadHandler) listUsers(c *gin.Context, list []*service.ThreadListItem) (map[int64]*model.UserDTO, error) {
+	uids := make([]int64, 0, len(list))
+	for _, item := range list {
+		uids = append(uids, item.Uid)
+	}
+	return h.userSvc.GetUsersByIDs(c.Request.Context(), uids)
+}
+
+// Related GET /api/v1/thread/:tid/related
+// 相关主题（共同标签、同版块、时间），只返回当前用户组可读版块中的主题
+func (h *ThreadHandler) Related(c *gin.Context) {
+	tid, err := strconv.ParseInt(c.Param("tid"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid tid")
+		return
+	}
+
+	limit := 10
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 20 {
+			limit = parsed
+		}
+	}
+
+	dto, err := h.svc.Get(c.Request.Context(), tid)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if dto == nil || dto.Status != model.ThreadPublished {
+		response.NotFound(c, "thread not found")
+		return
+	}
+	gid := GetGIDFromContext(c)
+	if !runtime.Get().CheckAccess(dto.Fid, gid, model.AccessRead) {
+		response.Forbidden(c, "no permission to read this forum")
+		return
+	}
+
+	related, err := h.svc.Related(c.Request.Context(), tid)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	list := make([]*service.ThreadListItem, 0, limit)
+	for _, item := range related {
+		if len(list) >= limit {
+			break
+		}
+		// 状态已由 Related 按实时数据过滤，版块权限按当前访问者检查
+		if runtime.Get().CheckAccess(item.Fid, gid, model.AccessRead) {
+			list = append(list, item)
+		}
+	}
+
+	users, err := h.listUsers(c, list)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"list":  list,
+		"users": users,
+	})
+}
+
 // Get GET /api/v1/thread/:tid
 func (h *ThreadHandler) Get(c *gin.Context) {
 	tidStr := c.Param("tid")
@@ -114,13 +287,19 @@ func (h *ThreadHandler) Get(c *gin.Context) {
 		response.Fail(c, tagErr)
 		return
 	}
-	if dto == nil {
+	if dto == nil || dto.Status != model.ThreadPublished {
 		response.NotFound(c, "thread not found")
 		return
 	}
+	if !runtime.Get().CheckAccess(dto.Fid, GetGIDFromContext(c), model.AccessRead) {
+		response.Forbidden(c, "no permission to read this forum")
+		return
+	}
 
-	// 详情页浏览量异步累计（不阻塞主流程）
-	go h.svc.IncViews(context.Background(), tid)
+	// 详情页浏览量异步累计（不阻塞主流程）；跳转占位不计浏览
+	if dto.RedirectTid == 0 {
+		go h.svc.RecordView(context.Background(), tid, c.ClientIP())
+	}
 
 	response.Success(c, gin.H{
 		"thread": dto,
@@ -3,6 +3,7 @@ package v1
 import (
 	"net/http"
 
+	"well_go/internal/model"
 	"well_go/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -89,6 +90,16 @@ func ParseID(s string) int64 {
 	return id
 }
 
+// GetGIDFromContext 从上下文获取用户组（未登录为游客）
+func GetGIDFromContext(c *gin.Context) int {
+	if v, exists := c.Get("gid"); exists {
+		if gid, ok := v.(int); ok {
+			return gid
+		}
+	}
+	return model.GroupGuest
+}
+
 // GetUIDFromContext 从上下文获取UID
 func GetUIDFromContext(c *gin.Context) int64 {
 	if v, exists := c.Get("uid"); exists {
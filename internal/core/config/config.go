@@ -10,6 +10,12 @@ import (
 var v *viper.Viper
 var cfg *Config
 
+// placeholderJWTSecrets 默认值与示例配置中的 HS256 密钥，公开可见，仅允许在 debug 模式使用
+var placeholderJWTSecrets = map[string]bool{
+	"change-me-in-production":                  true,
+	"your-jwt-secret-key-change-in-production": true,
+}
+
 // Config App-wide configuration
 type Config struct {
 	Database  DatabaseConfig  `mapstructure:"-"`
@@ -20,6 +26,11 @@ type Config struct {
 	Snowflake SnowflakeConfig `mapstructure:"-"`
 	Logging   LoggingConfig   `mapstructure:"-"`
 	Security  SecurityConfig  `mapstructure:"-"`
+	Login     LoginConfig     `mapstructure:"-"`
+	Thread    ThreadConfig    `mapstructure:"-"`
+	Tag       TagConfig       `mapstructure:"-"`
+	Trash     TrashConfig     `mapstructure:"-"`
+	SEO       SEOConfig       `mapstructure:"-"`
 }
 
 // DatabaseConfig MySQL Database Configuration
@@ -49,18 +60,52 @@ type AppConfig struct {
 	Port int
 	Mode string
 	BaseURL string
+	TrustedProxies []string // 反向代理地址（IP/CIDR），只信任这些来源的 X-Forwarded-For
 }
 
 // JWTConfig JWT Configuration
 type JWTConfig struct {
-	Secret string
-	Expiry int // Token过期时间(秒)
+	Algorithm     string   // 签名算法：HS256 / RS256 / EdDSA
+	Secret        string   // HS256 共享密钥
+	KeyID         string   // 当前签名密钥 kid（RS256/EdDSA）
+	PrivateKey    string   // 当前签名私钥 PEM 文件
+	PublicKeys    []string // 轮换期间仍接受的旧公钥，"kid:PEM 文件"
+	Expiry        int      // Access Token 过期时间(秒)
+	RefreshExpiry int      // Refresh Token 过期时间(秒)，每次刷新顺延
 }
 
 // CacheConfig Cache Configuration
 type CacheConfig struct {
-	L1Cap int
-	L2TTL int
+	L1Cap       int
+	L2TTL       int
+	BusChannel  string  // L1 跨实例失效广播频道
+	NegativeTTL int     // 负缓存 TTL（秒），0 关闭
+	TTLJitter   float64 // TTL 随机抖动比例（0~1）
+}
+
+// ThreadConfig Thread Configuration
+type ThreadConfig struct {
+	PublishInterval   int // 定时发布扫描间隔(秒)
+	ViewFlushInterval int // 浏览量落库间隔(秒)
+	ViewDedupWindow   int // 同一访客重复浏览不计数的窗口(秒)
+}
+
+// TagConfig Tag Configuration
+type TagConfig struct {
+	RelatedInterval int // 相关标签（共现统计）重算间隔(秒)
+	SuggestRefresh  int // 自动补全使用的运行时标签列表刷新间隔(秒)
+}
+
+// TrashConfig Recycle Bin Configuration
+type TrashConfig struct {
+	RetentionDays int // 回收站保留天数，到期自动彻底删除
+	PurgeInterval int // 到期清除扫描间隔(秒)
+}
+
+// SEOConfig SEO Configuration
+type SEOConfig struct {
+	IndexNowKey      string // IndexNow API Key，为空时不提交
+	IndexNowEndpoint string // IndexNow 提交端点
 }
 
 // SnowflakeConfig Snowflake Configuration
@@ -85,6 +130,16 @@ type SecurityConfig struct {
 	RateLimit int      // 频率限制
 }
 
+// LoginConfig Login Brute-force Protection Configuration
+type LoginConfig struct {
+	MaxFailures   int // 同一用户名连续失败次数达到后锁定
+	IPMaxFailures int // 同一 IP 失败次数达到后锁定
+	FailureWindow int // 失败计数窗口(秒)
+	Lockout       int // 锁定时长(秒)
+	BackoffBase   int // 每次失败后的等待时间基数(秒)，按失败次数指数增长
+	BackoffMax    int // 等待时间上限(秒)
+}
+
 // Init Initialize configuration with Viper
 func Init(configPath string) error {
 	v = viper.New()
@@ -140,15 +195,34 @@ func setDefaults() {
 
 	v.SetDefault("cache.l1_cap", 1000)
 	v.SetDefault("cache.l2_ttl", 3600)
+	v.SetDefault("cache.bus_channel", "well:l1:invalidate")
+	v.SetDefault("cache.negative_ttl", 60)
+	v.SetDefault("cache.ttl_jitter", 0.1)
 
 	v.SetDefault("snowflake.worker_id", 0)
 
+	v.SetDefault("thread.publish_interval", 30)
+
+	v.SetDefault("trash.retention_days", 30)
+	v.SetDefault("trash.purge_interval", 3600)
+
+	v.SetDefault("seo.indexnow_endpoint", "https://api.indexnow.org/indexnow")
+
+	v.SetDefault("jwt.algorithm", "HS256")
 	v.SetDefault("jwt.secret", "change-me-in-production")
-	v.SetDefault("jwt.expiry", 86400)
+	v.SetDefault("jwt.expiry", 900)
+	v.SetDefault("jwt.refresh_expiry", 2592000)
 
 	v.SetDefault("security.allow_ips", []string{"127.0.0.1", "localhost", "::1"})
 	v.SetDefault("security.rate_limit", 100)
 
+	v.SetDefault("login.max_failures", 5)
+	v.SetDefault("login.ip_max_failures", 20)
+	v.SetDefault("login.failure_window", 900)
+	v.SetDefault("login.lockout", 900)
+	v.SetDefault("login.backoff_base", 1)
+	v.SetDefault("login.backoff_max", 60)
+
 	v.SetDefault("logging.level", "info")
 	v.SetDefault("logging.output", "stdout")
 }
@@ -169,6 +243,7 @@ func bindEnvs() {
 
 	// JWT
 	v.BindEnv("jwt.secret", "WELL_JWT_SECRET")
+	v.BindEnv("jwt.private_key", "WELL_JWT_PRIVATE_KEY")
 }
 
 // parseConfig 解析配置到结构体
@@ -195,18 +270,77 @@ func parseConfig() error {
 	cfg.App.Port = v.GetInt("app.port")
 	cfg.App.Mode = v.GetString("app.mode")
 	cfg.App.BaseURL = strings.TrimSpace(v.GetString("app.base_url"))
+	cfg.App.TrustedProxies = v.GetStringSlice("app.trusted_proxies")
 
 	// JWT
+	cfg.JWT.Algorithm = v.GetString("jwt.algorithm")
+	if cfg.JWT.Algorithm == "" {
+		cfg.JWT.Algorithm = "HS256"
+	}
 	cfg.JWT.Secret = v.GetString("jwt.secret")
+	if cfg.JWT.Algorithm == "HS256" && placeholderJWTSecrets[cfg.JWT.Secret] && cfg.App.Mode != "debug" {
+		return fmt.Errorf("jwt.secret is a placeholder, set jwt.secret or WELL_JWT_SECRET (placeholder allowed only in debug mode)")
+	}
+	cfg.JWT.KeyID = v.GetString("jwt.key_id")
+	cfg.JWT.PrivateKey = v.GetString("jwt.private_key")
+	cfg.JWT.PublicKeys = v.GetStringSlice("jwt.public_keys")
 	cfg.JWT.Expiry = v.GetInt("jwt.expiry")
+	cfg.JWT.RefreshExpiry = v.GetInt("jwt.refresh_expiry")
 
 	// Cache
 	cfg.Cache.L1Cap = v.GetInt("cache.l1_cap")
 	cfg.Cache.L2TTL = v.GetInt("cache.l2_ttl")
+	cfg.Cache.BusChannel = v.GetString("cache.bus_channel")
+	cfg.Cache.NegativeTTL = v.GetInt("cache.negative_ttl")
+	cfg.Cache.TTLJitter = v.GetFloat64("cache.ttl_jitter")
+	if cfg.Cache.BusChannel == "" {
+		cfg.Cache.BusChannel = "well:l1:invalidate"
+	}
 
 	// Snowflake
 	cfg.Snowflake.WorkerID = v.GetInt64("snowflake.worker_id")
 
+	// Thread
+	cfg.Thread.PublishInterval = v.GetInt("thread.publish_interval")
+	if cfg.Thread.PublishInterval <= 0 {
+		cfg.Thread.PublishInterval = 30
+	}
+	cfg.Thread.ViewFlushInterval = v.GetInt("thread.view_flush_interval")
+	if cfg.Thread.ViewFlushInterval <= 0 {
+		cfg.Thread.ViewFlushInterval = 60
+	}
+	cfg.Thread.ViewDedupWindow = v.GetInt("thread.view_dedup_window")
+	if cfg.Thread.ViewDedupWindow <= 0 {
+		cfg.Thread.ViewDedupWindow = 1800
+	}
+
+	// Tag
+	cfg.Tag.RelatedInterval = v.GetInt("tag.related_interval")
+	if cfg.Tag.RelatedInterval <= 0 {
+		cfg.Tag.RelatedInterval = 3600
+	}
+	cfg.Tag.SuggestRefresh = v.GetInt("tag.suggest_refresh")
+	if cfg.Tag.SuggestRefresh <= 0 {
+		cfg.Tag.SuggestRefresh = 60
+	}
+
+	// Trash
+	cfg.Trash.RetentionDays = v.GetInt("trash.retention_days")
+	if cfg.Trash.RetentionDays <= 0 {
+		cfg.Trash.RetentionDays = 30
+	}
+	cfg.Trash.PurgeInterval = v.GetInt("trash.purge_interval")
+	if cfg.Trash.PurgeInterval <= 0 {
+		cfg.Trash.PurgeInterval = 3600
+	}
+
+	// SEO
+	cfg.SEO.IndexNowKey = v.GetString("seo.indexnow_key")
+	cfg.SEO.IndexNowEndpoint = v.GetString("seo.indexnow_endpoint")
+	if cfg.SEO.IndexNowEndpoint == "" {
+		cfg.SEO.IndexNowEndpoint = "https://api.indexnow.org/indexnow"
+	}
+
 	// Logging
 	cfg.Logging.Level = v.GetString("logging.level")
 	cfg.Logging.Output = v.GetString("logging.output")
@@ -220,6 +354,32 @@ func parseConfig() error {
 	cfg.Security.DenyIPs = v.GetStringSlice("security.deny_ips")
 	cfg.Security.RateLimit = v.GetInt("security.rate_limit")
 
+	// Login
+	cfg.Login.MaxFailures = v.GetInt("login.max_failures")
+	if cfg.Login.MaxFailures <= 0 {
+		cfg.Login.MaxFailures = 5
+	}
+	cfg.Login.IPMaxFailures = v.GetInt("login.ip_max_failures")
+	if cfg.Login.IPMaxFailures <= 0 {
+		cfg.Login.IPMaxFailures = 20
+	}
+	cfg.Login.FailureWindow = v.GetInt("login.failure_window")
+	if cfg.Login.FailureWindow <= 0 {
+		cfg.Login.FailureWindow = 900
+	}
+	cfg.Login.Lockout = v.GetInt("login.lockout")
+	if cfg.Login.Lockout <= 0 {
+		cfg.Login.Lockout = 900
+	}
+	cfg.Login.BackoffBase = v.GetInt("login.backoff_base")
+	if cfg.Login.BackoffBase <= 0 {
+		cfg.Login.BackoffBase = 1
+	}
+	cfg.Login.BackoffMax = v.GetInt("login.backoff_max")
+	if cfg.Login.BackoffMax <= 0 {
+		cfg.Login.BackoffMax = 60
+	}
+
 	return nil
 }
 
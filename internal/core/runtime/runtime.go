@@ -3,10 +3,16 @@ package runtime
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"well_go/internal/core/logger"
+	"well_go/internal/model"
+	"well_go/internal/pkg/pinyin"
+	"well_go/internal/pkg/pool"
 	"well_go/internal/repository"
 	"well_go/internal/service"
 )
@@ -20,8 +26,17 @@ type Runtime struct {
 	accessMaps map[int]map[int][]int // fid -> gid -> permissions
 	mu         sync.RWMutex
 	loadedAt   time.Time
+	forumSvc   *service.ForumService
+	tagSvc     *service.TagService
 }
 
+// 跨实例重新加载事件（经 pool 失效总线广播）
+const (
+	eventForumsChanged = "runtime:forums"
+	eventTagsChanged   = "runtime:tags"
+	eventAccessChanged = "runtime:access"
+)
+
 // Singleton instance
 var rt *Runtime
 var once sync.Once
@@ -41,7 +56,12 @@ func Init(cfg *RuntimeConfig) error {
 		rt = &Runtime{
 			config:     make(map[string]string),
 			accessMaps: make(map[int]map[int][]int),
+			forumSvc:   cfg.ForumSvc,
+			tagSvc:     cfg.TagSvc,
 		}
+		pool.OnEvent(eventForumsChanged, rt.reloadForums)
+		pool.OnEvent(eventTagsChanged, rt.reloadTags)
+		pool.OnEvent(eventAccessChanged, rt.reloadAccess)
 		initErr = rt.warmup(cfg)
 	})
 	return initErr
@@ -96,7 +116,14 @@ func (r *Runtime) warmup(cfg *RuntimeConfig) error {
 		}
 	}
 
-	// 3. 预热配置（从数据库或其他来源）
+	// 3. 预热版块权限
+	if cfg.ForumSvc != nil {
+		if err := r.refreshAccess(ctx, cfg.ForumSvc); err != nil {
+			logger.Error("warmup forum access failed", logger.String("error", err.Error()))
+		}
+	}
+
+	// 4. 预热配置（从数据库或其他来源）
 	// TODO: 从数据库加载站点配置
 	r.mu.Lock()
 	r.config["site_name"] = "WellCMS Go"
@@ -115,6 +142,116 @@ func (r *Runtime) Reload(cfg *RuntimeConfig) error {
 	return r.warmup(cfg)
 }
 
+// RefreshForums 重新加载 Forum 列表与树（版块增删/恢复后调用）
+func (r *Runtime) RefreshForums(ctx context.Context, forumSvc *service.ForumService) error {
+	list, err := forumSvc.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	tree, err := forumSvc.GetTree(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.forumList = list
+	r.forumTree = tree
+	r.mu.Unlock()
+	return nil
+}
+
+// RefreshTags 重新加载 Tag 列表（标签删除/恢复后调用）
+func (r *Runtime) RefreshTags(ctx context.Context, tagSvc *service.TagService) error {
+	list, err := tagSvc.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.tagList = list
+	r.mu.Unlock()
+	return nil
+}
+
+// ForumsChanged 版块变更后重新加载本实例的 Forum 列表与树，并通知其它实例
+// 变更已写入，重新加载失败只记录日志，不影响接口结果
+func (r *Runtime) ForumsChanged(ctx context.Context) {
+	r.reloadForums(ctx)
+	pool.Notify(ctx, eventForumsChanged)
+}
+
+// TagsChanged 标签变更后重新加载本实例的 Tag 列表，并通知其它实例
+func (r *Runtime) TagsChanged(ctx context.Context) {
+	r.reloadTags(ctx)
+	pool.Notify(ctx, eventTagsChanged)
+}
+
+func (r *Runtime) reloadForums(ctx context.Context) {
+	if r.forumSvc == nil {
+		return
+	}
+	if err := r.RefreshForums(ctx, r.forumSvc); err != nil {
+		logger.Error("runtime: refresh forums failed", logger.String("error", err.Error()))
+	}
+}
+
+func (r *Runtime) reloadTags(ctx context.Context) {
+	if r.tagSvc == nil {
+		return
+	}
+	if err := r.RefreshTags(ctx, r.tagSvc); err != nil {
+		logger.Error("runtime: refresh tags failed", logger.String("error", err.Error()))
+	}
+}
+
+// refreshAccess 重新加载全部版块权限
+func (r *Runtime) refreshAccess(ctx context.Context, forumSvc *service.ForumService) error {
+	rows, err := forumSvc.GetAllAccess(ctx)
+	if err != nil {
+		return err
+	}
+	accessMaps := make(map[int]map[int][]int)
+	for _, a := range rows {
+		if accessMaps[a.Fid] == nil {
+			accessMaps[a.Fid] = make(map[int][]int)
+		}
+		accessMaps[a.Fid][a.Gid] = a.Perms()
+	}
+
+	r.mu.Lock()
+	r.accessMaps = accessMaps
+	r.mu.Unlock()
+	logger.Info("runtime: forum access loaded", logger.Int("count", len(rows)))
+	return nil
+}
+
+func (r *Runtime) reloadAccess(ctx context.Context) {
+	if r.forumSvc == nil {
+		return
+	}
+	if err := r.refreshAccess(ctx, r.forumSvc); err != nil {
+		logger.Error("runtime: refresh forum access failed", logger.String("error", err.Error()))
+	}
+}
+
+// RunTagRefresher 定时重新加载 Tag 列表，阻塞直到 ctx 结束
+// 发帖时自动创建的标签及其它实例的标签变更不经过 RefreshTags，由此在一个间隔内同步到自动补全
+func (r *Runtime) RunTagRefresher(ctx context.Context, tagSvc *service.TagService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.RefreshTags(ctx, tagSvc); err != nil {
+				logger.Error("runtime: refresh tags failed", logger.String("error", err.Error()))
+			}
+		}
+	}
+}
+
 // GetForumList 获取 Forum 列表
 func (r *Runtime) GetForumList() []*service.ForumDTO {
 	r.mu.RLock()
@@ -122,6 +259,53 @@ func (r *Runtime) GetForumList() []*service.ForumDTO {
 	return r.forumList
 }
 
+// ReadableForums 返回用户组可读的版块 fid
+// root > 0 时只包含 root 及其全部子孙版块（按 path 祖先链判断）
+func (r *Runtime) ReadableForums(gid, root int) []int {
+	list := r.GetForumList()
+
+	prefix := ""
+	if root > 0 {
+		for _, f := range list {
+			if f.Fid == root {
+				prefix = f.Path + "," + strconv.Itoa(root)
+				break
+			}
+		}
+		if prefix == "" {
+			return []int{}
+		}
+	}
+
+	fids := make([]int, 0, len(list))
+	for _, f := range list {
+		if root > 0 && f.Fid != root && f.Path != prefix && !strings.HasPrefix(f.Path, prefix+",") {
+			continue
+		}
+		if r.CheckAccess(f.Fid, gid, model.AccessRead) {
+			fids = append(fids, f.Fid)
+		}
+	}
+	return fids
+}
+
+// ForumChain 返回版块及其全部祖先的 fid（版主权限按祖先链继承）
+func (r *Runtime) ForumChain(fid int) []int {
+	for _, f := range r.GetForumList() {
+		if f.Fid != fid {
+			continue
+		}
+		chain := []int{fid}
+		for _, s := range strings.Split(f.Path, ",") {
+			if id, err := strconv.Atoi(s); err == nil && id > 0 {
+				chain = append(chain, id)
+			}
+		}
+		return chain
+	}
+	return []int{fid}
+}
+
 // GetForumTree 获取 Forum 树
 func (r *Runtime) GetForumTree() []*service.ForumTreeNode {
 	r.mu.RLock()
@@ -136,6 +320,92 @@ func (r *Runtime) GetTagList() []*service.TagDTO {
 	return r.tagList
 }
 
+// SuggestTags 标签自动补全：名称或拼音 slug 前缀匹配（不区分大小写，忽略 slug 中的 "-"）
+// 名称完全相同的排最前，其次名称前缀匹配，再按关联主题数倒序
+func (r *Runtime) SuggestTags(q string, limit int) []*service.TagDTO {
+	q = strings.ToLower(strings.TrimSpace(q))
+	if q == "" {
+		return []*service.TagDTO{}
+	}
+	py := strings.ReplaceAll(pinyin.Slug(q, 0), "-", "")
+
+	type match struct {
+		tag  *service.TagDTO
+		rank int // 0 名称相同，1 名称前缀，2 slug 前缀
+	}
+	var matches []match
+	for _, t := range r.GetTagList() {
+		name := strings.ToLower(t.Name)
+		switch {
+		case name == q:
+			matches = append(matches, match{t, 0})
+		case strings.HasPrefix(name, q):
+			matches = append(matches, match{t, 1})
+		case py != "" && strings.HasPrefix(strings.ReplaceAll(t.Slug, "-", ""), py):
+			matches = append(matches, match{t, 2})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].rank != matches[j].rank {
+			return matches[i].rank < matches[j].rank
+		}
+		return matches[i].tag.Threads > matches[j].tag.Threads
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	list := make([]*service.TagDTO, 0, len(matches))
+	for _, m := range matches {
+		list = append(list, m.tag)
+	}
+	return list
+}
+
+// CheckAccess 检查用户组在版块的权限（perm 取 model.Access*）
+// 版块未配置 forum_access 时默认放行；管理员组始终放行；已配置但无该组记录时拒绝
+func (r *Runtime) CheckAccess(fid, gid, perm int) bool {
+	if gid == model.GroupAdmin {
+		return true
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	groups, ok := r.accessMaps[fid]
+	if !ok || len(groups) == 0 {
+		return true
+	}
+	perms, ok := groups[gid]
+	if !ok || perm < 0 || perm >= len(perms) {
+		return false
+	}
+	return perms[perm] == 1
+}
+
+// ForumAccessChanged 替换本实例单个版块的权限，并通知其它实例重新加载（mgt 修改后调用）
+func (r *Runtime) ForumAccessChanged(ctx context.Context, fid int, rows []*service.ForumAccessDTO) {
+	r.SetForumAccess(fid, rows)
+	pool.Notify(ctx, eventAccessChanged)
+}
+
+// SetForumAccess 替换单个版块的权限
+func (r *Runtime) SetForumAccess(fid int, rows []*service.ForumAccessDTO) {
+	groups := make(map[int][]int, len(rows))
+	for _, a := range rows {
+		groups[a.Gid] = a.Perms()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(groups) == 0 {
+		delete(r.accessMaps, fid)
+		return
+	}
+	r.accessMaps[fid] = groups
+}
+
 // GetConfig 获取配置
 func (r *Runtime) GetConfig(key string) string {
 	r.mu.RLock()
@@ -172,6 +442,7 @@ func (r *Runtime) Status() map[string]interface{} {
 		"forum_count":  len(r.forumList),
 		"forum_tree":   len(r.forumTree),
 		"tag_count":    len(r.tagList),
+		"access_count": len(r.accessMaps),
 		"config_count": len(r.config),
 		"loaded_at":    r.loadedAt.Format("2006-01-02 15:04:05"),
 	}
@@ -2,14 +2,15 @@ package middleware
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
-	"well_go/internal/core/config"
 	"well_go/internal/core/logger"
+	"well_go/internal/model"
+	"well_go/internal/pkg/jwtkey"
 )
 
 // LoggerMiddleware 请求日志中间件
@@ -100,16 +101,13 @@ func CORSMiddleware() gin.HandlerFunc {
 	}
 }
 
-// UserClaims 自定义 JWT Claims
-type UserClaims struct {
-	UID      int64  `json:"uid"`
-	Username string `json:"username"`
-	Role     int    `json:"role"`
-	jwt.RegisteredClaims
+// TokenChecker 查询 access token 是否已被撤销（service.TokenService 实现）
+type TokenChecker interface {
+	Revoked(ctx context.Context, sid, jti string) (bool, error)
 }
 
-// JWTMW JWT中间件
-func JWTMW(cfg *config.JWTConfig) gin.HandlerFunc {
+// JWTMW JWT中间件，签名有效后再检查会话是否已注销、jti 是否在黑名单中
+func JWTMW(keys *jwtkey.KeySet, checker TokenChecker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		token := c.GetHeader("Authorization")
 		if token == "" {
@@ -131,7 +129,7 @@ func JWTMW(cfg *config.JWTConfig) gin.HandlerFunc {
 		token = strings.TrimPrefix(token, "Bearer ")
 
 		// 解析JWT
-		claims, err := ParseJWT(token, cfg.Secret)
+		claims, err := keys.Parse(token)
 		if err != nil {
 			c.AbortWithStatusJSON(401, gin.H{
 				"code": 401,
@@ -140,50 +138,85 @@ func JWTMW(cfg *config.JWTConfig) gin.HandlerFunc {
 			return
 		}
 
-		// 提取用户信息
-		if uid, ok := claims["uid"].(float64); ok {
-			c.Set("uid", int64(uid))
-		}
-		if role, ok := claims["role"].(float64); ok {
-			c.Set("role", int(role))
+		sid, _ := claims["sid"].(string)
+		jti, _ := claims["jti"].(string)
+		revoked, err := checker.Revoked(c.Request.Context(), sid, jti)
+		if err != nil {
+			logger.Error("jwt: check revocation failed", logger.String("error", err.Error()))
+			c.AbortWithStatusJSON(503, gin.H{
+				"code": 503,
+				"msg":  "auth service unavailable",
+			})
+			return
 		}
-		if username, ok := claims["username"].(string); ok {
-			c.Set("username", username)
+		if revoked {
+			c.AbortWithStatusJSON(401, gin.H{
+				"code": 401,
+				"msg":  "token revoked",
+			})
+			return
 		}
 
+		setClaims(c, claims)
+
 		c.Next()
 	}
 }
 
-// ParseJWT 解析JWT
-func ParseJWT(tokenString, secret string) (map[string]interface{}, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return []byte(secret), nil
-	})
-	if err != nil {
-		return nil, err
-	}
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		return map[string]interface{}(claims), nil
+// OptionalJWTMW 可选 JWT 中间件（Public API 用）
+// 携带有效且未撤销的 Token 时提取用户信息，否则按游客处理，不拦截请求
+func OptionalJWTMW(keys *jwtkey.KeySet, checker TokenChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("gid", model.GroupGuest)
+
+		token := c.GetHeader("Authorization")
+		if strings.HasPrefix(token, "Bearer ") {
+			if claims, err := keys.Parse(strings.TrimPrefix(token, "Bearer ")); err == nil {
+				sid, _ := claims["sid"].(string)
+				jti, _ := claims["jti"].(string)
+				if revoked, err := checker.Revoked(c.Request.Context(), sid, jti); err == nil && !revoked {
+					setClaims(c, claims)
+				}
+			}
+		}
+
+		c.Next()
 	}
-	return nil, fmt.Errorf("invalid token")
 }
 
-// GenerateToken 生成 JWT Token
-func GenerateToken(uid int64, username string, role int, cfg *config.JWTConfig) (string, error) {
-	claims := UserClaims{
-		UID:      uid,
-		Username: username,
-		Role:     role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(cfg.Expiry) * time.Second)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-			Issuer:    "well_go",
-			Subject:   fmt.Sprintf("%d", uid),
-		},
+// claimInt64 读取整数 claim；解析时使用 json.Number，避免 19 位雪花 uid 经 float64 丢失精度
+func claimInt64(claims map[string]interface{}, key string) (int64, bool) {
+	switch v := claims[key].(type) {
+	case json.Number:
+		n, err := v.Int64()
+		return n, err == nil
+	case float64:
+		return int64(v), true
 	}
+	return 0, false
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(cfg.Secret))
+// setClaims 提取用户信息到上下文，并由 role 推导用户组
+func setClaims(c *gin.Context, claims map[string]interface{}) {
+	if uid, ok := claimInt64(claims, "uid"); ok {
+		c.Set("uid", uid)
+	}
+	role := 0
+	if r, ok := claimInt64(claims, "role"); ok {
+		role = int(r)
+	}
+	c.Set("role", role)
+	c.Set("gid", model.GroupOfRole(role))
+	if username, ok := claims["username"].(string); ok {
+		c.Set("username", username)
+	}
+	if sid, ok := claims["sid"].(string); ok {
+		c.Set("sid", sid)
+	}
+	if jti, ok := claims["jti"].(string); ok {
+		c.Set("jti", jti)
+	}
+	if exp, ok := claimInt64(claims, "exp"); ok {
+		c.Set("exp", exp)
+	}
 }
@@ -0,0 +1,109 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"well_go/internal/api/mgt"
+	"well_go/internal/middleware"
+	"well_go/internal/model"
+	"well_go/internal/pkg/jwtkey"
+)
+
+type allowAll struct{}
+
+func (allowAll) Revoked(ctx context.Context, sid, jti string) (bool, error) { return false, nil }
+
+// TestJWTSnowflakeUID 19 位雪花 uid 经签发、解析、写入上下文后不丢精度
+func TestJWTSnowflakeUID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const uid int64 = 1981234567890123457
+
+	keys, err := jwtkey.Load(jwtkey.Options{Secret: "test-secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := time.Now().Add(time.Minute).Unix()
+	token, err := keys.Sign(jwt.MapClaims{"uid": uid, "role": 1, "sid": "s", "jti": "j", "exp": exp})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var gotUID, gotExp int64
+	var gotRole int
+	router := gin.New()
+	router.GET("/", middleware.JWTMW(keys, allowAll{}), func(c *gin.Context) {
+		gotUID = mgt.GetUIDFromContext(c)
+		gotExp = c.GetInt64("exp")
+		gotRole = c.GetInt("role")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if gotUID != uid {
+		t.Fatalf("uid = %d, want %d", gotUID, uid)
+	}
+	if gotExp != exp || gotRole != 1 {
+		t.Fatalf("exp = %d role = %d", gotExp, gotRole)
+	}
+}
+
+type staticGrants struct{ grants *model.Grants }
+
+func (s staticGrants) Grants(ctx context.Context, uid int64) (*model.Grants, error) {
+	return s.grants, nil
+}
+
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	member := model.NewGrants(nil)
+	moderator := model.NewGrants([]*model.UserRole{{Fid: 10, Permissions: "thread.move"}})
+	admin := model.NewGrants([]*model.UserRole{{Fid: 0, Permissions: "*"}})
+
+	tests := []struct {
+		name       string
+		grants     *model.Grants
+		uid        int64
+		perm       string
+		wantStatus int
+		wantScoped bool
+	}{
+		{"global grant", admin, 1, model.PermThreadDelete, http.StatusOK, false},
+		{"member default", member, 1, model.PermThreadCreate, http.StatusOK, false},
+		{"ownable fallback", member, 1, model.PermThreadUpdate, http.StatusOK, true},
+		{"not ownable", member, 1, model.PermThreadDelete, http.StatusForbidden, false},
+		{"forum grant", moderator, 1, model.PermThreadMove, http.StatusOK, true},
+		{"forum grant other perm", moderator, 1, model.PermThreadDelete, http.StatusForbidden, false},
+		{"global-only perm", moderator, 1, model.PermTagUpdate, http.StatusForbidden, false},
+		{"anonymous", admin, 0, model.PermThreadCreate, http.StatusUnauthorized, false},
+	}
+	for _, tt := range tests {
+		authz := middleware.NewAuthorizer(staticGrants{tt.grants})
+		var scoped bool
+		router := gin.New()
+		router.GET("/", func(c *gin.Context) {
+			if tt.uid > 0 {
+				c.Set("uid", tt.uid)
+			}
+		}, authz.RequirePermission(tt.perm), func(c *gin.Context) {
+			scoped = c.GetBool("perm_scoped")
+		})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if w.Code != tt.wantStatus || scoped != tt.wantScoped {
+			t.Errorf("%s: status = %d scoped = %v, want %d %v", tt.name, w.Code, scoped, tt.wantStatus, tt.wantScoped)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"well_go/internal/core/logger"
+	"well_go/internal/model"
+)
+
+// GrantsLoader 查询用户有效权限（service.RBACService 实现）
+type GrantsLoader interface {
+	Grants(ctx context.Context, uid int64) (*model.Grants, error)
+}
+
+// Authorizer 权限校验中间件工厂
+type Authorizer struct {
+	loader GrantsLoader
+}
+
+// NewAuthorizer 创建 Authorizer
+func NewAuthorizer(loader GrantsLoader) *Authorizer {
+	return &Authorizer{loader: loader}
+}
+
+// RequirePermission 要求当前用户拥有 perm（须在 JWTMW 之后）
+// 全站拥有时直接放行；thread./post. 权限在仅有版块授予或可能是作者本人时也放行，
+// 并设置 perm_scoped，由处理函数按目标版块/作者再次校验
+func (a *Authorizer) RequirePermission(perm string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		uid, _ := c.Get("uid")
+		id, ok := uid.(int64)
+		if !ok || id <= 0 {
+			c.AbortWithStatusJSON(401, gin.H{
+				"code": 401,
+				"msg":  "unauthorized",
+			})
+			return
+		}
+
+		grants, err := a.loader.Grants(c.Request.Context(), id)
+		if err != nil {
+			logger.Error("rbac: load grants failed", logger.Int64("uid", id), logger.String("error", err.Error()))
+			c.AbortWithStatusJSON(500, gin.H{
+				"code": 500,
+				"msg":  "internal server error",
+			})
+			return
+		}
+		c.Set("grants", grants)
+
+		switch {
+		case grants.Has(perm):
+		case model.ForumScoped(perm) && (grants.HasInAnyForum(perm) || model.Ownable(perm)):
+			c.Set("perm_scoped", true)
+		default:
+			c.AbortWithStatusJSON(403, gin.H{
+				"code": 403,
+				"msg":  "permission denied: " + perm,
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,18 @@
+package model
+
+// 审计事件
+const (
+	AuditLoginLockout = "login.lockout" // 登录失败过多被临时锁定
+	AuditLoginUnlock  = "login.unlock"  // 管理员解除登录锁定
+)
+
+// AuditLog 审计日志
+type AuditLog struct {
+	ID       int64  `db:"id" json:"id"`
+	Action   string `db:"action" json:"action"`
+	Uid      int64  `db:"uid" json:"uid"`
+	Target   string `db:"target" json:"target"`
+	IP       string `db:"ip" json:"ip"`
+	Detail   string `db:"detail" json:"detail"`
+	Dateline int    `db:"dateline" json:"dateline"`
+}
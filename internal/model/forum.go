@@ -15,6 +15,7 @@ type Forum struct {
 	Today    int       `db:"today"`     // 今日主题
 	Posts    int       `db:"posts"`     // 帖子数
 	Status   int       `db:"status"`    // 状态（0正常1禁用）
+	DeletedAt int      `db:"deleted_at"` // 移入回收站时间（0 表示未删除）
 	CreatedAt time.Time `db:"created_at"`
 	UpdatedAt time.Time `db:"updated_at"`
 }
@@ -50,3 +51,12 @@ type ForumAccess struct {
 	Upload   int       `db:"upload"`    // 上传权限
 	Download int       `db:"download"`  // 下载权限
 }
+
+// 版块权限位（对应 forum_access 列顺序）
+const (
+	AccessRead = iota
+	AccessThread
+	AccessReply
+	AccessUpload
+	AccessDownload
+)
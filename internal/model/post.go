@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// 回帖状态
+const (
+	PostNormal  = 0 // 正常
+	PostBlocked = 1 // 屏蔽
+)
+
+// ValidPostStatus 是否为合法的回帖状态
+func ValidPostStatus(status int) bool {
+	return status == PostNormal || status == PostBlocked
+}
+
+// Post 回帖模型
+type Post struct {
+	Pid       int64     `db:"pid"`
+	Tid       int64     `db:"tid"`
+	Uid       int64     `db:"uid"`
+	Message   string    `db:"message"`
+	Dateline  int       `db:"dateline"`
+	Status    int       `db:"status"` // 状态（0正常1屏蔽）
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
@@ -0,0 +1,158 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// 权限字符串，"模块.操作"；角色中可用 "*" 表示全部、"thread.*" 表示模块下全部
+const (
+	PermForumCreate = "forum.create"
+	PermForumUpdate = "forum.update" // 修改、移动、排序
+	PermForumDelete = "forum.delete"
+	PermForumAccess = "forum.access" // 版块用户组权限
+
+	PermThreadCreate  = "thread.create"
+	PermThreadUpdate  = "thread.update" // 编辑、查看/回滚修订
+	PermThreadDelete  = "thread.delete"
+	PermThreadMove    = "thread.move"
+	PermThreadPublish = "thread.publish"
+
+	PermPostCreate = "post.create"
+	PermPostUpdate = "post.update"
+	PermPostDelete = "post.delete"
+
+	PermTagCreate = "tag.create"
+	PermTagUpdate = "tag.update" // 改名、slug、合并、同义词
+	PermTagDelete = "tag.delete"
+
+	PermTrashManage  = "trash.manage"
+	PermSearchManage = "search.manage"
+	PermCacheManage  = "cache.manage"
+	PermRoleManage   = "role.manage"
+	PermUserManage   = "user.manage" // 强制下线等账号管理
+)
+
+// 内置角色 ID（见 scripts/rbac.sql）
+const (
+	RoleAdmin     = 1
+	RoleEditor    = 2
+	RoleModerator = 3
+)
+
+// MemberPermissions 所有登录用户默认拥有的权限（仍受 forum_access 限制）
+var MemberPermissions = []string{PermThreadCreate, PermPostCreate}
+
+// ForumScoped 可按版块授予的权限（版主）；其余权限只能全站授予
+func ForumScoped(perm string) bool {
+	return strings.HasPrefix(perm, "thread.") || strings.HasPrefix(perm, "post.")
+}
+
+// Ownable 作者对自己的内容默认拥有的权限
+func Ownable(perm string) bool {
+	return perm == PermThreadUpdate || perm == PermPostUpdate || perm == PermPostDelete
+}
+
+// Role 角色
+type Role struct {
+	RoleID      int       `db:"role_id"`
+	Name        string    `db:"name"`
+	Title       string    `db:"title"`
+	Permissions string    `db:"permissions"` // 逗号分隔
+	CreatedAt   time.Time `db:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
+
+// Perms 解析权限列表
+func (r *Role) Perms() []string {
+	return SplitPerms(r.Permissions)
+}
+
+// SplitPerms 解析逗号分隔的权限列表（忽略空项）
+func SplitPerms(s string) []string {
+	perms := make([]string, 0, 8)
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			perms = append(perms, p)
+		}
+	}
+	return perms
+}
+
+// UserRole 用户角色（Fid=0 全站，>0 限定版块及其子版块）
+type UserRole struct {
+	Uid         int64  `db:"uid" json:"uid"`
+	RoleID      int    `db:"role_id" json:"role_id"`
+	Fid         int    `db:"fid" json:"fid"`
+	Name        string `db:"name" json:"name"`
+	Permissions string `db:"permissions" json:"-"`
+}
+
+// Grants 用户的有效权限
+type Grants struct {
+	Global []string         `json:"global"`
+	Forums map[int][]string `json:"forums,omitempty"` // fid → 权限
+}
+
+// NewGrants 由用户角色汇总有效权限（含登录用户默认权限）
+func NewGrants(roles []*UserRole) *Grants {
+	g := &Grants{Global: append([]string(nil), MemberPermissions...), Forums: map[int][]string{}}
+	for _, r := range roles {
+		perms := SplitPerms(r.Permissions)
+		if r.Fid == 0 {
+			g.Global = append(g.Global, perms...)
+			continue
+		}
+		for _, p := range perms {
+			if ForumScoped(p) || p == "*" {
+				g.Forums[r.Fid] = append(g.Forums[r.Fid], p)
+			}
+		}
+	}
+	return g
+}
+
+// Has 是否全站拥有权限
+func (g *Grants) Has(perm string) bool {
+	return matchPerm(g.Global, perm)
+}
+
+// HasInForums 是否拥有权限：全站授予，或在 fids（版块及其祖先）中任一版块授予
+func (g *Grants) HasInForums(perm string, fids []int) bool {
+	if g.Has(perm) {
+		return true
+	}
+	if !ForumScoped(perm) {
+		return false
+	}
+	for _, fid := range fids {
+		if matchPerm(g.Forums[fid], perm) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasInAnyForum 是否在任一版块拥有权限
+func (g *Grants) HasInAnyForum(perm string) bool {
+	if !ForumScoped(perm) {
+		return false
+	}
+	for _, perms := range g.Forums {
+		if matchPerm(perms, perm) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPerm 权限匹配，支持 "*" 与 "模块.*"
+func matchPerm(perms []string, perm string) bool {
+	module, _, _ := strings.Cut(perm, ".")
+	for _, p := range perms {
+		if p == perm || p == "*" || p == module+".*" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package model
+
+import "testing"
+
+func TestMatchPerm(t *testing.T) {
+	tests := []struct {
+		perms []string
+		perm  string
+		want  bool
+	}{
+		{[]string{"thread.update"}, "thread.update", true},
+		{[]string{"thread.update"}, "thread.delete", false},
+		{[]string{"*"}, "role.manage", true},
+		{[]string{"thread.*"}, "thread.move", true},
+		{[]string{"thread.*"}, "post.update", false},
+		{[]string{"thread.*"}, "threads.update", false},
+		{[]string{"thread"}, "thread.update", false},
+		{nil, "thread.create", false},
+	}
+	for _, tt := range tests {
+		if got := matchPerm(tt.perms, tt.perm); got != tt.want {
+			t.Errorf("matchPerm(%v, %q) = %v, want %v", tt.perms, tt.perm, got, tt.want)
+		}
+	}
+}
+
+func TestGrants(t *testing.T) {
+	grants := NewGrants([]*UserRole{
+		{RoleID: RoleEditor, Fid: 0, Permissions: "tag.*,trash.manage"},
+		// 版主：版块授予只保留 thread./post. 权限，role.manage 被忽略
+		{RoleID: RoleModerator, Fid: 10, Permissions: "thread.update,thread.move,post.*,role.manage"},
+		{RoleID: 9, Fid: 20, Permissions: "*"},
+	})
+
+	tests := []struct {
+		name string
+		perm string
+		fids []int // 版块及祖先链
+		want bool
+	}{
+		{"member default", PermThreadCreate, nil, true},
+		{"global module wildcard", PermTagDelete, nil, true},
+		{"global not granted", PermRoleManage, nil, false},
+		{"forum grant", PermThreadMove, []int{10}, true},
+		{"forum grant via ancestor", PermThreadMove, []int{11, 10}, true},
+		{"forum grant other forum", PermThreadMove, []int{12, 3}, false},
+		{"forum module wildcard", PermPostDelete, []int{10}, true},
+		{"forum grant not listed", PermThreadDelete, []int{10}, false},
+		{"forum-scoped role.manage ignored", PermRoleManage, []int{10}, false},
+		{"forum star only thread/post", PermThreadDelete, []int{20}, true},
+		{"forum star not global perm", PermSearchManage, []int{20}, false},
+	}
+	for _, tt := range tests {
+		if got := grants.HasInForums(tt.perm, tt.fids); got != tt.want {
+			t.Errorf("%s: HasInForums(%q, %v) = %v, want %v", tt.name, tt.perm, tt.fids, got, tt.want)
+		}
+	}
+
+	if grants.Has(PermThreadMove) {
+		t.Error("forum grant leaked into global")
+	}
+	if !grants.HasInAnyForum(PermThreadMove) || grants.HasInAnyForum(PermTagCreate) {
+		t.Error("HasInAnyForum")
+	}
+}
+
+func TestOwnable(t *testing.T) {
+	for perm, want := range map[string]bool{
+		PermThreadUpdate: true,
+		PermPostUpdate:   true,
+		PermPostDelete:   true,
+		PermThreadDelete: false,
+		PermThreadMove:   false,
+		PermThreadCreate: false,
+	} {
+		if got := Ownable(perm); got != want {
+			t.Errorf("Ownable(%q) = %v, want %v", perm, got, want)
+		}
+	}
+}
@@ -10,10 +10,17 @@ type Tag struct {
 	Threads  int       `db:"threads"`    // 关联主题数
 	View     int       `db:"view"`      // 浏览次数
 	Status   int       `db:"status"`    // 状态
+	DeletedAt int      `db:"deleted_at"` // 移入回收站时间（0 表示未删除）
 	CreatedAt time.Time `db:"created_at"`
 	UpdatedAt time.Time `db:"updated_at"`
 }
 
+// TagNameMaxLen 标签名最大字符数（tag.name 为 VARCHAR(30)）
+const TagNameMaxLen = 30
+
+// TagSlugMaxLen slug 最大长度（tag.slug 为 VARCHAR(60)）
+const TagSlugMaxLen = 60
+
 // TagDTO 标签数据传输对象
 type TagDTO struct {
 	TagID   int    `json:"tag_id"`
@@ -30,3 +37,17 @@ type ThreadTag struct {
 	Tid    int64 `db:"tid"`
 	TagID  int   `db:"tag_id"`
 }
+
+// TagAlias 标签同义词（alias 归并到 tag_id）
+type TagAlias struct {
+	Alias     string    `db:"alias"`
+	TagID     int       `db:"tag_id"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// TagCooccur 两个标签共同出现的主题数
+type TagCooccur struct {
+	TagID     int `db:"tag_id"`
+	RelatedID int `db:"related_id"`
+	Count     int `db:"cnt"`
+}
@@ -4,17 +4,54 @@ import "time"
 
 // Thread Thread主表模型
 type Thread struct {
-	Tid       int64     `db:"tid"`
-	Fid       int       `db:"fid"`
-	Uid       int64     `db:"uid"`
-	Subject   string    `db:"subject"`
-	Views     int       `db:"views"`
-	Replies   int       `db:"replies"`
-	Dateline  int       `db:"dateline"`
-	Lastpost  int       `db:"lastpost"`
-	Status    int       `db:"status"`
-	CreatedAt time.Time `db:"created_at"`
-	UpdatedAt time.Time `db:"updated_at"`
+	Tid         int64     `db:"tid"`
+	Fid         int       `db:"fid"`
+	Uid         int64     `db:"uid"`
+	Subject     string    `db:"subject"`
+	Views       int       `db:"views"`
+	Replies     int       `db:"replies"`
+	Dateline    int       `db:"dateline"`
+	Lastpost    int       `db:"lastpost"`
+	Status      int       `db:"status"`       // 见 Thread* 状态常量
+	PublishAt   int       `db:"publish_at"`   // 定时发布时间（status=ThreadScheduled 时有效）
+	DeletedAt   int       `db:"deleted_at"`   // 移入回收站时间（status=ThreadTrashed 时有效）
+	PrevStatus  int       `db:"prev_status"`  // 移入回收站前的状态，恢复时还原
+	RedirectTid int64     `db:"redirect_tid"` // 移动后留在原版块的跳转占位，指向新主题（0 表示普通主题）
+	CreatedAt   time.Time `db:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
+
+// 主题状态（published 保持 0 以兼容历史数据）
+const (
+	ThreadPublished = 0 // 已发布
+	ThreadDraft     = 1 // 草稿
+	ThreadPending   = 2 // 待审核
+	ThreadScheduled = 3 // 定时发布
+	ThreadHidden    = 4 // 已隐藏
+	ThreadTrashed   = 5 // 回收站
+)
+
+// ValidThreadStatus 是否为合法的主题状态
+func ValidThreadStatus(status int) bool {
+	return status >= ThreadPublished && status <= ThreadTrashed
+}
+
+// 主题列表排序方式
+const (
+	ThreadSortLastpost = "lastpost" // 最后回复（默认）
+	ThreadSortDateline = "dateline" // 最新发布
+	ThreadSortViews    = "views"    // 浏览最多
+	ThreadSortReplies  = "replies"  // 回复最多
+	ThreadSortHot      = "hot"      // 热度（互动量按时间衰减）
+)
+
+// ValidThreadSort 是否为合法的排序方式
+func ValidThreadSort(sort string) bool {
+	switch sort {
+	case ThreadSortLastpost, ThreadSortDateline, ThreadSortViews, ThreadSortReplies, ThreadSortHot:
+		return true
+	}
+	return false
 }
 
 // ThreadData Thread内容表模型
@@ -29,6 +66,26 @@ type ThreadWithContent struct {
 	Message string `db:"message"`
 }
 
+// ThreadRevision 主题修订记录（每次编辑后的标题+内容完整快照）
+type ThreadRevision struct {
+	ID       int64  `db:"id"`
+	Tid      int64  `db:"tid"`
+	Rev      int    `db:"rev"` // 主题内递增版本号，从 1 开始
+	Uid      int64  `db:"uid"` // 编辑人
+	Subject  string `db:"subject"`
+	Message  string `db:"message"`
+	Action   int    `db:"action"`   // 见 Revision* 常量
+	FromRev  int    `db:"from_rev"` // 回滚来源版本（action=RevisionRollback 时有效）
+	Dateline int    `db:"dateline"`
+}
+
+// 修订动作
+const (
+	RevisionCreate   = 0 // 发布时的初始版本
+	RevisionEdit     = 1 // 编辑
+	RevisionRollback = 2 // 回滚
+)
+
 // ThreadDTO Thread数据传输对象
 type ThreadDTO struct {
 	Tid      int64  `json:"tid"`
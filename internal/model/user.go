@@ -17,6 +17,21 @@ type User struct {
 	UpdatedAt time.Time `db:"updated_at"`
 }
 
+// 用户组 ID（对应 forum_access.gid）
+const (
+	GroupGuest  = 0   // 游客（未登录）
+	GroupAdmin  = 1   // 管理员
+	GroupMember = 101 // 普通用户
+)
+
+// GroupOfRole 由 JWT role 推导用户组
+func GroupOfRole(role int) int {
+	if role == 1 {
+		return GroupAdmin
+	}
+	return GroupMember
+}
+
 // UserDTO 用户数据传输对象
 type UserDTO struct {
 	Uid      int64  `json:"uid"`
@@ -49,8 +64,15 @@ type RegisterRequest struct {
 
 // LoginResponse 登录响应
 type LoginResponse struct {
-	Token string  `json:"token"`
-	User  UserDTO `json:"user"`
+	TokenPair
+	User UserDTO `json:"user"`
+}
+
+// TokenPair 访问令牌与刷新令牌
+type TokenPair struct {
+	Token        string `json:"token"`         // Access Token（JWT，短期）
+	ExpiresIn    int    `json:"expires_in"`    // Access Token 有效期(秒)
+	RefreshToken string `json:"refresh_token"` // 一次性，刷新后作废并返回新的
 }
 
 // RegisterResponse 注册响应
@@ -0,0 +1,145 @@
+// Package codec 缓存 DTO 二进制编解码
+//
+// 格式：[version 1B][kind 1B][fields...][crc32 4B]
+//   - 整数使用 zigzag varint，长度前缀使用 uvarint，无长度上限截断
+//   - crc32(IEEE) 覆盖 version 到最后一个字段
+//   - kind 区分 DTO 类型，避免不同类型的数据被误解码
+//
+// 任何长度不足、校验失败、版本/类型不匹配都返回错误而不是 panic，
+// 调用方（TieredCache）据此按未命中处理。
+package codec
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+)
+
+// Version 当前格式版本，字段布局变化时递增
+const Version byte = 2
+
+const (
+	headerLen  = 2
+	trailerLen = 4
+)
+
+var (
+	ErrShort    = errors.New("codec: data too short")
+	ErrChecksum = errors.New("codec: checksum mismatch")
+	ErrVersion  = errors.New("codec: unsupported version")
+	ErrKind     = errors.New("codec: kind mismatch")
+	ErrCorrupt  = errors.New("codec: corrupt field")
+	ErrTrailing = errors.New("codec: trailing bytes")
+)
+
+// Writer 编码器
+type Writer struct {
+	buf []byte
+}
+
+// NewWriter 创建编码器并写入头部
+func NewWriter(kind byte, sizeHint int) *Writer {
+	buf := make([]byte, 0, headerLen+sizeHint+trailerLen)
+	buf = append(buf, Version, kind)
+	return &Writer{buf: buf}
+}
+
+// Int 写入有符号整数
+func (w *Writer) Int(v int64) {
+	w.buf = binary.AppendVarint(w.buf, v)
+}
+
+// Uint 写入无符号整数
+func (w *Writer) Uint(v uint64) {
+	w.buf = binary.AppendUvarint(w.buf, v)
+}
+
+// String 写入字符串（uvarint 长度前缀）
+func (w *Writer) String(s string) {
+	w.buf = binary.AppendUvarint(w.buf, uint64(len(s)))
+	w.buf = append(w.buf, s...)
+}
+
+// Bytes 追加校验和并返回结果
+func (w *Writer) Bytes() []byte {
+	return binary.BigEndian.AppendUint32(w.buf, crc32.ChecksumIEEE(w.buf))
+}
+
+// Reader 解码器，错误是粘滞的：首次出错后后续读取均返回零值
+type Reader struct {
+	data []byte
+	off  int
+	err  error
+}
+
+// NewReader 校验头部与校验和，返回字段解码器
+func NewReader(data []byte, kind byte) (*Reader, error) {
+	if len(data) < headerLen+trailerLen {
+		return nil, ErrShort
+	}
+	body := data[:len(data)-trailerLen]
+	if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(data[len(body):]) {
+		return nil, ErrChecksum
+	}
+	if body[0] != Version {
+		return nil, ErrVersion
+	}
+	if body[1] != kind {
+		return nil, ErrKind
+	}
+	return &Reader{data: body, off: headerLen}, nil
+}
+
+// Int 读取有符号整数
+func (r *Reader) Int() int64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(r.data[r.off:])
+	if n <= 0 {
+		r.err = ErrCorrupt
+		return 0
+	}
+	r.off += n
+	return v
+}
+
+// Uint 读取无符号整数
+func (r *Reader) Uint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.data[r.off:])
+	if n <= 0 {
+		r.err = ErrCorrupt
+		return 0
+	}
+	r.off += n
+	return v
+}
+
+// String 读取字符串
+func (r *Reader) String() string {
+	n := r.Uint()
+	if r.err != nil {
+		return ""
+	}
+	if n > uint64(len(r.data)-r.off) {
+		r.err = ErrCorrupt
+		return ""
+	}
+	s := string(r.data[r.off : r.off+int(n)])
+	r.off += int(n)
+	return s
+}
+
+// Finish 返回解码过程中的错误，并确认数据已全部消费
+func (r *Reader) Finish() error {
+	if r.err != nil {
+		return r.err
+	}
+	if r.off != len(r.data) {
+		return ErrTrailing
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package codec
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	long := strings.Repeat("x", 70000) // 超过旧格式 2 字节长度上限
+
+	w := NewWriter(7, 0)
+	w.Int(-42)
+	w.Uint(1 << 60)
+	w.String(long)
+	w.String("")
+	data := w.Bytes()
+
+	r, err := NewReader(data, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := r.Int(); v != -42 {
+		t.Fatalf("int: %d", v)
+	}
+	if v := r.Uint(); v != 1<<60 {
+		t.Fatalf("uint: %d", v)
+	}
+	if s := r.String(); s != long {
+		t.Fatalf("string length: %d", len(s))
+	}
+	if s := r.String(); s != "" {
+		t.Fatalf("empty string: %q", s)
+	}
+	if err := r.Finish(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReject(t *testing.T) {
+	w := NewWriter(1, 0)
+	w.String("hello")
+	data := w.Bytes()
+
+	if _, err := NewReader(data[:3], 1); err != ErrShort {
+		t.Fatalf("short: %v", err)
+	}
+	if _, err := NewReader(data, 2); err != ErrKind {
+		t.Fatalf("kind: %v", err)
+	}
+
+	bad := append([]byte(nil), data...)
+	bad[3] ^= 0xff
+	if _, err := NewReader(bad, 1); err != ErrChecksum {
+		t.Fatalf("checksum: %v", err)
+	}
+
+	r, _ := NewReader(data, 1)
+	_ = r.String()
+	_ = r.String() // 越界读取
+	if err := r.Finish(); err != ErrCorrupt {
+		t.Fatalf("overread: %v", err)
+	}
+}
+
+func FuzzReader(f *testing.F) {
+	w := NewWriter(1, 0)
+	w.Int(1)
+	w.String("seed")
+	f.Add(w.Bytes())
+	f.Add([]byte{})
+	f.Add([]byte{Version, 1, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		r, err := NewReader(data, 1)
+		if err != nil {
+			return
+		}
+		_ = r.Int()
+		_ = r.String()
+		_ = r.Uint()
+		_ = r.Finish()
+	})
+}
+
+func FuzzRoundTrip(f *testing.F) {
+	f.Add(int64(0), uint64(0), "")
+	f.Add(int64(-1), uint64(1<<63), "中文标题")
+
+	f.Fuzz(func(t *testing.T, i int64, u uint64, s string) {
+		w := NewWriter(3, len(s))
+		w.Int(i)
+		w.Uint(u)
+		w.String(s)
+
+		r, err := NewReader(w.Bytes(), 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Int() != i || r.Uint() != u || r.String() != s {
+			t.Fatal("round trip mismatch")
+		}
+		if err := r.Finish(); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
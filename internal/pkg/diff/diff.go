@@ -0,0 +1,166 @@
+// Package diff 行级文本差异（Myers 算法），用于主题修订对比
+package diff
+
+import "strings"
+
+// Op 差异操作类型
+type Op byte
+
+const (
+	Equal  Op = '='
+	Insert Op = '+'
+	Delete Op = '-'
+)
+
+// Line 差异结果中的一行
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// MaxEdits 编辑距离上限，超过后退化为整体删除+整体插入，避免超大文本占用过多内存
+const MaxEdits = 2000
+
+// Lines 按行比较 a、b，返回从 a 变换到 b 的行序列
+func Lines(a, b string) []Line {
+	return Diff(splitLines(a), splitLines(b))
+}
+
+// Diff 比较两个行序列
+func Diff(a, b []string) []Line {
+	// 公共前后缀直接跳过，缩小 Myers 搜索范围
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	out := make([]Line, 0, len(a)+len(b))
+	for _, s := range a[:prefix] {
+		out = append(out, Line{Equal, s})
+	}
+	out = append(out, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, s := range a[len(a)-suffix:] {
+		out = append(out, Line{Equal, s})
+	}
+	return out
+}
+
+// Stats 统计新增/删除行数
+func Stats(lines []Line) (added, removed int) {
+	for _, l := range lines {
+		switch l.Op {
+		case Insert:
+			added++
+		case Delete:
+			removed++
+		}
+	}
+	return added, removed
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+}
+
+// myers O((N+M)D) 最短编辑脚本
+// trace[d] 保存第 d 轮开始前 k∈[-d,d] 的 V 值快照，用于回溯路径
+func myers(a, b []string) []Line {
+	n, m := len(a), len(b)
+	if n == 0 && m == 0 {
+		return nil
+	}
+	limit := n + m
+	if limit > MaxEdits {
+		limit = MaxEdits
+	}
+
+	off := limit + 1
+	v := make([]int, 2*limit+3)
+	trace := make([][]int, 0, 16)
+
+	for d := 0; d <= limit; d++ {
+		snap := make([]int, 2*d+1)
+		copy(snap, v[off-d:off+d+1])
+		trace = append(trace, snap)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[off+k-1] < v[off+k+1]) {
+				x = v[off+k+1]
+			} else {
+				x = v[off+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[off+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, a, b)
+			}
+		}
+	}
+
+	// 超出上限：整体替换
+	out := make([]Line, 0, n+m)
+	for _, s := range a {
+		out = append(out, Line{Delete, s})
+	}
+	for _, s := range b {
+		out = append(out, Line{Insert, s})
+	}
+	return out
+}
+
+func backtrack(trace [][]int, a, b []string) []Line {
+	x, y := len(a), len(b)
+	rev := make([]Line, 0, x+y)
+
+	for d := len(trace) - 1; d > 0; d-- {
+		snap := trace[d]
+		at := func(k int) int { return snap[k+d] }
+
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			rev = append(rev, Line{Equal, a[x]})
+		}
+		if x == prevX {
+			y--
+			rev = append(rev, Line{Insert, b[y]})
+		} else {
+			x--
+			rev = append(rev, Line{Delete, a[x]})
+		}
+	}
+	// d=0：剩余部分为起点的公共蛇形
+	for x > 0 && y > 0 {
+		x--
+		y--
+		rev = append(rev, Line{Equal, a[x]})
+	}
+
+	out := make([]Line, len(rev))
+	for i, l := range rev {
+		out[len(rev)-1-i] = l
+	}
+	return out
+}
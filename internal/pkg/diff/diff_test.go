@@ -0,0 +1,80 @@
+package diff
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// apply 从差异结果还原 a、b
+func apply(lines []Line) (a, b []string) {
+	for _, l := range lines {
+		switch l.Op {
+		case Equal:
+			a = append(a, l.Text)
+			b = append(b, l.Text)
+		case Delete:
+			a = append(a, l.Text)
+		case Insert:
+			b = append(b, l.Text)
+		}
+	}
+	return a, b
+}
+
+func TestLines(t *testing.T) {
+	lines := Lines("a\nb\nc\nd", "a\nc\nd\ne")
+	added, removed := Stats(lines)
+	if added != 1 || removed != 1 {
+		t.Fatalf("stats = +%d -%d, want +1 -1", added, removed)
+	}
+
+	var got strings.Builder
+	for _, l := range lines {
+		got.WriteByte(byte(l.Op))
+		got.WriteString(l.Text)
+	}
+	if got.String() != "=a-b=c=d+e" {
+		t.Fatalf("diff = %q", got.String())
+	}
+
+	if lines := Lines("", "x"); len(lines) != 1 || lines[0].Op != Insert {
+		t.Fatalf("empty -> x = %+v", lines)
+	}
+	if lines := Lines("same", "same"); len(lines) != 1 || lines[0].Op != Equal {
+		t.Fatalf("same = %+v", lines)
+	}
+}
+
+func TestDiffRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	gen := func() []string {
+		s := make([]string, r.Intn(30))
+		for i := range s {
+			s[i] = string(rune('a' + r.Intn(4)))
+		}
+		return s
+	}
+
+	for i := 0; i < 500; i++ {
+		a, b := gen(), gen()
+		gotA, gotB := apply(Diff(a, b))
+		if strings.Join(gotA, ",") != strings.Join(a, ",") || strings.Join(gotB, ",") != strings.Join(b, ",") {
+			t.Fatalf("round %d: diff does not reproduce inputs\na=%v\nb=%v", i, a, b)
+		}
+	}
+}
+
+func TestDiffMaxEdits(t *testing.T) {
+	a := make([]string, MaxEdits)
+	b := make([]string, MaxEdits)
+	for i := range a {
+		a[i] = "a" + string(rune(i))
+		b[i] = "b" + string(rune(i))
+	}
+	lines := Diff(a, b)
+	added, removed := Stats(lines)
+	if added != MaxEdits || removed != MaxEdits {
+		t.Fatalf("stats = +%d -%d", added, removed)
+	}
+}
@@ -0,0 +1,250 @@
+// Package jwtkey JWT 签名密钥管理：HS256 共享密钥或 RS256/EdDSA 非对称密钥，
+// 支持按 kid 轮换（一个签名密钥 + 多个仍可验证的旧公钥）并导出 JWKS 供其它服务验签
+package jwtkey
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 支持的签名算法
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	EdDSA = "EdDSA"
+)
+
+// minRSABits RSA 密钥最小长度
+const minRSABits = 2048
+
+var (
+	ErrUnknownKey = fmt.Errorf("unknown signing key id")
+	ErrInvalidKey = fmt.Errorf("invalid key")
+)
+
+// Options 密钥配置
+type Options struct {
+	Algorithm  string   // HS256 / RS256 / EdDSA，为空时按 HS256
+	Secret     string   // HS256 共享密钥
+	KeyID      string   // 当前签名密钥 kid
+	PrivateKey string   // 当前签名私钥 PEM 文件
+	PublicKeys []string // 轮换期间仍接受的旧公钥，格式 "kid:PEM 文件"
+}
+
+// KeySet 一组签名/验证密钥，签名与验证只使用配置的算法
+type KeySet struct {
+	method  jwt.SigningMethod
+	kid     string
+	signKey interface{}            // []byte / *rsa.PrivateKey / ed25519.PrivateKey
+	verify  map[string]interface{} // kid → []byte / *rsa.PublicKey / ed25519.PublicKey
+}
+
+// Load 按配置加载密钥
+func Load(opts Options) (*KeySet, error) {
+	switch opts.Algorithm {
+	case "", HS256:
+		if opts.Secret == "" {
+			return nil, fmt.Errorf("%w: empty HS256 secret", ErrInvalidKey)
+		}
+		secret := []byte(opts.Secret)
+		return &KeySet{
+			method:  jwt.SigningMethodHS256,
+			signKey: secret,
+			verify:  map[string]interface{}{"": secret},
+		}, nil
+	case RS256, EdDSA:
+	default:
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidKey, opts.Algorithm)
+	}
+
+	if opts.KeyID == "" || opts.PrivateKey == "" {
+		return nil, fmt.Errorf("%w: %s requires key_id and private_key", ErrInvalidKey, opts.Algorithm)
+	}
+	priv, err := loadPEM(opts.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := priv.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is not a private key", ErrInvalidKey, opts.PrivateKey)
+	}
+
+	ks := &KeySet{kid: opts.KeyID, verify: make(map[string]interface{})}
+	if opts.Algorithm == RS256 {
+		ks.method = jwt.SigningMethodRS256
+	} else {
+		ks.method = jwt.SigningMethodEdDSA
+	}
+	if err := ks.addKey(opts.KeyID, signer.Public()); err != nil {
+		return nil, fmt.Errorf("%s: %w", opts.PrivateKey, err)
+	}
+	ks.signKey = priv
+
+	for _, entry := range opts.PublicKeys {
+		kid, file, ok := strings.Cut(entry, ":")
+		if !ok || kid == "" || file == "" {
+			return nil, fmt.Errorf("%w: public key entry %q, expect kid:file", ErrInvalidKey, entry)
+		}
+		if _, dup := ks.verify[kid]; dup {
+			return nil, fmt.Errorf("%w: duplicate kid %q", ErrInvalidKey, kid)
+		}
+		pub, err := loadPEM(file)
+		if err != nil {
+			return nil, err
+		}
+		if signer, ok := pub.(crypto.Signer); ok {
+			pub = signer.Public() // 允许直接给出旧私钥
+		}
+		if err := ks.addKey(kid, pub); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+	}
+	return ks, nil
+}
+
+// addKey 校验公钥类型与算法一致后加入验证集合
+func (ks *KeySet) addKey(kid string, pub interface{}) error {
+	switch ks.method {
+	case jwt.SigningMethodRS256:
+		k, ok := pub.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w: kid %q is not an RSA key", ErrInvalidKey, kid)
+		}
+		if k.N.BitLen() < minRSABits {
+			return fmt.Errorf("%w: kid %q RSA key shorter than %d bits", ErrInvalidKey, kid, minRSABits)
+		}
+	case jwt.SigningMethodEdDSA:
+		if _, ok := pub.(ed25519.PublicKey); !ok {
+			return fmt.Errorf("%w: kid %q is not an Ed25519 key", ErrInvalidKey, kid)
+		}
+	}
+	ks.verify[kid] = pub
+	return nil
+}
+
+// loadPEM 读取 PEM 文件中的私钥或公钥（PKCS#8 / PKCS#1 / PKIX）
+func loadPEM(file string) (interface{}, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%w: %s is not PEM encoded", ErrInvalidKey, file)
+	}
+
+	var key interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%w: %s unsupported PEM block %q", ErrInvalidKey, file, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidKey, file, err)
+	}
+	return key, nil
+}
+
+// Algorithm 当前签名算法
+func (ks *KeySet) Algorithm() string {
+	return ks.method.Alg()
+}
+
+// Sign 用当前签名密钥签发令牌，非对称算法在 header 中写入 kid
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.method, claims)
+	if ks.kid != "" {
+		token.Header["kid"] = ks.kid
+	}
+	return token.SignedString(ks.signKey)
+}
+
+// Parse 验证并解析令牌：只接受配置的算法，非对称算法按 header 中的 kid 选择公钥
+// 数字 claim 解码为 json.Number（雪花 uid 超出 float64 精度）
+func (ks *KeySet) Parse(tokenString string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if ks.kid == "" {
+			return ks.verify[""], nil
+		}
+		kid, _ := token.Header["kid"].(string)
+		key, ok := ks.verify[kid]
+		if !ok {
+			return nil, ErrUnknownKey
+		}
+		return key, nil
+	}, jwt.WithValidMethods([]string{ks.method.Alg()}), jwt.WithExpirationRequired(), jwt.WithJSONNumber())
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// JWK 单个公钥（RFC 7517）
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Crv string `json:"crv,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS 公钥集合
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS 导出全部验证公钥（当前签名密钥在前）；HS256 不公开任何密钥
+func (ks *KeySet) JWKS() *JWKS {
+	set := &JWKS{Keys: []JWK{}}
+	if ks.kid == "" {
+		return set
+	}
+
+	kids := make([]string, 0, len(ks.verify))
+	for kid := range ks.verify {
+		if kid != ks.kid {
+			kids = append(kids, kid)
+		}
+	}
+	sort.Strings(kids)
+	kids = append([]string{ks.kid}, kids...)
+
+	enc := base64.RawURLEncoding
+	for _, kid := range kids {
+		jwk := JWK{Kid: kid, Use: "sig", Alg: ks.method.Alg()}
+		switch k := ks.verify[kid].(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = enc.EncodeToString(k.N.Bytes())
+			jwk.E = enc.EncodeToString(big.NewInt(int64(k.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = enc.EncodeToString(k)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
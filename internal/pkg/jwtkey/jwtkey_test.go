@@ -0,0 +1,189 @@
+package jwtkey
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// writeKey 将私钥写入 PKCS#8 PEM 文件，同时写出对应的公钥文件
+func writeKey(t *testing.T, dir, name string, priv crypto.Signer) (privFile, pubFile string) {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privFile = filepath.Join(dir, name+".pem")
+	if err := os.WriteFile(privFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	der, err = x509.MarshalPKIXPublicKey(priv.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubFile = filepath.Join(dir, name+".pub.pem")
+	if err := os.WriteFile(pubFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return privFile, pubFile
+}
+
+func newEdKey(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return priv
+}
+
+func claims() jwt.MapClaims {
+	return jwt.MapClaims{"uid": 1, "exp": time.Now().Add(time.Minute).Unix()}
+}
+
+func TestEdDSARotation(t *testing.T) {
+	dir := t.TempDir()
+	oldPriv, oldPub := writeKey(t, dir, "old", newEdKey(t))
+	newPriv, _ := writeKey(t, dir, "new", newEdKey(t))
+
+	before, err := Load(Options{Algorithm: EdDSA, KeyID: "k1", PrivateKey: oldPriv})
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldToken, err := before.Sign(claims())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 轮换：新密钥签名，旧公钥保留验证
+	after, err := Load(Options{Algorithm: EdDSA, KeyID: "k2", PrivateKey: newPriv, PublicKeys: []string{"k1:" + oldPub}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := after.Parse(oldToken); err != nil {
+		t.Fatalf("old token rejected after rotation: %v", err)
+	}
+	newToken, err := after.Sign(claims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, err := after.Parse(newToken); err != nil || c["uid"] != json.Number("1") {
+		t.Fatalf("parse new token: %v %v", c, err)
+	}
+	if _, err := before.Parse(newToken); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("unknown kid: %v", err)
+	}
+
+	set := after.JWKS()
+	if len(set.Keys) != 2 || set.Keys[0].Kid != "k2" || set.Keys[1].Kid != "k1" {
+		t.Fatalf("jwks = %+v", set.Keys)
+	}
+	if k := set.Keys[0]; k.Kty != "OKP" || k.Crv != "Ed25519" || k.Alg != EdDSA || k.X == "" {
+		t.Fatalf("jwk = %+v", k)
+	}
+}
+
+func TestRS256(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privFile, _ := writeKey(t, t.TempDir(), "rsa", priv)
+
+	ks, err := Load(Options{Algorithm: RS256, KeyID: "r1", PrivateKey: privFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := ks.Sign(claims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ks.Parse(token); err != nil {
+		t.Fatal(err)
+	}
+	if k := ks.JWKS().Keys[0]; k.Kty != "RSA" || k.E != "AQAB" || k.N == "" {
+		t.Fatalf("jwk = %+v", k)
+	}
+
+	// 算法固定：以公钥内容作为 HMAC 密钥伪造的 HS256 令牌必须被拒绝
+	pubDER, _ := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, claims())
+	forged.Header["kid"] = "r1"
+	forgedStr, _ := forged.SignedString(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}))
+	if _, err := ks.Parse(forgedStr); err == nil {
+		t.Fatal("HS256 token accepted by RS256 key set")
+	}
+
+	// 算法与密钥类型不符
+	edFile, _ := writeKey(t, t.TempDir(), "ed", newEdKey(t))
+	if _, err := Load(Options{Algorithm: RS256, KeyID: "r1", PrivateKey: edFile}); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("mismatched key type: %v", err)
+	}
+}
+
+func TestHS256(t *testing.T) {
+	ks, err := Load(Options{Secret: "s3cret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := ks.Sign(claims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ks.Parse(token); err != nil {
+		t.Fatal(err)
+	}
+	if len(ks.JWKS().Keys) != 0 {
+		t.Fatal("HS256 secret must not be published")
+	}
+
+	other, _ := Load(Options{Secret: "other"})
+	if _, err := other.Parse(token); err == nil {
+		t.Fatal("token verified with wrong secret")
+	}
+
+	noExp, _ := ks.Sign(jwt.MapClaims{"uid": 1})
+	if _, err := ks.Parse(noExp); err == nil {
+		t.Fatal("token without exp accepted")
+	}
+}
+
+// TestParseSnowflakeUID 19 位雪花 uid 解析为 json.Number，不经 float64 丢精度
+func TestParseSnowflakeUID(t *testing.T) {
+	const uid int64 = 1981234567890123457
+	ks, err := Load(Options{Secret: "s3cret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := ks.Sign(jwt.MapClaims{"uid": uid, "exp": time.Now().Add(time.Minute).Unix()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := ks.Parse(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, ok := parsed["uid"].(json.Number)
+	if !ok {
+		t.Fatalf("uid decoded as %T, want json.Number", parsed["uid"])
+	}
+	if got, err := n.Int64(); err != nil || got != uid {
+		t.Fatalf("uid = %v (%v), want %d", n, err, uid)
+	}
+	if _, err := parsed.GetExpirationTime(); err != nil {
+		t.Fatalf("exp: %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+// Package pinyin 汉字转拼音（内嵌字典，无外部依赖）
+//
+// 字典见 pinyin.txt，不带声调，多音字取常用读音；用于生成 URL slug，
+// 不追求按词语消歧。
+package pinyin
+
+import (
+	_ "embed"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+//go:embed pinyin.txt
+var dictData string
+
+var (
+	dictOnce sync.Once
+	dict     map[rune]string
+)
+
+// load 解析内嵌字典（首次使用时）
+func load() {
+	dict = make(map[rune]string, 27000)
+	for _, line := range strings.Split(dictData, "\n") {
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		fields := strings.Fields(line)
+		start, err := strconv.ParseInt(fields[0], 16, 32)
+		if err != nil {
+			continue
+		}
+		for i, syl := range fields[1:] {
+			if syl != "-" {
+				dict[rune(start)+rune(i)] = syl
+			}
+		}
+	}
+}
+
+// Lookup 单个汉字的拼音，不在字典中时返回 false
+func Lookup(r rune) (string, bool) {
+	dictOnce.Do(load)
+	s, ok := dict[r]
+	return s, ok
+}
+
+// Slug 生成 URL slug：汉字转拼音、字母数字转小写，各音节/单词以 "-" 连接
+// 其它字符视为分隔符；结果超过 maxLen（>0）时在音节/单词边界截断
+//
+//	Slug("Go 语言", 60)  → "go-yu-yan"
+//	Slug("C++ 入门", 60) → "c-ru-men"
+func Slug(s string, maxLen int) string {
+	var b strings.Builder
+	for _, part := range Split(s) {
+		n := len(part)
+		if b.Len() > 0 {
+			n++
+		}
+		if maxLen > 0 && b.Len()+n > maxLen {
+			break
+		}
+		if b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		b.WriteString(part)
+	}
+	return b.String()
+}
+
+// Split 切分为拼音音节与小写 ASCII 单词，丢弃其它字符
+func Split(s string) []string {
+	var parts []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			parts = append(parts, word.String())
+			word.Reset()
+		}
+	}
+
+	for _, r := range s {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			word.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		flush()
+		if py, ok := Lookup(r); ok {
+			parts = append(parts, py)
+		}
+	}
+	flush()
+	return parts
+}
@@ -0,0 +1,35 @@
+package pinyin
+
+import "testing"
+
+func TestLookup(t *testing.T) {
+	for r, want := range map[rune]string{'中': "zhong", '文': "wen", '语': "yu", '㐀': "qiu"} {
+		if got, ok := Lookup(r); !ok || got != want {
+			t.Fatalf("Lookup(%q) = %q, %v; want %q", r, got, ok, want)
+		}
+	}
+	if _, ok := Lookup('a'); ok {
+		t.Fatal("Lookup('a') should miss")
+	}
+}
+
+func TestSlug(t *testing.T) {
+	cases := []struct {
+		in   string
+		max  int
+		want string
+	}{
+		{"Go 语言", 60, "go-yu-yan"},
+		{"C++ 入门", 60, "c-ru-men"},
+		{"  Hello, World!  ", 60, "hello-world"},
+		{"中文Go2", 60, "zhong-wen-go2"},
+		{"★☆", 60, ""},
+		{"中华人民共和国", 12, "zhong-hua"},
+		{"abcdef", 3, ""},
+	}
+	for _, c := range cases {
+		if got := Slug(c.in, c.max); got != c.want {
+			t.Errorf("Slug(%q, %d) = %q, want %q", c.in, c.max, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package pool
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 跨实例 L1 失效广播
+// 设计原则：
+// 1. 所有 BigCache 实例创建时自动登记，失效时统一清理本进程内所有 L1
+// 2. 本地清理后通过 Redis Pub/Sub 广播，其它实例收到后清理各自 L1
+// 3. 消息携带节点 ID，节点忽略自己发出的消息
+// 4. Bus 未初始化或 Redis 不可用时退化为仅清理本地，不影响主流程
+// 5. 进程内常驻数据（如 runtime 的版块/标签列表）不在 L1 中，通过 Notify/OnEvent 广播具名事件重新加载
+
+var (
+	registryMu sync.RWMutex
+	registry   []*BigCache
+	handlers   = make(map[string][]func(context.Context))
+	bus        *Bus
+)
+
+// Bus L1 失效广播总线
+type Bus struct {
+	client  *redis.Client
+	channel string
+	node    string
+}
+
+// invalidateEvent 失效事件（Prefix 为 true 时 Key 视为前缀，空前缀表示全部）
+// Event 非空时为具名事件，交给 OnEvent 登记的处理函数，不清理 L1
+type invalidateEvent struct {
+	Node   string `json:"n"`
+	Key    string `json:"k"`
+	Prefix bool   `json:"p,omitempty"`
+	Event  string `json:"e,omitempty"`
+}
+
+// InitBus 初始化失效广播总线
+// node: 本实例唯一 ID，用于忽略自身消息
+func InitBus(client *redis.Client, channel, node string) *Bus {
+	bus = &Bus{
+		client:  client,
+		channel: channel,
+		node:    node,
+	}
+	return bus
+}
+
+// Node 返回本实例节点 ID
+func (b *Bus) Node() string {
+	return b.node
+}
+
+// Run 订阅失效频道并应用到本地 L1，阻塞直到 ctx 结束
+// 断线重连由 go-redis PubSub 自动处理
+func (b *Bus) Run(ctx context.Context, onError func(error)) {
+	sub := b.client.Subscribe(ctx, b.channel)
+	defer sub.Close()
+
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			var ev invalidateEvent
+			if err := json.Unmarshal([]byte(msg.Payload), &ev); err != nil {
+				if onError != nil {
+					onError(err)
+				}
+				continue
+			}
+			if ev.Node == b.node {
+				continue
+			}
+			if ev.Event != "" {
+				dispatch(ctx, ev.Event)
+				continue
+			}
+			applyLocal(ev.Key, ev.Prefix)
+		}
+	}
+}
+
+func (b *Bus) publish(ctx context.Context, ev invalidateEvent) {
+	ev.Node = b.node
+	data, _ := json.Marshal(ev)
+	b.client.Publish(ctx, b.channel, data)
+}
+
+// register 登记 BigCache 实例
+func register(c *BigCache) {
+	registryMu.Lock()
+	registry = append(registry, c)
+	registryMu.Unlock()
+}
+
+// applyLocal 清理本进程内所有 L1
+func applyLocal(key string, prefix bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	for _, c := range registry {
+		if prefix {
+			c.RemovePrefix(key)
+		} else {
+			c.Remove(key)
+		}
+	}
+}
+
+// Invalidate 清理所有实例 L1 中的指定 key
+func Invalidate(ctx context.Context, key string) {
+	applyLocal(key, false)
+	if bus != nil {
+		bus.publish(ctx, invalidateEvent{Key: key})
+	}
+}
+
+// InvalidatePrefix 清理所有实例 L1 中指定前缀的 key（空前缀表示全部）
+func InvalidatePrefix(ctx context.Context, prefix string) {
+	applyLocal(prefix, true)
+	if bus != nil {
+		bus.publish(ctx, invalidateEvent{Key: prefix, Prefix: true})
+	}
+}
+
+// OnEvent 登记具名事件的处理函数，其它实例 Notify 该事件时调用
+func OnEvent(event string, fn func(ctx context.Context)) {
+	registryMu.Lock()
+	handlers[event] = append(handlers[event], fn)
+	registryMu.Unlock()
+}
+
+// Notify 通知其它实例具名事件（本实例不触发处理函数，调用方自行处理本地）
+func Notify(ctx context.Context, event string) {
+	if bus != nil {
+		bus.publish(ctx, invalidateEvent{Event: event})
+	}
+}
+
+// dispatch 异步执行事件处理函数，避免重新加载阻塞失效消息的消费
+func dispatch(ctx context.Context, event string) {
+	registryMu.RLock()
+	fns := handlers[event]
+	registryMu.RUnlock()
+
+	for _, fn := range fns {
+		go fn(ctx)
+	}
+}
+
+// RemovePrefix 删除指定前缀的 key（空前缀直接清空）
+func (c *BigCache) RemovePrefix(prefix string) error {
+	if prefix == "" {
+		return c.cache.Reset()
+	}
+
+	var keys []string
+	it := c.cache.Iterator()
+	for it.SetNext() {
+		entry, err := it.Value()
+		if err != nil {
+			continue
+		}
+		if strings.HasPrefix(entry.Key(), prefix) {
+			keys = append(keys, entry.Key())
+		}
+	}
+	for _, key := range keys {
+		c.cache.Delete(key)
+	}
+	return nil
+}
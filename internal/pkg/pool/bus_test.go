@@ -0,0 +1,132 @@
+package pool
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+const testBusChannel = "test:l1:invalidate"
+
+func newTestBus(t *testing.T, node string) (*Bus, *miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() {
+		bus = nil
+		client.Close()
+	})
+	return InitBus(client, testBusChannel, node), mr, client
+}
+
+// waitFor 轮询直到 cond 成立（订阅与消息消费均为异步）
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestBusPublish(t *testing.T) {
+	ctx := context.Background()
+	_, _, client := newTestBus(t, "a")
+	sub := client.Subscribe(ctx, testBusChannel)
+	defer sub.Close()
+	if _, err := sub.Receive(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	Invalidate(ctx, "thread:1")
+	InvalidatePrefix(ctx, "thread:list:")
+	Notify(ctx, "runtime:forums")
+
+	want := []invalidateEvent{
+		{Node: "a", Key: "thread:1"},
+		{Node: "a", Key: "thread:list:", Prefix: true},
+		{Node: "a", Event: "runtime:forums"},
+	}
+	for _, w := range want {
+		msg, err := sub.ReceiveMessage(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ev invalidateEvent
+		if err := json.Unmarshal([]byte(msg.Payload), &ev); err != nil {
+			t.Fatal(err)
+		}
+		if ev != w {
+			t.Fatalf("event = %+v, want %+v", ev, w)
+		}
+	}
+}
+
+func TestBusApplyRemote(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	b, mr, client := newTestBus(t, "a")
+
+	cache, _ := NewBigCache(8, time.Minute)
+	defer cache.Close()
+	for _, key := range []string{"bus:own", "bus:key", "bus:list:1", "bus:list:2", "bus:other"} {
+		_ = cache.Set(key, []byte("v"))
+	}
+	reloaded := make(chan struct{}, 1)
+	OnEvent("test:reload", func(ctx context.Context) { reloaded <- struct{}{} })
+
+	errs := make(chan error, 1)
+	go b.Run(ctx, func(err error) { errs <- err })
+	waitFor(t, "subscription", func() bool { return mr.PubSubNumSub(testBusChannel)[testBusChannel] == 1 })
+
+	send := func(ev invalidateEvent) {
+		data, _ := json.Marshal(ev)
+		if err := client.Publish(ctx, testBusChannel, data).Err(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	exists := func(key string) bool {
+		_, ok := cache.Get(key)
+		return ok
+	}
+
+	// 自身消息被忽略；消息按序消费，后一条生效时前一条已处理
+	send(invalidateEvent{Node: "a", Key: "bus:own"})
+	send(invalidateEvent{Node: "b", Key: "bus:key"})
+	waitFor(t, "remote key invalidation", func() bool { return !exists("bus:key") })
+	if !exists("bus:own") {
+		t.Fatal("own message should be ignored")
+	}
+
+	send(invalidateEvent{Node: "b", Key: "bus:list:", Prefix: true})
+	waitFor(t, "remote prefix invalidation", func() bool { return !exists("bus:list:1") && !exists("bus:list:2") })
+	if !exists("bus:other") {
+		t.Fatal("keys outside prefix should be kept")
+	}
+
+	if err := client.Publish(ctx, testBusChannel, "not json").Err(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-errs:
+	case <-time.After(2 * time.Second):
+		t.Fatal("invalid payload should be reported")
+	}
+
+	// 具名事件只交给处理函数，不清理 L1
+	send(invalidateEvent{Node: "b", Key: "bus:other", Event: "test:reload"})
+	select {
+	case <-reloaded:
+	case <-time.After(2 * time.Second):
+		t.Fatal("event handler not called")
+	}
+	if !exists("bus:other") {
+		t.Fatal("named event should not invalidate L1")
+	}
+}
@@ -0,0 +1,49 @@
+package pool
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Generation 列表代际计数器（存 Redis，所有实例共享）
+// 写操作 Bump 使代际 +1，读操作把代际拼进缓存 key，旧 key 随 TTL 自然过期，无需扫描删除
+type Generation struct {
+	l2     *redis.Client
+	prefix string
+}
+
+// NewGeneration 创建代际计数器
+// prefix: Redis key 前缀，如 "post:list:gen"
+func NewGeneration(l2 *redis.Client, prefix string) *Generation {
+	return &Generation{l2: l2, prefix: prefix}
+}
+
+func (g *Generation) key(id string) string {
+	return g.prefix + ":" + id
+}
+
+// Get 获取当前代际（Redis 不可用时返回 0，不影响主流程）
+func (g *Generation) Get(ctx context.Context, id string) int64 {
+	v, err := g.l2.Get(ctx, g.key(id)).Int64()
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// Bump 代际 +1，使该范围内所有已缓存列表失效
+func (g *Generation) Bump(ctx context.Context, id string) error {
+	return g.l2.Incr(ctx, g.key(id)).Err()
+}
+
+// GetInt64 按数值 ID 获取代际
+func (g *Generation) GetInt64(ctx context.Context, id int64) int64 {
+	return g.Get(ctx, strconv.FormatInt(id, 10))
+}
+
+// BumpInt64 按数值 ID 递增代际
+func (g *Generation) BumpInt64(ctx context.Context, id int64) error {
+	return g.Bump(ctx, strconv.FormatInt(id, 10))
+}
@@ -0,0 +1,60 @@
+package pool
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PromMetrics 基于 Prometheus 的缓存指标
+// 指标：
+//   - well_cache_requests_total{cache, result}  result: l1 / l2 / miss
+//   - well_cache_load_seconds{cache, status}     status: ok / error
+type PromMetrics struct {
+	requests *prometheus.CounterVec
+	loads    *prometheus.HistogramVec
+}
+
+var (
+	promOnce    sync.Once
+	promMetrics *PromMetrics
+)
+
+// NewPromMetrics 返回进程内共享的 Prometheus 指标（重复调用不会重复注册）
+func NewPromMetrics() *PromMetrics {
+	promOnce.Do(func() {
+		promMetrics = &PromMetrics{
+			requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Name: "well_cache_requests_total",
+				Help: "Tiered cache lookups by result.",
+			}, []string{"cache", "result"}),
+			loads: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+				Name:    "well_cache_load_seconds",
+				Help:    "Tiered cache loader latency.",
+				Buckets: prometheus.DefBuckets,
+			}, []string{"cache", "status"}),
+		}
+		prometheus.MustRegister(promMetrics.requests, promMetrics.loads)
+	})
+	return promMetrics
+}
+
+// Hit 记录命中
+func (m *PromMetrics) Hit(cache, level string) {
+	m.requests.WithLabelValues(cache, level).Inc()
+}
+
+// Miss 记录未命中
+func (m *PromMetrics) Miss(cache string) {
+	m.requests.WithLabelValues(cache, "miss").Inc()
+}
+
+// Load 记录回源耗时
+func (m *PromMetrics) Load(cache string, d time.Duration, err error) {
+	status := "ok"
+	if err != nil {
+		status = "error"
+	}
+	m.loads.WithLabelValues(cache, status).Observe(d.Seconds())
+}
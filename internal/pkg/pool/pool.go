@@ -36,7 +36,9 @@ func NewBigCache(capacityMB int, expiration time.Duration) (*BigCache, error) {
 		return nil, err
 	}
 
-	return &BigCache{cache: cache}, nil
+	c := &BigCache{cache: cache}
+	register(c)
+	return c, nil
 }
 
 // Get 直接返回[]byte，由上层反序列化
@@ -1,10 +1,15 @@
 package pool
 
 import (
+	"context"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
 	"testing"
 	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
 )
 
 // ThreadDTO 测试用DTO
@@ -98,3 +103,101 @@ func BenchmarkSimpleCache_Get(b *testing.B) {
 }
 
 func formatKey(i int) string { return fmt.Sprintf("thread:%d", i) }
+
+func TestInvalidateLocal(t *testing.T) {
+	a, _ := NewBigCache(8, time.Minute)
+	b, _ := NewBigCache(8, time.Minute)
+	defer a.Close()
+	defer b.Close()
+
+	_ = a.Set("thread:1", []byte("1"))
+	_ = a.Set("thread:list:1:0:1:20", []byte("l"))
+	_ = b.Set("thread:tags:1", []byte("t"))
+	_ = b.Set("forum:1", []byte("f"))
+
+	// Bus 未初始化时仅清理本地
+	Invalidate(context.Background(), "thread:1")
+	if _, ok := a.Get("thread:1"); ok {
+		t.Fatal("thread:1 should be removed")
+	}
+
+	InvalidatePrefix(context.Background(), "thread:")
+	if _, ok := a.Get("thread:list:1:0:1:20"); ok {
+		t.Fatal("thread:list should be removed by prefix")
+	}
+	if _, ok := b.Get("thread:tags:1"); ok {
+		t.Fatal("thread:tags should be removed across registered caches")
+	}
+	if _, ok := b.Get("forum:1"); !ok {
+		t.Fatal("forum:1 should be kept")
+	}
+}
+
+func TestTieredCacheL1Envelope(t *testing.T) {
+	l1, _ := NewBigCache(8, time.Minute)
+	defer l1.Close()
+
+	c := NewTieredCache(TieredOptions[ThreadDTO]{L1: l1, TTL: time.Minute, Jitter: 0.2})
+
+	data, _ := json.Marshal(&ThreadDTO{TID: 1, Subject: "hello"})
+	_ = l1.Set("thread:1", encodeL1(flagValue, time.Minute, data))
+	if v, _, ok := c.getL1("thread:1"); !ok || v.Subject != "hello" {
+		t.Fatalf("value hit expected, got %+v", v)
+	}
+
+	_ = l1.Set("thread:2", encodeL1(flagNegative, time.Minute, nil))
+	if v, found, ok := c.getL1("thread:2"); !found || ok || v != nil {
+		t.Fatal("negative hit expected")
+	}
+
+	// 单 key TTL 到期后按未命中处理
+	_ = l1.Set("thread:3", encodeL1(flagValue, -time.Second, data))
+	if _, found, _ := c.getL1("thread:3"); found {
+		t.Fatal("expired entry should miss")
+	}
+
+	for i := 0; i < 100; i++ {
+		ttl := c.withJitter(time.Minute)
+		if ttl < 48*time.Second || ttl > 72*time.Second {
+			t.Fatalf("jitter out of range: %v", ttl)
+		}
+	}
+}
+
+// TestTieredCacheL1FollowsL2TTL L2 命中回写 L1 时使用 key 剩余寿命，不超过默认 TTL
+func TestTieredCacheL1FollowsL2TTL(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	l2 := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer l2.Close()
+	l1, _ := NewBigCache(8, time.Minute)
+	defer l1.Close()
+
+	c := NewTieredCache(TieredOptions[ThreadDTO]{L1: l1, L2: l2, TTL: time.Minute, NegativeTTL: time.Minute})
+	data, _ := json.Marshal(&ThreadDTO{TID: 1})
+	l2.Set(ctx, "thread:1", encodeL2(flagValue, data), 5*time.Second)
+	l2.Set(ctx, "thread:2", encodeL2(flagValue, data), time.Hour)
+	l2.Set(ctx, "thread:3", encodeL2(flagNegative, nil), 5*time.Second)
+	l2.Set(ctx, "thread:4", encodeL2(flagValue, data), 0)
+
+	c.GetMulti(ctx, []string{"thread:1", "thread:2"})
+	c.getL2(ctx, "thread:3")
+	c.getL2(ctx, "thread:4")
+
+	now := time.Now()
+	for key, want := range map[string]time.Duration{
+		"thread:1": 5 * time.Second,
+		"thread:2": time.Minute,
+		"thread:3": 5 * time.Second,
+		"thread:4": time.Minute,
+	} {
+		raw, ok := l1.Get(key)
+		if !ok {
+			t.Fatalf("%s not written to L1", key)
+		}
+		ttl := time.Duration(int64(binary.BigEndian.Uint64(raw[1:l1HeaderLen])) - now.UnixNano())
+		if ttl > want || ttl < want-time.Second {
+			t.Errorf("%s L1 ttl = %v, want %v", key, ttl, want)
+		}
+	}
+}
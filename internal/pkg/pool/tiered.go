@@ -0,0 +1,359 @@
+package pool
+
+import (
+	"context"
+	"encoding"
+	"encoding/binary"
+	"encoding/json"
+	"math/rand"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+// TieredCache 二级缓存组件
+// 统一 L1(bigcache) → L2(redis) → singleflight → DB 加载 → 回写 → 失效 的执行路径，
+// 避免各 Service 复制粘贴后行为漂移。
+//
+// 存储格式：
+//   - L1: [flag 1B][expireAt 8B][payload]，expireAt 实现单 key TTL（bigcache 只有全局过期）
+//   - L2: [magic 1B][flag 1B][payload]，magic 不匹配（旧格式/脏数据）按未命中处理
+//
+// flag 为 flagNegative 时表示负缓存（DB 中不存在），payload 为空。
+type TieredCache[T any] struct {
+	name        string
+	l1          *BigCache
+	l2          *redis.Client
+	l1Codec     Codec[T]
+	l2Codec     Codec[T]
+	ttl         time.Duration
+	negativeTTL time.Duration
+	jitter      float64
+	metrics     Metrics
+	sf          singleflight.Group
+}
+
+// TieredOptions TieredCache 配置
+type TieredOptions[T any] struct {
+	Name        string        // 缓存名（用于指标）
+	L1          *BigCache     // 可为 nil（仅 L2）
+	L2          *redis.Client // 必填
+	L1Codec     Codec[T]      // 默认 JSON
+	L2Codec     Codec[T]      // 默认同 L1Codec
+	TTL         time.Duration // 默认 TTL
+	NegativeTTL time.Duration // 负缓存 TTL，0 表示关闭
+	Jitter      float64       // TTL 随机抖动比例（0~1），防止同时过期
+	Metrics     Metrics       // 可为 nil
+}
+
+// Loader 回源加载函数，返回 (nil, nil) 表示不存在
+type Loader[T any] func(ctx context.Context) (*T, error)
+
+// Codec 序列化编解码器
+type Codec[T any] interface {
+	Marshal(v *T) ([]byte, error)
+	Unmarshal(data []byte, v *T) error
+}
+
+// JSONCodec JSON 编解码
+type JSONCodec[T any] struct{}
+
+func (JSONCodec[T]) Marshal(v *T) ([]byte, error)      { return json.Marshal(v) }
+func (JSONCodec[T]) Unmarshal(data []byte, v *T) error { return json.Unmarshal(data, v) }
+
+// binaryPtr 约束：*T 实现 BinaryMarshaler/BinaryUnmarshaler
+type binaryPtr[T any] interface {
+	*T
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+// BinaryCodec 使用 DTO 自身的 MarshalBinary/UnmarshalBinary
+// 用法：pool.BinaryCodec[ThreadDTO, *ThreadDTO]{}
+type BinaryCodec[T any, PT binaryPtr[T]] struct{}
+
+func (BinaryCodec[T, PT]) Marshal(v *T) ([]byte, error)      { return PT(v).MarshalBinary() }
+func (BinaryCodec[T, PT]) Unmarshal(data []byte, v *T) error { return PT(v).UnmarshalBinary(data) }
+
+// Metrics 缓存指标回调
+type Metrics interface {
+	Hit(cache, level string) // level: l1 / l2
+	Miss(cache string)
+	Load(cache string, d time.Duration, err error)
+}
+
+const (
+	l2Magic      byte = 0xA7
+	flagValue    byte = 0
+	flagNegative byte = 1
+	l1HeaderLen       = 9
+	l2HeaderLen       = 2
+)
+
+// NewTieredCache 创建二级缓存
+func NewTieredCache[T any](opts TieredOptions[T]) *TieredCache[T] {
+	c := &TieredCache[T]{
+		name:        opts.Name,
+		l1:          opts.L1,
+		l2:          opts.L2,
+		l1Codec:     opts.L1Codec,
+		l2Codec:     opts.L2Codec,
+		ttl:         opts.TTL,
+		negativeTTL: opts.NegativeTTL,
+		jitter:      opts.Jitter,
+		metrics:     opts.Metrics,
+	}
+	if c.l1Codec == nil {
+		c.l1Codec = JSONCodec[T]{}
+	}
+	if c.l2Codec == nil {
+		c.l2Codec = c.l1Codec
+	}
+	return c
+}
+
+// Get 按默认 TTL 读取，未命中时回源
+func (c *TieredCache[T]) Get(ctx context.Context, key string, load Loader[T]) (*T, error) {
+	return c.GetWithTTL(ctx, key, c.ttl, load)
+}
+
+// GetWithTTL 按指定 TTL 读取，未命中时回源（singleflight 合并并发回源）
+func (c *TieredCache[T]) GetWithTTL(ctx context.Context, key string, ttl time.Duration, load Loader[T]) (*T, error) {
+	if v, found, ok := c.getL1(key); ok {
+		c.hit("l1")
+		return v, nil
+	} else if found {
+		// L1 负缓存命中
+		c.hit("l1")
+		return nil, nil
+	}
+
+	if v, found, ok := c.getL2(ctx, key); ok || found {
+		c.hit("l2")
+		return v, nil
+	}
+
+	if c.metrics != nil {
+		c.metrics.Miss(c.name)
+	}
+
+	v, err, _ := c.sf.Do(key, func() (interface{}, error) {
+		start := time.Now()
+		v, err := load(ctx)
+		if c.metrics != nil {
+			c.metrics.Load(c.name, time.Since(start), err)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if v == nil {
+			c.setNegative(ctx, key)
+			return nil, nil
+		}
+		c.Set(ctx, key, v, ttl)
+		return v, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, nil
+	}
+	return v.(*T), nil
+}
+
+// GetMulti 批量读取（L1 → Redis MGET），只返回命中的 key，不回源
+// 负缓存命中的 key 以 nil 值返回，调用方可据此跳过 DB
+func (c *TieredCache[T]) GetMulti(ctx context.Context, keys []string) map[string]*T {
+	result := make(map[string]*T, len(keys))
+	missing := make([]string, 0, len(keys))
+
+	for _, key := range keys {
+		if v, found, ok := c.getL1(key); ok || found {
+			c.hit("l1")
+			result[key] = v
+			continue
+		}
+		missing = append(missing, key)
+	}
+	if len(missing) == 0 {
+		return result
+	}
+
+	pipe := c.l2.Pipeline()
+	gets := make([]*redis.StringCmd, len(missing))
+	ttls := make([]*redis.DurationCmd, len(missing))
+	for i, key := range missing {
+		gets[i] = pipe.Get(ctx, key)
+		ttls[i] = pipe.PTTL(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return result
+	}
+	for i, key := range missing {
+		data, err := gets[i].Bytes()
+		if err != nil {
+			continue
+		}
+		if v, found, ok := c.decodeL2(key, data, ttls[i].Val()); ok || found {
+			c.hit("l2")
+			result[key] = v
+		}
+	}
+	return result
+}
+
+// Set 写入 L1 + L2，ttl 为 0 时使用默认 TTL
+func (c *TieredCache[T]) Set(ctx context.Context, key string, v *T, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = c.ttl
+	}
+	ttl = c.withJitter(ttl)
+
+	if c.l1 != nil {
+		if data, err := c.l1Codec.Marshal(v); err == nil {
+			c.l1.Set(key, encodeL1(flagValue, ttl, data))
+		}
+	}
+	if data, err := c.l2Codec.Marshal(v); err == nil {
+		c.l2.Set(ctx, key, encodeL2(flagValue, data), ttl)
+	}
+}
+
+// Delete 失效 key：所有实例 L1 + L2
+func (c *TieredCache[T]) Delete(ctx context.Context, keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+	for _, key := range keys {
+		Invalidate(ctx, key)
+	}
+	c.l2.Del(ctx, keys...)
+}
+
+func (c *TieredCache[T]) setNegative(ctx context.Context, key string) {
+	if c.negativeTTL <= 0 {
+		return
+	}
+	ttl := c.withJitter(c.negativeTTL)
+	if c.l1 != nil {
+		c.l1.Set(key, encodeL1(flagNegative, ttl, nil))
+	}
+	c.l2.Set(ctx, key, encodeL2(flagNegative, nil), ttl)
+}
+
+// getL1 返回 (值, 是否命中负缓存或值, 是否命中值)
+func (c *TieredCache[T]) getL1(key string) (*T, bool, bool) {
+	if c.l1 == nil {
+		return nil, false, false
+	}
+	data, ok := c.l1.Get(key)
+	if !ok || len(data) < l1HeaderLen {
+		return nil, false, false
+	}
+	expireAt := int64(binary.BigEndian.Uint64(data[1:l1HeaderLen]))
+	if time.Now().UnixNano() >= expireAt {
+		c.l1.Remove(key)
+		return nil, false, false
+	}
+	if data[0] == flagNegative {
+		return nil, true, false
+	}
+	var v T
+	if err := c.l1Codec.Unmarshal(data[l1HeaderLen:], &v); err != nil {
+		c.l1.Remove(key)
+		return nil, false, false
+	}
+	return &v, true, true
+}
+
+// getL2 读取 L2（连同剩余寿命），命中时回写 L1
+func (c *TieredCache[T]) getL2(ctx context.Context, key string) (*T, bool, bool) {
+	pipe := c.l2.Pipeline()
+	get := pipe.Get(ctx, key)
+	pttl := pipe.PTTL(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, false, false
+	}
+	data, err := get.Bytes()
+	if err != nil {
+		return nil, false, false
+	}
+	v, found, ok := c.decodeL2(key, data, pttl.Val())
+	if !found {
+		// 旧格式或损坏的数据：删除后按未命中回源
+		c.l2.Del(ctx, key)
+	}
+	return v, found, ok
+}
+
+// decodeL2 解码 L2 数据并回写 L1；remain 为 L2 剩余寿命（PTTL）
+func (c *TieredCache[T]) decodeL2(key string, data []byte, remain time.Duration) (*T, bool, bool) {
+	if len(data) < l2HeaderLen || data[0] != l2Magic {
+		return nil, false, false
+	}
+	if data[1] == flagNegative {
+		if ttl := l1TTL(remain, c.negativeTTL); c.l1 != nil && ttl > 0 {
+			c.l1.Set(key, encodeL1(flagNegative, ttl, nil))
+		}
+		return nil, true, false
+	}
+	var v T
+	if err := c.l2Codec.Unmarshal(data[l2HeaderLen:], &v); err != nil {
+		return nil, false, false
+	}
+	if ttl := l1TTL(remain, c.withJitter(c.ttl)); c.l1 != nil && ttl > 0 {
+		if b, err := c.l1Codec.Marshal(&v); err == nil {
+			c.l1.Set(key, encodeL1(flagValue, ttl, b))
+		}
+	}
+	return &v, true, true
+}
+
+// l1TTL L1 回写 TTL：跟随 L2 剩余寿命（按短 TTL 写入的 key 不会在 L1 中存活更久），
+// 不超过 limit；L2 无过期时间时取 limit，key 已不存在时不回写
+func l1TTL(remain, limit time.Duration) time.Duration {
+	switch {
+	case remain == -1:
+		return limit
+	case remain < 0:
+		return 0
+	case remain < limit:
+		return remain
+	}
+	return limit
+}
+
+func (c *TieredCache[T]) withJitter(ttl time.Duration) time.Duration {
+	if c.jitter <= 0 || ttl <= 0 {
+		return ttl
+	}
+	delta := time.Duration(float64(ttl) * c.jitter * (rand.Float64()*2 - 1))
+	if ttl+delta <= 0 {
+		return ttl
+	}
+	return ttl + delta
+}
+
+func (c *TieredCache[T]) hit(level string) {
+	if c.metrics != nil {
+		c.metrics.Hit(c.name, level)
+	}
+}
+
+func encodeL1(flag byte, ttl time.Duration, payload []byte) []byte {
+	buf := make([]byte, l1HeaderLen+len(payload))
+	buf[0] = flag
+	binary.BigEndian.PutUint64(buf[1:l1HeaderLen], uint64(time.Now().Add(ttl).UnixNano()))
+	copy(buf[l1HeaderLen:], payload)
+	return buf
+}
+
+func encodeL2(flag byte, payload []byte) []byte {
+	buf := make([]byte, l2HeaderLen+len(payload))
+	buf[0] = l2Magic
+	buf[1] = flag
+	copy(buf[l2HeaderLen:], payload)
+	return buf
+}
@@ -71,6 +71,14 @@ func Unauthorized(c *gin.Context, msg string) {
 	})
 }
 
+// Forbidden Forbidden response
+func Forbidden(c *gin.Context, msg string) {
+	c.JSON(http.StatusForbidden, Response{
+		Code: apperr.CodeForbidden,
+		Msg:  msg,
+	})
+}
+
 // NotFound Not found response
 func NotFound(c *gin.Context, msg string) {
 	c.JSON(http.StatusNotFound, Response{
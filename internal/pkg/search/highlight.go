@@ -0,0 +1,118 @@
+package search
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	highlightOpen  = "<em>"
+	highlightClose = "</em>"
+	ellipsis       = "…"
+)
+
+// Highlight 在文本中标记查询词（HTML 转义后以 <em> 包裹）
+// maxRunes > 0 时截取首个命中附近的片段作为摘要
+func Highlight(text string, terms []string, maxRunes int) string {
+	runes := []rune(text)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	spans := matchSpans(lower, terms)
+
+	start, end := 0, len(runes)
+	if maxRunes > 0 && len(runes) > maxRunes {
+		if len(spans) > 0 {
+			start = spans[0][0] - maxRunes/4
+			if start < 0 {
+				start = 0
+			}
+		}
+		end = start + maxRunes
+		if end > len(runes) {
+			end = len(runes)
+			start = end - maxRunes
+		}
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString(ellipsis)
+	}
+	pos := start
+	for _, sp := range spans {
+		s, e := sp[0], sp[1]
+		if e <= start || s >= end {
+			continue
+		}
+		if s < start {
+			s = start
+		}
+		if e > end {
+			e = end
+		}
+		b.WriteString(html.EscapeString(string(runes[pos:s])))
+		b.WriteString(highlightOpen)
+		b.WriteString(html.EscapeString(string(runes[s:e])))
+		b.WriteString(highlightClose)
+		pos = e
+	}
+	b.WriteString(html.EscapeString(string(runes[pos:end])))
+	if end < len(runes) {
+		b.WriteString(ellipsis)
+	}
+	return b.String()
+}
+
+// matchSpans 查找命中区间并合并重叠（rune 下标，左闭右开）
+func matchSpans(lower []rune, terms []string) [][2]int {
+	var spans [][2]int
+	for _, t := range terms {
+		tr := []rune(t)
+		if len(tr) == 0 || len(tr) > len(lower) {
+			continue
+		}
+		word := !isCJK(tr[0])
+		for i := 0; i+len(tr) <= len(lower); i++ {
+			if !equalRunes(lower[i:i+len(tr)], tr) {
+				continue
+			}
+			// 拉丁单词要求词边界，避免 go 命中 google
+			if word && ((i > 0 && isWord(lower[i-1]) && !isCJK(lower[i-1])) ||
+				(i+len(tr) < len(lower) && isWord(lower[i+len(tr)]) && !isCJK(lower[i+len(tr)]))) {
+				continue
+			}
+			spans = append(spans, [2]int{i, i + len(tr)})
+		}
+	}
+	if len(spans) == 0 {
+		return nil
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+	merged := spans[:1]
+	for _, sp := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if sp[0] <= last[1] {
+			if sp[1] > last[1] {
+				last[1] = sp[1]
+			}
+			continue
+		}
+		merged = append(merged, sp)
+	}
+	return merged
+}
+
+func equalRunes(a, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
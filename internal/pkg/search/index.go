@@ -0,0 +1,181 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// subjectBoost 标题命中权重
+const subjectBoost = 3
+
+// Document 待索引文档
+type Document struct {
+	ID      int64
+	Fid     int
+	Subject string
+	Body    string
+	Time    int64 // 同分时按时间倒序
+}
+
+// Hit 命中结果
+type Hit struct {
+	ID    int64
+	Fid   int
+	Score float64
+}
+
+// Query 查询条件
+type Query struct {
+	Terms  []string                     // QueryTerms 结果，全部命中（AND）
+	Filter func(id int64, fid int) bool // 可选过滤（版块/标签/权限）
+	Offset int
+	Limit  int
+}
+
+// Index 倒排索引（并发安全）
+type Index struct {
+	mu       sync.RWMutex
+	postings map[string]map[int64]float32 // term -> doc -> 词频权重
+	docs     map[int64]*docMeta
+}
+
+type docMeta struct {
+	fid   int
+	time  int64
+	terms []string
+}
+
+// NewIndex 创建空索引
+func NewIndex() *Index {
+	return &Index{
+		postings: make(map[string]map[int64]float32),
+		docs:     make(map[int64]*docMeta),
+	}
+}
+
+// Add 添加或替换文档
+func (x *Index) Add(doc *Document) {
+	weights := make(map[string]float32)
+	for _, t := range Tokenize(doc.Subject) {
+		weights[t] += subjectBoost
+	}
+	for _, t := range Tokenize(doc.Body) {
+		weights[t]++
+	}
+
+	meta := &docMeta{fid: doc.Fid, time: doc.Time, terms: make([]string, 0, len(weights))}
+	for t := range weights {
+		meta.terms = append(meta.terms, t)
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.removeLocked(doc.ID)
+	for t, w := range weights {
+		p := x.postings[t]
+		if p == nil {
+			p = make(map[int64]float32)
+			x.postings[t] = p
+		}
+		p[doc.ID] = w
+	}
+	x.docs[doc.ID] = meta
+}
+
+// Remove 删除文档
+func (x *Index) Remove(id int64) {
+	x.mu.Lock()
+	x.removeLocked(id)
+	x.mu.Unlock()
+}
+
+func (x *Index) removeLocked(id int64) {
+	meta, ok := x.docs[id]
+	if !ok {
+		return
+	}
+	for _, t := range meta.terms {
+		if p := x.postings[t]; p != nil {
+			delete(p, id)
+			if len(p) == 0 {
+				delete(x.postings, t)
+			}
+		}
+	}
+	delete(x.docs, id)
+}
+
+// Len 文档数
+func (x *Index) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.docs)
+}
+
+// Search 查询，返回当前页命中与总命中数
+func (x *Index) Search(q Query) ([]Hit, int) {
+	if len(q.Terms) == 0 {
+		return nil, 0
+	}
+
+	x.mu.RLock()
+	lists := make([]map[int64]float32, 0, len(q.Terms))
+	for _, t := range q.Terms {
+		p := x.postings[t]
+		if len(p) == 0 {
+			x.mu.RUnlock()
+			return nil, 0
+		}
+		lists = append(lists, p)
+	}
+	// 从最短的倒排表开始求交集
+	sort.Slice(lists, func(i, j int) bool { return len(lists[i]) < len(lists[j]) })
+
+	n := float64(len(x.docs))
+	hits := make([]Hit, 0, len(lists[0]))
+	times := make(map[int64]int64, len(lists[0]))
+	for id := range lists[0] {
+		meta := x.docs[id]
+		if q.Filter != nil && !q.Filter(id, meta.fid) {
+			continue
+		}
+		score := 0.0
+		matched := true
+		for _, p := range lists {
+			w, ok := p[id]
+			if !ok {
+				matched = false
+				break
+			}
+			score += float64(w) * math.Log(1+n/float64(len(p)))
+		}
+		if !matched {
+			continue
+		}
+		hits = append(hits, Hit{ID: id, Fid: meta.fid, Score: score})
+		times[id] = meta.time
+	}
+	x.mu.RUnlock()
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		if times[hits[i].ID] != times[hits[j].ID] {
+			return times[hits[i].ID] > times[hits[j].ID]
+		}
+		return hits[i].ID > hits[j].ID
+	})
+
+	total := len(hits)
+	if q.Offset >= total {
+		return []Hit{}, total
+	}
+	end := total
+	if q.Limit > 0 && q.Offset+q.Limit < end {
+		end = q.Offset + q.Limit
+	}
+	return hits[q.Offset:end], total
+}
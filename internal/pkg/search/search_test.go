@@ -0,0 +1,62 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	got := QueryTerms("Go语言 数据库")
+	want := []string{"go", "语言", "数据", "据库"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("QueryTerms = %v, want %v", got, want)
+	}
+
+	got = Tokenize("中文")
+	want = []string{"中", "中文", "文"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Tokenize = %v, want %v", got, want)
+	}
+}
+
+func TestIndexSearch(t *testing.T) {
+	x := NewIndex()
+	x.Add(&Document{ID: 1, Fid: 1, Subject: "MySQL 数据库优化", Body: "索引与查询", Time: 1})
+	x.Add(&Document{ID: 2, Fid: 2, Subject: "Redis 缓存", Body: "数据库前面加一层缓存", Time: 2})
+	x.Add(&Document{ID: 3, Fid: 1, Subject: "闲聊", Body: "今天天气不错", Time: 3})
+
+	hits, total := x.Search(Query{Terms: QueryTerms("数据库"), Limit: 10})
+	if total != 2 || hits[0].ID != 1 {
+		t.Fatalf("subject match should rank first: %+v", hits)
+	}
+
+	hits, total = x.Search(Query{Terms: QueryTerms("数据库"), Filter: func(_ int64, fid int) bool { return fid == 2 }})
+	if total != 1 || hits[0].ID != 2 {
+		t.Fatalf("fid filter: %+v", hits)
+	}
+
+	// 替换文档后旧词条失效
+	x.Add(&Document{ID: 1, Fid: 1, Subject: "PostgreSQL", Time: 1})
+	if _, total = x.Search(Query{Terms: QueryTerms("mysql")}); total != 0 {
+		t.Fatal("stale terms after re-add")
+	}
+
+	x.Remove(2)
+	if _, total = x.Search(Query{Terms: QueryTerms("缓存")}); total != 0 || x.Len() != 2 {
+		t.Fatal("remove failed")
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	got := Highlight("Go 语言的<数据库>驱动, google", QueryTerms("数据库 go"), 0)
+	want := "<em>Go</em> 语言的&lt;<em>数据库</em>&gt;驱动, google"
+	if got != want {
+		t.Fatalf("Highlight = %q, want %q", got, want)
+	}
+
+	got = Highlight("0123456789关键词0123456789", QueryTerms("关键词"), 8)
+	want = "…89<em>关键词</em>012…"
+	if got != want {
+		t.Fatalf("snippet = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,121 @@
+// Package search 内嵌倒排索引
+//
+// 分词规则：
+//   - 拉丁字母/数字按连续片段切成单词，统一小写
+//   - 中日韩文字按连续片段切分：索引时同时写入单字与二元组（bigram），
+//     查询时片段长度 ≥2 用二元组、单字用单字，兼顾召回与精度
+package search
+
+import (
+	"unicode"
+)
+
+// maxWordRunes 单词最大长度，超长片段截断（防止异常数据撑大词典）
+const maxWordRunes = 32
+
+// segment 连续同类字符片段
+type segment struct {
+	runes []rune
+	cjk   bool
+}
+
+// isCJK 判断是否为中日韩文字
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) ||
+		unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) ||
+		unicode.Is(unicode.Hangul, r)
+}
+
+func isWord(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// segments 将文本切为单词/CJK 片段
+func segments(text string) []segment {
+	var (
+		segs []segment
+		cur  []rune
+		cjk  bool
+	)
+	flush := func() {
+		if len(cur) > 0 {
+			segs = append(segs, segment{runes: cur, cjk: cjk})
+			cur = nil
+		}
+	}
+
+	for _, r := range text {
+		r = unicode.ToLower(r)
+		switch {
+		case isCJK(r):
+			if !cjk {
+				flush()
+			}
+			cjk = true
+			cur = append(cur, r)
+		case isWord(r):
+			if cjk {
+				flush()
+			}
+			cjk = false
+			cur = append(cur, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return segs
+}
+
+// Tokenize 索引分词（CJK 输出单字 + 二元组）
+func Tokenize(text string) []string {
+	var tokens []string
+	for _, seg := range segments(text) {
+		if !seg.cjk {
+			tokens = append(tokens, wordToken(seg.runes))
+			continue
+		}
+		for i := range seg.runes {
+			tokens = append(tokens, string(seg.runes[i]))
+			if i+1 < len(seg.runes) {
+				tokens = append(tokens, string(seg.runes[i:i+2]))
+			}
+		}
+	}
+	return tokens
+}
+
+// QueryTerms 查询分词（去重，CJK 片段长度 ≥2 时只用二元组）
+func QueryTerms(query string) []string {
+	seen := make(map[string]struct{})
+	var terms []string
+	add := func(t string) {
+		if _, ok := seen[t]; ok {
+			return
+		}
+		seen[t] = struct{}{}
+		terms = append(terms, t)
+	}
+
+	for _, seg := range segments(query) {
+		switch {
+		case !seg.cjk:
+			add(wordToken(seg.runes))
+		case len(seg.runes) == 1:
+			add(string(seg.runes))
+		default:
+			for i := 0; i+1 < len(seg.runes); i++ {
+				add(string(seg.runes[i : i+2]))
+			}
+		}
+	}
+	return terms
+}
+
+func wordToken(runes []rune) string {
+	if len(runes) > maxWordRunes {
+		runes = runes[:maxWordRunes]
+	}
+	return string(runes)
+}
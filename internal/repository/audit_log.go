@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+
+	"well_go/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// AuditLogRepository 审计日志数据访问接口
+type AuditLogRepository interface {
+	Create(ctx context.Context, log *model.AuditLog) error
+	// List 按 ID 倒序分页，action 为空时不过滤
+	List(ctx context.Context, action string, offset, limit int) ([]*model.AuditLog, error)
+	Count(ctx context.Context, action string) (int, error)
+}
+
+// auditLogRepository 审计日志数据访问实现
+type auditLogRepository struct {
+	db *sqlx.DB
+}
+
+// NewAuditLogRepository 创建 AuditLogRepository 实例
+func NewAuditLogRepository(db *sqlx.DB) AuditLogRepository {
+	return &auditLogRepository{db: db}
+}
+
+// Create 写入审计日志
+func (r *auditLogRepository) Create(ctx context.Context, log *model.AuditLog) error {
+	result, err := r.db.ExecContext(ctx,
+		"INSERT INTO audit_log (action, uid, target, ip, detail, dateline) VALUES (?, ?, ?, ?, ?, ?)",
+		log.Action, log.Uid, log.Target, log.IP, log.Detail, log.Dateline)
+	if err != nil {
+		return err
+	}
+	log.ID, err = result.LastInsertId()
+	return err
+}
+
+// List 按 ID 倒序分页
+func (r *auditLogRepository) List(ctx context.Context, action string, offset, limit int) ([]*model.AuditLog, error) {
+	var logs []*model.AuditLog
+	var err error
+	if action == "" {
+		err = r.db.SelectContext(ctx, &logs,
+			"SELECT id, action, uid, target, ip, detail, dateline FROM audit_log ORDER BY id DESC LIMIT ?, ?",
+			offset, limit)
+	} else {
+		err = r.db.SelectContext(ctx, &logs,
+			"SELECT id, action, uid, target, ip, detail, dateline FROM audit_log WHERE action = ? ORDER BY id DESC LIMIT ?, ?",
+			action, offset, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// Count 审计日志总数
+func (r *auditLogRepository) Count(ctx context.Context, action string) (int, error) {
+	var count int
+	var err error
+	if action == "" {
+		err = r.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM audit_log")
+	} else {
+		err = r.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM audit_log WHERE action = ?", action)
+	}
+	return count, err
+}
@@ -3,12 +3,24 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
 
 	"well_go/internal/model"
 
 	"github.com/jmoiron/sqlx"
 )
 
+var (
+	// ErrForumCycle 目标父版块位于被移动版块的子树中
+	ErrForumCycle = errors.New("forum cannot be moved under itself or its descendants")
+	// ErrForumNotEmpty 版块下仍有主题（含草稿、回收站中的主题）
+	ErrForumNotEmpty = errors.New("forum still has threads")
+	// ErrForumHasChildren 版块下仍有未删除的子版块
+	ErrForumHasChildren = errors.New("forum still has sub forums")
+)
+
 // ForumRepository Forum 数据访问接口
 type ForumRepository interface {
 	GetByID(ctx context.Context, fid int) (*model.Forum, error)
@@ -17,7 +29,19 @@ type ForumRepository interface {
 	GetByParent(ctx context.Context, parent int) ([]*model.Forum, error)
 	Create(ctx context.Context, forum *model.Forum) (int, error)
 	Update(ctx context.Context, forum *model.Forum) error
-	Delete(ctx context.Context, fid int) error
+	// Move 更换父版块并重算整个子树的 path/depth，order 非 nil 时同时设置排序值，返回受影响的 fid（含自身）
+	// 版块或父版块不存在（含回收站）时返回 sql.ErrNoRows，形成环时返回 ErrForumCycle
+	Move(ctx context.Context, fid, parent int, order *int) ([]int, error)
+	// Reorder 批量设置排序值（fid → order）
+	Reorder(ctx context.Context, orders map[int]int) error
+	// 回收站：Trash 只做标记，Purge 仅清除已在回收站且无主题、无子版块的版块
+	// Trash 在版块不存在或已在回收站时返回 false，仍有主题或子版块时返回 ErrForumNotEmpty/ErrForumHasChildren
+	Trash(ctx context.Context, fid int, now int) (bool, error)
+	Restore(ctx context.Context, fid int) (bool, error)
+	Purge(ctx context.Context, fid int) (bool, error)
+	GetTrashed(ctx context.Context, offset, limit int) ([]*model.Forum, error)
+	CountTrashed(ctx context.Context) (int, error)
+	GetExpiredTrash(ctx context.Context, before int, limit int) ([]int, error)
 	IncThreads(ctx context.Context, fid int) error
 	IncToday(ctx context.Context, fid int) error
 }
@@ -48,7 +72,7 @@ func (r *forumRepository) GetByID(ctx context.Context, fid int) (*model.Forum, e
 // GetAll 获取所有 Forum
 func (r *forumRepository) GetAll(ctx context.Context) ([]*model.Forum, error) {
 	var forums []*model.Forum
-	err := r.db.SelectContext(ctx, &forums, "SELECT * FROM forum ORDER BY `order` ASC")
+	err := r.db.SelectContext(ctx, &forums, "SELECT * FROM forum WHERE deleted_at = 0 ORDER BY `order` ASC")
 	if err != nil {
 		return nil, err
 	}
@@ -58,7 +82,7 @@ func (r *forumRepository) GetAll(ctx context.Context) ([]*model.Forum, error) {
 // GetTree 获取论坛树（一级版块 + 子版块）
 func (r *forumRepository) GetTree(ctx context.Context) ([]*model.Forum, error) {
 	var forums []*model.Forum
-	err := r.db.SelectContext(ctx, &forums, "SELECT * FROM forum ORDER BY `order` ASC")
+	err := r.db.SelectContext(ctx, &forums, "SELECT * FROM forum WHERE deleted_at = 0 ORDER BY `order` ASC")
 	if err != nil {
 		return nil, err
 	}
@@ -68,7 +92,7 @@ func (r *forumRepository) GetTree(ctx context.Context) ([]*model.Forum, error) {
 // GetByParent 根据父版块获取子版块
 func (r *forumRepository) GetByParent(ctx context.Context, parent int) ([]*model.Forum, error) {
 	var forums []*model.Forum
-	err := r.db.SelectContext(ctx, &forums, "SELECT * FROM forum WHERE parent = ? ORDER BY `order` ASC", parent)
+	err := r.db.SelectContext(ctx, &forums, "SELECT * FROM forum WHERE parent = ? AND deleted_at = 0 ORDER BY `order` ASC", parent)
 	if err != nil {
 		return nil, err
 	}
@@ -95,10 +119,202 @@ func (r *forumRepository) Update(ctx context.Context, forum *model.Forum) error
 	return err
 }
 
-// Delete 删除 Forum
-func (r *forumRepository) Delete(ctx context.Context, fid int) error {
-	_, err := r.db.ExecContext(ctx, "DELETE FROM forum WHERE fid = ?", fid)
-	return err
+// Move 更换父版块并在同一事务中重算子树 path/depth（及排序值）
+// path 形如 "0,1,2"（祖先链），子树即 path 以 "{path},{fid}" 开头的版块
+func (r *forumRepository) Move(ctx context.Context, fid, parent int, order *int) ([]int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var forum model.Forum
+	if err := tx.GetContext(ctx, &forum, "SELECT * FROM forum WHERE fid = ? AND deleted_at = 0 FOR UPDATE", fid); err != nil {
+		return nil, err
+	}
+
+	oldPrefix := forum.Path + "," + strconv.Itoa(fid)
+	newPath, newDepth := "0", 0
+	if parent > 0 {
+		if parent == fid {
+			return nil, ErrForumCycle
+		}
+		var p model.Forum
+		if err := tx.GetContext(ctx, &p, "SELECT * FROM forum WHERE fid = ? AND deleted_at = 0 FOR UPDATE", parent); err != nil {
+			return nil, err
+		}
+		if p.Path == oldPrefix || strings.HasPrefix(p.Path, oldPrefix+",") {
+			return nil, ErrForumCycle
+		}
+		newPath = p.Path + "," + strconv.Itoa(parent)
+		newDepth = p.Depth + 1
+	}
+
+	// 子树（含回收站中的子版块，恢复后仍需正确的层级）
+	var fids []int
+	err = tx.SelectContext(ctx, &fids,
+		"SELECT fid FROM forum WHERE path = ? OR path LIKE ? FOR UPDATE", oldPrefix, oldPrefix+",%")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE forum SET parent = ?, path = ?, depth = ? WHERE fid = ?",
+		parent, newPath, newDepth, fid); err != nil {
+		return nil, err
+	}
+	if order != nil {
+		if _, err := tx.ExecContext(ctx, "UPDATE forum SET `order` = ? WHERE fid = ?", *order, fid); err != nil {
+			return nil, err
+		}
+	}
+	if len(fids) > 0 {
+		newPrefix := newPath + "," + strconv.Itoa(fid)
+		_, err = tx.ExecContext(ctx,
+			"UPDATE forum SET path = CONCAT(?, SUBSTRING(path, ?)), depth = depth + ? WHERE path = ? OR path LIKE ?",
+			newPrefix, len(oldPrefix)+1, newDepth-forum.Depth, oldPrefix, oldPrefix+",%")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return append([]int{fid}, fids...), tx.Commit()
+}
+
+// Reorder 批量设置排序值
+func (r *forumRepository) Reorder(ctx context.Context, orders map[int]int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for fid, order := range orders {
+		if _, err := tx.ExecContext(ctx, "UPDATE forum SET `order` = ? WHERE fid = ?", order, fid); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Trash 将版块移入回收站
+// 空版块检查与标记在同一事务内，计数查询加锁，避免检查后新建的主题/子版块落入回收站中的版块
+func (r *forumRepository) Trash(ctx context.Context, fid int, now int) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var deletedAt int
+	err = tx.GetContext(ctx, &deletedAt, "SELECT deleted_at FROM forum WHERE fid = ? FOR UPDATE", fid)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if deletedAt > 0 {
+		return false, nil
+	}
+
+	var count int
+	if err := tx.GetContext(ctx, &count, "SELECT COUNT(*) FROM thread WHERE fid = ? FOR UPDATE", fid); err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return false, ErrForumNotEmpty
+	}
+	if err := tx.GetContext(ctx, &count, "SELECT COUNT(*) FROM forum WHERE parent = ? AND deleted_at = 0 FOR UPDATE", fid); err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return false, ErrForumHasChildren
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE forum SET deleted_at = ? WHERE fid = ?", now, fid); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// Restore 从回收站恢复版块
+func (r *forumRepository) Restore(ctx context.Context, fid int) (bool, error) {
+	result, err := r.db.ExecContext(ctx, "UPDATE forum SET deleted_at = 0 WHERE fid = ? AND deleted_at > 0", fid)
+	if err != nil {
+		return false, err
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+// Purge 彻底删除回收站中的版块及其权限行
+// 仍有主题（含草稿/回收站主题）或子版块时不删除，返回 false
+func (r *forumRepository) Purge(ctx context.Context, fid int) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var forum model.Forum
+	err = tx.GetContext(ctx, &forum, "SELECT * FROM forum WHERE fid = ? FOR UPDATE", fid)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if forum.DeletedAt == 0 {
+		return false, nil
+	}
+
+	var count int
+	err = tx.GetContext(ctx, &count,
+		"SELECT (SELECT COUNT(*) FROM thread WHERE fid = ?) + (SELECT COUNT(*) FROM forum WHERE parent = ?)", fid, fid)
+	if err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return false, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM forum_access WHERE fid = ?", fid); err != nil {
+		return false, err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM forum WHERE fid = ?", fid); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// GetTrashed 获取回收站版块（按删除时间倒序）
+func (r *forumRepository) GetTrashed(ctx context.Context, offset, limit int) ([]*model.Forum, error) {
+	var forums []*model.Forum
+	err := r.db.SelectContext(ctx, &forums,
+		"SELECT * FROM forum WHERE deleted_at > 0 ORDER BY deleted_at DESC LIMIT ?, ?", offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return forums, nil
+}
+
+// CountTrashed 回收站版块数
+func (r *forumRepository) CountTrashed(ctx context.Context) (int, error) {
+	var count int
+	err := r.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM forum WHERE deleted_at > 0")
+	return count, err
+}
+
+// GetExpiredTrash 获取删除时间早于 before 的回收站版块（子版块优先，便于逐层清除）
+func (r *forumRepository) GetExpiredTrash(ctx context.Context, before int, limit int) ([]int, error) {
+	var fids []int
+	err := r.db.SelectContext(ctx, &fids,
+		"SELECT fid FROM forum WHERE deleted_at > 0 AND deleted_at < ? ORDER BY depth DESC, deleted_at ASC LIMIT ?", before, limit)
+	if err != nil {
+		return nil, err
+	}
+	return fids, nil
 }
 
 // IncThreads 增加主题数
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+
+	"well_go/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ForumAccessRepository ForumAccess 数据访问接口
+type ForumAccessRepository interface {
+	GetAll(ctx context.Context) ([]*model.ForumAccess, error)
+	GetByFid(ctx context.Context, fid int) ([]*model.ForumAccess, error)
+	Replace(ctx context.Context, fid int, rows []*model.ForumAccess) error
+}
+
+// forumAccessRepository ForumAccess 数据访问实现
+type forumAccessRepository struct {
+	db *sqlx.DB
+}
+
+// NewForumAccessRepository 创建 ForumAccessRepository 实例
+func NewForumAccessRepository(db *sqlx.DB) ForumAccessRepository {
+	return &forumAccessRepository{db: db}
+}
+
+// GetAll 获取全部权限行（runtime 预热用）
+func (r *forumAccessRepository) GetAll(ctx context.Context) ([]*model.ForumAccess, error) {
+	var rows []*model.ForumAccess
+	err := r.db.SelectContext(ctx, &rows,
+		"SELECT id, fid, gid, `read`, thread, reply, upload, download FROM forum_access ORDER BY fid, gid")
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// GetByFid 获取版块的权限行
+func (r *forumAccessRepository) GetByFid(ctx context.Context, fid int) ([]*model.ForumAccess, error) {
+	var rows []*model.ForumAccess
+	err := r.db.SelectContext(ctx, &rows,
+		"SELECT id, fid, gid, `read`, thread, reply, upload, download FROM forum_access WHERE fid = ? ORDER BY gid", fid)
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// Replace 整体替换版块的权限行（空列表表示取消限制）
+func (r *forumAccessRepository) Replace(ctx context.Context, fid int, rows []*model.ForumAccess) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM forum_access WHERE fid = ?", fid); err != nil {
+		return err
+	}
+
+	for _, a := range rows {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO forum_access (fid, gid, `read`, thread, reply, upload, download) VALUES (?, ?, ?, ?, ?, ?, ?)",
+			fid, a.GID, a.Read, a.Thread, a.Reply, a.Upload, a.Download)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
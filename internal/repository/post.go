@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"well_go/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// PostRepository Post 数据访问接口
+type PostRepository interface {
+	GetByID(ctx context.Context, pid int64) (*model.Post, error)
+	// GetListPIDsByTid/CountByTid 只包含正常状态的回帖
+	GetListPIDsByTid(ctx context.Context, tid int64, offset, limit int) ([]int64, error)
+	CountByTid(ctx context.Context, tid int64) (int, error)
+	GetByPIDs(ctx context.Context, pids []int64) ([]*model.Post, error)
+	// Create/Update/Delete 在同一事务内维护 thread.replies/lastpost 与 forum.posts（只计正常状态的回帖）
+	Create(ctx context.Context, post *model.Post, fid int) error
+	Update(ctx context.Context, post *model.Post, fid int) error
+	Delete(ctx context.Context, post *model.Post, fid int) error
+}
+
+// postRepository Post 数据访问实现
+type postRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostRepository 创建 PostRepository 实例
+func NewPostRepository(db *sqlx.DB) PostRepository {
+	return &postRepository{db: db}
+}
+
+// GetByID 根据 ID 获取 Post
+func (r *postRepository) GetByID(ctx context.Context, pid int64) (*model.Post, error) {
+	var post model.Post
+	err := r.db.GetContext(ctx, &post, "SELECT pid, tid, uid, message, dateline, status FROM post WHERE pid = ?", pid)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &post, nil
+}
+
+// GetListPIDsByTid 根据 tid 获取正常状态的回帖 pid 列表（按发布顺序）
+func (r *postRepository) GetListPIDsByTid(ctx context.Context, tid int64, offset, limit int) ([]int64, error) {
+	var pids []int64
+	err := r.db.SelectContext(ctx, &pids,
+		"SELECT pid FROM post WHERE tid = ? AND status = ? ORDER BY pid ASC LIMIT ?, ?",
+		tid, model.PostNormal, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return pids, nil
+}
+
+// CountByTid 主题下正常状态的回帖数（与 GetListPIDsByTid 条件一致）
+func (r *postRepository) CountByTid(ctx context.Context, tid int64) (int, error) {
+	var count int
+	err := r.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM post WHERE tid = ? AND status = ?", tid, model.PostNormal)
+	return count, err
+}
+
+// GetByPIDs 批量按 pid 获取回帖（保持输入顺序）
+func (r *postRepository) GetByPIDs(ctx context.Context, pids []int64) ([]*model.Post, error) {
+	if len(pids) == 0 {
+		return []*model.Post{}, nil
+	}
+
+	placeholders := make([]string, 0, len(pids))
+	args := make([]interface{}, 0, len(pids)*2)
+	for _, pid := range pids {
+		placeholders = append(placeholders, "?")
+		args = append(args, pid)
+	}
+	for _, pid := range pids {
+		args = append(args, pid)
+	}
+
+	in := strings.Join(placeholders, ",")
+	query := fmt.Sprintf(
+		"SELECT pid, tid, uid, message, dateline, status FROM post WHERE pid IN (%s) ORDER BY FIELD(pid, %s)",
+		in, in,
+	)
+
+	var posts []*model.Post
+	if err := r.db.SelectContext(ctx, &posts, query, args...); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+// Create 创建回帖
+func (r *postRepository) Create(ctx context.Context, post *model.Post, fid int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO post (pid, tid, uid, message, dateline, status) VALUES (?, ?, ?, ?, ?, ?)",
+		post.Pid, post.Tid, post.Uid, post.Message, post.Dateline, post.Status)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE thread SET replies = replies + 1, lastpost = ? WHERE tid = ?",
+		post.Dateline, post.Tid)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE forum SET posts = posts + 1 WHERE fid = ?", fid)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Update 更新回帖内容与状态，正常状态变化时同步回帖数与 lastpost
+func (r *postRepository) Update(ctx context.Context, post *model.Post, fid int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// 以锁定后的当前状态为准，避免并发修改重复计数
+	var prevStatus int
+	err = tx.GetContext(ctx, &prevStatus, "SELECT status FROM post WHERE pid = ? FOR UPDATE", post.Pid)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE post SET message = ?, status = ? WHERE pid = ?",
+		post.Message, post.Status, post.Pid)
+	if err != nil {
+		return err
+	}
+
+	wasNormal, isNormal := prevStatus == model.PostNormal, post.Status == model.PostNormal
+	if wasNormal != isNormal {
+		delta := 1
+		if wasNormal {
+			delta = -1
+		}
+		if err := adjustPostCounters(ctx, tx, post.Tid, fid, delta); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Delete 删除回帖，正常状态的回帖同步回帖数与 lastpost
+func (r *postRepository) Delete(ctx context.Context, post *model.Post, fid int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var status int
+	err = tx.GetContext(ctx, &status, "SELECT status FROM post WHERE pid = ? FOR UPDATE", post.Pid)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM post WHERE pid = ?", post.Pid)
+	if err != nil {
+		return err
+	}
+
+	// 屏蔽的回帖在屏蔽时已扣减，不再重复扣减
+	if status == model.PostNormal {
+		if err := adjustPostCounters(ctx, tx, post.Tid, fid, -1); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// adjustPostCounters 按 delta 调整 thread.replies 与 forum.posts，
+// lastpost 重算为剩余正常回帖的最后时间（无回帖时回退到主题发布时间）
+func adjustPostCounters(ctx context.Context, tx *sqlx.Tx, tid int64, fid int, delta int) error {
+	_, err := tx.ExecContext(ctx,
+		"UPDATE thread SET replies = GREATEST(CAST(replies AS SIGNED) + ?, 0), lastpost = GREATEST(dateline, COALESCE((SELECT MAX(dateline) FROM post WHERE tid = ? AND status = ?), 0)) WHERE tid = ?",
+		delta, tid, model.PostNormal, tid)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE forum SET posts = GREATEST(CAST(posts AS SIGNED) + ?, 0) WHERE fid = ?", delta, fid)
+	return err
+}
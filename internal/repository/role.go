@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"well_go/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// RoleRepository 角色与用户角色数据访问接口
+type RoleRepository interface {
+	GetAll(ctx context.Context) ([]*model.Role, error)
+	GetByID(ctx context.Context, roleID int) (*model.Role, error)
+	GetByIDs(ctx context.Context, roleIDs []int) ([]*model.Role, error)
+	Create(ctx context.Context, role *model.Role) (int, error)
+	Update(ctx context.Context, role *model.Role) error
+	// Delete 删除角色及其全部授予
+	Delete(ctx context.Context, roleID int) (bool, error)
+
+	// GetUserRoles 用户的角色授予（仅 uid/role_id/fid，角色名称与权限由调用方按 role_id 补全）
+	GetUserRoles(ctx context.Context, uid int64) ([]*model.UserRole, error)
+	// GetForumRoles 版块的版主等限定角色授予（不含子版块，同样只含授予字段）
+	GetForumRoles(ctx context.Context, fid int) ([]*model.UserRole, error)
+	Assign(ctx context.Context, uid int64, roleID, fid int) error
+	Revoke(ctx context.Context, uid int64, roleID, fid int) (bool, error)
+}
+
+// roleRepository 角色数据访问实现
+type roleRepository struct {
+	db *sqlx.DB
+}
+
+// NewRoleRepository 创建 RoleRepository 实例
+func NewRoleRepository(db *sqlx.DB) RoleRepository {
+	return &roleRepository{db: db}
+}
+
+// GetAll 获取全部角色
+func (r *roleRepository) GetAll(ctx context.Context) ([]*model.Role, error) {
+	var roles []*model.Role
+	err := r.db.SelectContext(ctx, &roles,
+		"SELECT role_id, name, title, permissions, created_at, updated_at FROM role ORDER BY role_id")
+	if err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// GetByID 获取角色，不存在时返回 nil, nil
+func (r *roleRepository) GetByID(ctx context.Context, roleID int) (*model.Role, error) {
+	var role model.Role
+	err := r.db.GetContext(ctx, &role,
+		"SELECT role_id, name, title, permissions, created_at, updated_at FROM role WHERE role_id = ?", roleID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// GetByIDs 批量获取角色
+func (r *roleRepository) GetByIDs(ctx context.Context, roleIDs []int) ([]*model.Role, error) {
+	if len(roleIDs) == 0 {
+		return nil, nil
+	}
+	placeholders := make([]string, len(roleIDs))
+	args := make([]interface{}, len(roleIDs))
+	for i, id := range roleIDs {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+	var roles []*model.Role
+	query := fmt.Sprintf("SELECT role_id, name, title, permissions, created_at, updated_at FROM role WHERE role_id IN (%s)",
+		strings.Join(placeholders, ","))
+	if err := r.db.SelectContext(ctx, &roles, query, args...); err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// Create 创建角色
+func (r *roleRepository) Create(ctx context.Context, role *model.Role) (int, error) {
+	result, err := r.db.ExecContext(ctx,
+		"INSERT INTO role (name, title, permissions) VALUES (?, ?, ?)",
+		role.Name, role.Title, role.Permissions)
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	return int(id), err
+}
+
+// Update 更新角色名称与权限
+func (r *roleRepository) Update(ctx context.Context, role *model.Role) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE role SET name = ?, title = ?, permissions = ? WHERE role_id = ?",
+		role.Name, role.Title, role.Permissions, role.RoleID)
+	return err
+}
+
+// Delete 删除角色及其全部授予
+func (r *roleRepository) Delete(ctx context.Context, roleID int) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM role WHERE role_id = ?", roleID)
+	if err != nil {
+		return false, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return false, nil
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM user_role WHERE role_id = ?", roleID); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// GetUserRoles 用户的角色授予
+func (r *roleRepository) GetUserRoles(ctx context.Context, uid int64) ([]*model.UserRole, error) {
+	var roles []*model.UserRole
+	err := r.db.SelectContext(ctx, &roles,
+		"SELECT uid, role_id, fid FROM user_role WHERE uid = ? ORDER BY fid, role_id", uid)
+	if err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// GetForumRoles 版块的限定角色授予
+func (r *roleRepository) GetForumRoles(ctx context.Context, fid int) ([]*model.UserRole, error) {
+	var roles []*model.UserRole
+	err := r.db.SelectContext(ctx, &roles,
+		"SELECT uid, role_id, fid FROM user_role WHERE fid = ? ORDER BY role_id, uid", fid)
+	if err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// Assign 授予角色（已存在时忽略）
+func (r *roleRepository) Assign(ctx context.Context, uid int64, roleID, fid int) error {
+	_, err := r.db.ExecContext(ctx,
+		"INSERT IGNORE INTO user_role (uid, role_id, fid) VALUES (?, ?, ?)", uid, roleID, fid)
+	return err
+}
+
+// Revoke 收回角色，不存在时返回 false
+func (r *roleRepository) Revoke(ctx context.Context, uid int64, roleID, fid int) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		"DELETE FROM user_role WHERE uid = ? AND role_id = ? AND fid = ?", uid, roleID, fid)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
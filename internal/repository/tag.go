@@ -3,14 +3,32 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 
 	"well_go/internal/model"
+	"well_go/internal/pkg/pinyin"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
 )
 
+// ErrSlugConflict 写入的 slug 违反 uk_slug 唯一索引（并发请求先占用了同一 slug）
+var ErrSlugConflict = errors.New("tag slug already exists")
+
+// mysqlDuplicateEntry MySQL 唯一索引冲突错误码
+const mysqlDuplicateEntry = 1062
+
+// slugConflict 将 tag 表的唯一索引冲突转换为 ErrSlugConflict（tag 表只有 slug 唯一）
+func slugConflict(err error) error {
+	var me *mysql.MySQLError
+	if errors.As(err, &me) && me.Number == mysqlDuplicateEntry {
+		return ErrSlugConflict
+	}
+	return err
+}
+
 // TagRepository Tag 数据访问接口
 type TagRepository interface {
 	GetByID(ctx context.Context, tagID int) (*model.Tag, error)
@@ -22,10 +40,25 @@ type TagRepository interface {
 	GetByThread(ctx context.Context, tid int64) ([]*model.Tag, error)
 	Create(ctx context.Context, tag *model.Tag) (int, error)
 	Update(ctx context.Context, tag *model.Tag) error
-	Delete(ctx context.Context, tagID int) error
+	// 回收站：Trash 只做标记（关联保留，恢复后原样可见），Purge 清除标签及全部关联
+	Trash(ctx context.Context, tagID int, now int) (bool, error)
+	Restore(ctx context.Context, tagID int) (bool, error)
+	Purge(ctx context.Context, tagID int) (bool, error)
+	// Merge 将 from 的主题关联与同义词并入 to（去重），from 名称成为 to 的同义词并删除 from；
+	// 返回原先关联 from 的 tid。任一标签不存在（含回收站）时返回 sql.ErrNoRows
+	Merge(ctx context.Context, from, to int) ([]int64, error)
+	GetTrashed(ctx context.Context, offset, limit int) ([]*model.Tag, error)
+	CountTrashed(ctx context.Context) (int, error)
+	GetExpiredTrash(ctx context.Context, before int, limit int) ([]int, error)
 	IncThreads(ctx context.Context, tagID int) error
 	DecThreads(ctx context.Context, tagID int) error
-	IncView(ctx context.Context, tagID int) error
+	// AddViews 批量累加浏览数（tag_id → 增量）
+	AddViews(ctx context.Context, views map[int]int64) error
+	// SlugTaken slug 是否已被其它标签（含回收站）占用
+	SlugTaken(ctx context.Context, slug string, excludeID int) (bool, error)
+	UpdateSlug(ctx context.Context, tagID int, slug string) error
+	// GetWithoutSlug 获取 slug 为空的标签（含回收站，回填用）
+	GetWithoutSlug(ctx context.Context, limit int) ([]*model.Tag, error)
 	// Sitemap 专用方法
 	GetSitemapList(ctx context.Context, offset, limit int) ([]*model.Tag, error)
 }
@@ -43,7 +76,7 @@ func (r *tagRepository) GetByIDs(ctx context.Context, tagIDs []int) ([]*model.Ta
 		args = append(args, id)
 	}
 
-	query := fmt.Sprintf("SELECT tag_id, name, slug, threads, view, status FROM tag WHERE tag_id IN (%s)", strings.Join(placeholders, ","))
+	query := fmt.Sprintf("SELECT tag_id, name, slug, threads, view, status FROM tag WHERE tag_id IN (%s) AND deleted_at = 0", strings.Join(placeholders, ","))
 
 	var tags []*model.Tag
 	if err := r.db.SelectContext(ctx, &tags, query, args...); err != nil {
@@ -91,7 +124,7 @@ func (r *tagRepository) GetByName(ctx context.Context, name string) (*model.Tag,
 // GetBySlug 根据 slug 获取 Tag
 func (r *tagRepository) GetBySlug(ctx context.Context, slug string) (*model.Tag, error) {
 	var tag model.Tag
-	err := r.db.GetContext(ctx, &tag, "SELECT * FROM tag WHERE slug = ?", slug)
+	err := r.db.GetContext(ctx, &tag, "SELECT * FROM tag WHERE slug = ? AND deleted_at = 0", slug)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -104,7 +137,7 @@ func (r *tagRepository) GetBySlug(ctx context.Context, slug string) (*model.Tag,
 // GetAll 获取所有 Tag
 func (r *tagRepository) GetAll(ctx context.Context) ([]*model.Tag, error) {
 	var tags []*model.Tag
-	err := r.db.SelectContext(ctx, &tags, "SELECT * FROM tag ORDER BY threads DESC")
+	err := r.db.SelectContext(ctx, &tags, "SELECT * FROM tag WHERE deleted_at = 0 ORDER BY threads DESC")
 	if err != nil {
 		return nil, err
 	}
@@ -114,7 +147,7 @@ func (r *tagRepository) GetAll(ctx context.Context) ([]*model.Tag, error) {
 // GetHot 获取热门 Tag
 func (r *tagRepository) GetHot(ctx context.Context, limit int) ([]*model.Tag, error) {
 	var tags []*model.Tag
-	err := r.db.SelectContext(ctx, &tags, "SELECT * FROM tag ORDER BY threads DESC, view DESC LIMIT ?", limit)
+	err := r.db.SelectContext(ctx, &tags, "SELECT * FROM tag WHERE deleted_at = 0 ORDER BY threads DESC, view DESC LIMIT ?", limit)
 	if err != nil {
 		return nil, err
 	}
@@ -127,7 +160,7 @@ func (r *tagRepository) GetByThread(ctx context.Context, tid int64) ([]*model.Ta
 	query := `
 		SELECT t.* FROM tag t
 		INNER JOIN thread_tag tt ON t.tag_id = tt.tag_id
-		WHERE tt.tid = ?
+		WHERE tt.tid = ? AND t.deleted_at = 0
 		ORDER BY t.threads DESC
 	`
 	err := r.db.SelectContext(ctx, &tags, query, tid)
@@ -139,16 +172,16 @@ func (r *tagRepository) GetByThread(ctx context.Context, tid int64) ([]*model.Ta
 
 // Create 创建 Tag
 func (r *tagRepository) Create(ctx context.Context, tag *model.Tag) (int, error) {
-	// 生成 slug
+	// 兜底生成 slug（唯一性由 TagService 选取，uk_slug 兜底）
 	if tag.Slug == "" {
-		tag.Slug = generateSlug(tag.Name)
+		tag.Slug = pinyin.Slug(tag.Name, model.TagSlugMaxLen)
 	}
 
 	result, err := r.db.ExecContext(ctx,
 		"INSERT INTO tag (name, slug, threads, view, status) VALUES (?, ?, ?, ?, ?)",
 		tag.Name, tag.Slug, tag.Threads, tag.View, tag.Status)
 	if err != nil {
-		return 0, err
+		return 0, slugConflict(err)
 	}
 	id, _ := result.LastInsertId()
 	return int(id), nil
@@ -162,10 +195,161 @@ func (r *tagRepository) Update(ctx context.Context, tag *model.Tag) error {
 	return err
 }
 
-// Delete 删除 Tag
-func (r *tagRepository) Delete(ctx context.Context, tagID int) error {
-	_, err := r.db.ExecContext(ctx, "DELETE FROM tag WHERE tag_id = ?", tagID)
-	return err
+// Trash 将标签移入回收站
+func (r *tagRepository) Trash(ctx context.Context, tagID int, now int) (bool, error) {
+	result, err := r.db.ExecContext(ctx, "UPDATE tag SET deleted_at = ? WHERE tag_id = ? AND deleted_at = 0", now, tagID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+// Restore 从回收站恢复标签
+func (r *tagRepository) Restore(ctx context.Context, tagID int) (bool, error) {
+	result, err := r.db.ExecContext(ctx, "UPDATE tag SET deleted_at = 0 WHERE tag_id = ? AND deleted_at > 0", tagID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+// Purge 彻底删除回收站中的标签及其主题关联
+func (r *tagRepository) Purge(ctx context.Context, tagID int) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM tag WHERE tag_id = ? AND deleted_at > 0", tagID)
+	if err != nil {
+		return false, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return false, nil
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM thread_tag WHERE tag_id = ?", tagID); err != nil {
+		return false, err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM tag_alias WHERE tag_id = ?", tagID); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// Merge 合并标签（同一事务）
+func (r *tagRepository) Merge(ctx context.Context, from, to int) ([]int64, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var src, dst model.Tag
+	if err := tx.GetContext(ctx, &src, "SELECT * FROM tag WHERE tag_id = ? AND deleted_at = 0 FOR UPDATE", from); err != nil {
+		return nil, err
+	}
+	if err := tx.GetContext(ctx, &dst, "SELECT * FROM tag WHERE tag_id = ? AND deleted_at = 0 FOR UPDATE", to); err != nil {
+		return nil, err
+	}
+
+	var tids []int64
+	if err := tx.SelectContext(ctx, &tids, "SELECT tid FROM thread_tag WHERE tag_id = ?", from); err != nil {
+		return nil, err
+	}
+
+	// 已同时关联两个标签的主题由 uk_tid_tagid 去重
+	stmts := []struct {
+		query string
+		args  []interface{}
+	}{
+		{"INSERT IGNORE INTO thread_tag (tid, tag_id) SELECT tid, ? FROM thread_tag WHERE tag_id = ?", []interface{}{to, from}},
+		{"DELETE FROM thread_tag WHERE tag_id = ?", []interface{}{from}},
+		{"UPDATE tag_alias SET tag_id = ? WHERE tag_id = ?", []interface{}{to, from}},
+		{"INSERT INTO tag_alias (alias, tag_id) VALUES (?, ?) ON DUPLICATE KEY UPDATE tag_id = VALUES(tag_id)", []interface{}{src.Name, to}},
+		{"DELETE FROM tag WHERE tag_id = ?", []interface{}{from}},
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.ExecContext(ctx, stmt.query, stmt.args...); err != nil {
+			return nil, err
+		}
+	}
+
+	// 关联数按未进回收站的主题重算（与 Trash/Restore 的维护口径一致）
+	var merged []int64
+	if err := tx.SelectContext(ctx, &merged, "SELECT tid FROM thread_tag WHERE tag_id = ?", to); err != nil {
+		return nil, err
+	}
+	threads, err := countLiveThreads(ctx, tx, merged)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE tag SET threads = ?, `view` = `view` + ? WHERE tag_id = ?", threads, src.View, to); err != nil {
+		return nil, err
+	}
+
+	return tids, tx.Commit()
+}
+
+// mergeCountBatch 重算关联数时每次 IN 查询的 tid 数
+const mergeCountBatch = 1000
+
+// countLiveThreads 统计 tids 中未进回收站的主题数
+func countLiveThreads(ctx context.Context, tx *sqlx.Tx, tids []int64) (int, error) {
+	total := 0
+	for start := 0; start < len(tids); start += mergeCountBatch {
+		end := start + mergeCountBatch
+		if end > len(tids) {
+			end = len(tids)
+		}
+		placeholders := make([]string, 0, end-start)
+		args := make([]interface{}, 0, end-start+1)
+		for _, tid := range tids[start:end] {
+			placeholders = append(placeholders, "?")
+			args = append(args, tid)
+		}
+		args = append(args, model.ThreadTrashed)
+
+		var n int
+		query := fmt.Sprintf("SELECT COUNT(*) FROM thread WHERE tid IN (%s) AND status <> ?", strings.Join(placeholders, ","))
+		if err := tx.GetContext(ctx, &n, query, args...); err != nil {
+			return 0, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// GetTrashed 获取回收站标签（按删除时间倒序）
+func (r *tagRepository) GetTrashed(ctx context.Context, offset, limit int) ([]*model.Tag, error) {
+	var tags []*model.Tag
+	err := r.db.SelectContext(ctx, &tags,
+		"SELECT * FROM tag WHERE deleted_at > 0 ORDER BY deleted_at DESC LIMIT ?, ?", offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// CountTrashed 回收站标签数
+func (r *tagRepository) CountTrashed(ctx context.Context) (int, error) {
+	var count int
+	err := r.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM tag WHERE deleted_at > 0")
+	return count, err
+}
+
+// GetExpiredTrash 获取删除时间早于 before 的回收站标签
+func (r *tagRepository) GetExpiredTrash(ctx context.Context, before int, limit int) ([]int, error) {
+	var tagIDs []int
+	err := r.db.SelectContext(ctx, &tagIDs,
+		"SELECT tag_id FROM tag WHERE deleted_at > 0 AND deleted_at < ? ORDER BY deleted_at ASC LIMIT ?", before, limit)
+	if err != nil {
+		return nil, err
+	}
+	return tagIDs, nil
 }
 
 // IncThreads 增加关联主题数
@@ -176,40 +360,57 @@ func (r *tagRepository) IncThreads(ctx context.Context, tagID int) error {
 
 // DecThreads 减少关联主题数
 func (r *tagRepository) DecThreads(ctx context.Context, tagID int) error {
-	_, err := r.db.ExecContext(ctx, "UPDATE tag SET threads = GREATEST(threads - 1, 0) WHERE tag_id = ?", tagID)
+	_, err := r.db.ExecContext(ctx, "UPDATE tag SET threads = GREATEST(CAST(threads AS SIGNED) - 1, 0) WHERE tag_id = ?", tagID)
 	return err
 }
 
-// IncView 增加浏览数
-func (r *tagRepository) IncView(ctx context.Context, tagID int) error {
-	_, err := r.db.ExecContext(ctx, "UPDATE tag SET view = view + 1 WHERE tag_id = ?", tagID)
-	return err
+// AddViews 批量累加浏览数
+func (r *tagRepository) AddViews(ctx context.Context, views map[int]int64) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for tagID, n := range views {
+		if _, err := tx.ExecContext(ctx, "UPDATE tag SET `view` = `view` + ? WHERE tag_id = ?", n, tagID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// SlugTaken slug 是否已被其它标签占用
+func (r *tagRepository) SlugTaken(ctx context.Context, slug string, excludeID int) (bool, error) {
+	var count int
+	err := r.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM tag WHERE slug = ? AND tag_id <> ?", slug, excludeID)
+	return count > 0, err
+}
+
+// UpdateSlug 更新 slug
+func (r *tagRepository) UpdateSlug(ctx context.Context, tagID int, slug string) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE tag SET slug = ? WHERE tag_id = ?", slug, tagID)
+	return slugConflict(err)
+}
+
+// GetWithoutSlug 获取 slug 为空的标签
+func (r *tagRepository) GetWithoutSlug(ctx context.Context, limit int) ([]*model.Tag, error) {
+	var tags []*model.Tag
+	err := r.db.SelectContext(ctx, &tags, "SELECT * FROM tag WHERE slug = '' ORDER BY tag_id ASC LIMIT ?", limit)
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
 }
 
 // GetSitemapList 获取sitemap列表
 func (r *tagRepository) GetSitemapList(ctx context.Context, offset, limit int) ([]*model.Tag, error) {
 	var tags []*model.Tag
 	err := r.db.SelectContext(ctx, &tags,
-		"SELECT tag_id, name, slug FROM tag WHERE status = 0 ORDER BY threads DESC LIMIT ?, ?",
+		"SELECT tag_id, name, slug FROM tag WHERE status = 0 AND deleted_at = 0 AND slug <> '' ORDER BY threads DESC LIMIT ?, ?",
 		offset, limit)
 	if err != nil {
 		return nil, err
 	}
 	return tags, nil
 }
-
-// generateSlug 生成拼音 slug
-func generateSlug(name string) string {
-	// 简单实现：转小写，空格变横线
-	slug := strings.ToLower(name)
-	slug = strings.ReplaceAll(slug, " ", "-")
-	// 移除特殊字符
-	var result []byte
-	for i := 0; i < len(slug); i++ {
-		c := slug[i]
-		if (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' {
-			result = append(result, c)
-		}
-	}
-	return string(result)
-}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// TagAliasRepository 标签同义词数据访问接口
+type TagAliasRepository interface {
+	// GetTarget 同义词指向的 tag_id，不是同义词时返回 0
+	GetTarget(ctx context.Context, alias string) (int, error)
+	GetByTag(ctx context.Context, tagID int) ([]string, error)
+	Create(ctx context.Context, alias string, tagID int) error
+	Delete(ctx context.Context, alias string, tagID int) (bool, error)
+}
+
+// tagAliasRepository 标签同义词数据访问实现
+type tagAliasRepository struct {
+	db *sqlx.DB
+}
+
+// NewTagAliasRepository 创建 TagAliasRepository 实例
+func NewTagAliasRepository(db *sqlx.DB) TagAliasRepository {
+	return &tagAliasRepository{db: db}
+}
+
+// GetTarget 获取同义词指向的 tag_id
+func (r *tagAliasRepository) GetTarget(ctx context.Context, alias string) (int, error) {
+	var tagID int
+	err := r.db.GetContext(ctx, &tagID, "SELECT tag_id FROM tag_alias WHERE alias = ?", alias)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return tagID, err
+}
+
+// GetByTag 获取标签的全部同义词
+func (r *tagAliasRepository) GetByTag(ctx context.Context, tagID int) ([]string, error) {
+	var aliases []string
+	err := r.db.SelectContext(ctx, &aliases, "SELECT alias FROM tag_alias WHERE tag_id = ? ORDER BY alias ASC", tagID)
+	if err != nil {
+		return nil, err
+	}
+	return aliases, nil
+}
+
+// Create 添加同义词
+func (r *tagAliasRepository) Create(ctx context.Context, alias string, tagID int) error {
+	_, err := r.db.ExecContext(ctx, "INSERT INTO tag_alias (alias, tag_id) VALUES (?, ?)", alias, tagID)
+	return err
+}
+
+// Delete 删除同义词，不存在时返回 false
+func (r *tagAliasRepository) Delete(ctx context.Context, alias string, tagID int) (bool, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM tag_alias WHERE alias = ? AND tag_id = ?", alias, tagID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
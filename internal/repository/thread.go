@@ -12,18 +12,47 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
+// ErrThreadStatusChanged 写入前主题状态已被其它请求变更（发布、移入回收站等）
+var ErrThreadStatusChanged = fmt.Errorf("thread status changed concurrently, reload and retry")
+
 // ThreadRepository Thread数据访问接口
 type ThreadRepository interface {
 	GetByID(ctx context.Context, tid int64) (*model.Thread, error)
 	GetContentByID(ctx context.Context, tid int64) (*model.ThreadData, error)
 	GetByFid(ctx context.Context, fid int, offset, limit int) ([]*model.Thread, error)
 	GetListTIDsByFid(ctx context.Context, fid int, offset, limit int) ([]int64, error)
+	// GetListTIDsByFidAfter 按 (lastpost, tid) 倒序游标分页，lastpost=0 且 tid=0 表示第一页
+	GetListTIDsByFidAfter(ctx context.Context, fid int, lastpost int, tid int64, limit int) ([]int64, error)
+	// GetListTIDs 按条件与排序方式获取已发布主题 tid（跨版块 feed）
+	GetListTIDs(ctx context.Context, filter ThreadListFilter, offset, limit int) ([]int64, error)
+	// CountListTIDs 与 GetListTIDs 条件一致的主题数
+	CountListTIDs(ctx context.Context, filter ThreadListFilter) (int, error)
 	GetByTIDs(ctx context.Context, tids []int64) ([]*model.Thread, error)
 	Create(ctx context.Context, thread *model.Thread, content *model.ThreadData) (int64, error)
-	Update(ctx context.Context, thread *model.Thread) error
-	Delete(ctx context.Context, tid int64) error
-	IncViews(ctx context.Context, tid int64) error
+	// Update/Edit 仅在主题状态仍为 prevStatus 时写入（thread.Status 不同时才写 status），否则返回 ErrThreadStatusChanged
+	Update(ctx context.Context, thread *model.Thread, prevStatus int) error
+	// Edit 更新主题（message 为 nil 时不改内容）并在同一事务内写入修订记录，rev.Rev 由此分配
+	Edit(ctx context.Context, thread *model.Thread, prevStatus int, message *string, rev *model.ThreadRevision) error
+	// Publish/Unpublish 条件更新状态并在同一事务内维护 forum.threads/today，
+	// 返回 false 表示状态已被其它请求/实例变更（幂等）
+	Publish(ctx context.Context, tid int64, now int) (bool, error)
+	Unpublish(ctx context.Context, tid int64, status int) (bool, error)
+	GetDueScheduled(ctx context.Context, now int, limit int) ([]int64, error)
+	// 回收站：Trash/Restore 维护 forum.threads/posts 与 tag.threads，Purge 仅清除已在回收站的主题
+	Trash(ctx context.Context, tid int64, now int) (bool, error)
+	Restore(ctx context.Context, tid int64) (bool, error)
+	Purge(ctx context.Context, tid int64) (bool, error)
+	// Move 批量移动主题并在同一事务内对账两侧版块计数，目标版块不存在时返回 sql.ErrNoRows
+	Move(ctx context.Context, tids []int64, toFid int, stubs map[int64]int64) ([]*model.Thread, error)
+	GetTrashed(ctx context.Context, offset, limit int) ([]*model.Thread, error)
+	CountTrashed(ctx context.Context) (int, error)
+	GetExpiredTrash(ctx context.Context, before int, limit int) ([]int64, error)
+	// AddViews 批量累加浏览量（tid → 增量）
+	AddViews(ctx context.Context, views map[int64]int64) error
 	IncReplies(ctx context.Context, tid int64) error
+	// 搜索索引专用方法
+	GetContentsByTIDs(ctx context.Context, tids []int64) ([]*model.ThreadData, error)
+	GetBatchAfter(ctx context.Context, afterTid int64, limit int) ([]*model.Thread, error)
 	// Sitemap 专用方法
 	GetSitemapList(ctx context.Context, offset, limit int) ([]*model.Thread, error)
 	Count(ctx context.Context) (int, error)
@@ -33,7 +62,7 @@ type ThreadRepository interface {
 func (r *threadRepository) GetListTIDsByFid(ctx context.Context, fid int, offset, limit int) ([]int64, error) {
 	var tids []int64
 	err := r.db.SelectContext(ctx, &tids,
-		"SELECT tid FROM thread WHERE fid = ? ORDER BY lastpost DESC LIMIT ?, ?",
+		"SELECT tid FROM thread WHERE fid = ? AND status = 0 ORDER BY lastpost DESC, tid DESC LIMIT ?, ?",
 		fid, offset, limit)
 	if err != nil {
 		return nil, err
@@ -41,6 +70,104 @@ func (r *threadRepository) GetListTIDsByFid(ctx context.Context, fid int, offset
 	return tids, nil
 }
 
+// GetListTIDsByFidAfter 游标分页获取列表页tid
+// 走 idx_fid_lastpost（二级索引隐含主键 tid）定位，深翻页不扫描跳过的行
+func (r *threadRepository) GetListTIDsByFidAfter(ctx context.Context, fid int, lastpost int, tid int64, limit int) ([]int64, error) {
+	var tids []int64
+	var err error
+	if lastpost == 0 && tid == 0 {
+		err = r.db.SelectContext(ctx, &tids,
+			"SELECT tid FROM thread WHERE fid = ? AND status = 0 ORDER BY lastpost DESC, tid DESC LIMIT ?",
+			fid, limit)
+	} else {
+		err = r.db.SelectContext(ctx, &tids,
+			"SELECT tid FROM thread WHERE fid = ? AND status = 0 AND (lastpost < ? OR (lastpost = ? AND tid < ?)) ORDER BY lastpost DESC, tid DESC LIMIT ?",
+			fid, lastpost, lastpost, tid, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tids, nil
+}
+
+// ThreadListFilter 主题列表条件
+type ThreadListFilter struct {
+	Fids      []int  // 为空表示不限版块
+	Uid       int64  // 0 表示不限作者
+	TagID     int    // 0 表示不限标签
+	Sort      string // model.ThreadSort*，未知值按 lastpost
+	WithStubs bool   // 是否包含跳转占位（单版块列表需要，跨版块 feed 中原主题已出现）
+}
+
+// threadHotWindow hot 排序只计算最近 30 天发布的主题，避免整版块 filesort
+const threadHotWindow = 30 * 24 * 3600
+
+// threadListOrders 排序方式 → ORDER BY，tid 作为稳定的次序
+// hot：(回复×2 + 浏览/10 + 1) / (小时数 + 2)^1.5；dateline 转有符号，晚于数据库时钟时不会溢出
+var threadListOrders = map[string]string{
+	model.ThreadSortLastpost: "lastpost DESC, tid DESC",
+	model.ThreadSortDateline: "dateline DESC, tid DESC",
+	model.ThreadSortViews:    "views DESC, tid DESC",
+	model.ThreadSortReplies:  "replies DESC, tid DESC",
+	model.ThreadSortHot:      "(replies * 2 + views / 10 + 1) / POW((UNIX_TIMESTAMP() - CAST(dateline AS SIGNED)) / 3600 + 2, 1.5) DESC, tid DESC",
+}
+
+// listWhere 列表条件对应的 WHERE 子句与参数
+func (filter ThreadListFilter) listWhere() (string, []interface{}) {
+	where := []string{"status = ?"}
+	args := []interface{}{model.ThreadPublished}
+	if len(filter.Fids) > 0 {
+		placeholders := make([]string, 0, len(filter.Fids))
+		for _, fid := range filter.Fids {
+			placeholders = append(placeholders, "?")
+			args = append(args, fid)
+		}
+		where = append(where, "fid IN ("+strings.Join(placeholders, ",")+")")
+	}
+	if filter.Uid > 0 {
+		where = append(where, "uid = ?")
+		args = append(args, filter.Uid)
+	}
+	if filter.TagID > 0 {
+		where = append(where, "tid IN (SELECT tid FROM thread_tag WHERE tag_id = ?)")
+		args = append(args, filter.TagID)
+	}
+	if !filter.WithStubs {
+		where = append(where, "redirect_tid = 0")
+	}
+	if filter.Sort == model.ThreadSortHot {
+		where = append(where, "dateline >= ?")
+		args = append(args, time.Now().Unix()-threadHotWindow)
+	}
+	return strings.Join(where, " AND "), args
+}
+
+// GetListTIDs 按条件获取列表页tid
+func (r *threadRepository) GetListTIDs(ctx context.Context, filter ThreadListFilter, offset, limit int) ([]int64, error) {
+	where, args := filter.listWhere()
+	order, ok := threadListOrders[filter.Sort]
+	if !ok {
+		order = threadListOrders[model.ThreadSortLastpost]
+	}
+
+	var tids []int64
+	err := r.db.SelectContext(ctx, &tids,
+		fmt.Sprintf("SELECT tid FROM thread WHERE %s ORDER BY %s LIMIT ?, ?", where, order),
+		append(args, offset, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	return tids, nil
+}
+
+// CountListTIDs 按条件统计主题数
+func (r *threadRepository) CountListTIDs(ctx context.Context, filter ThreadListFilter) (int, error) {
+	where, args := filter.listWhere()
+	var count int
+	err := r.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM thread WHERE "+where, args...)
+	return count, err
+}
+
 // GetByTIDs 批量按tid获取主题（保持输入顺序）
 func (r *threadRepository) GetByTIDs(ctx context.Context, tids []int64) ([]*model.Thread, error) {
 	if len(tids) == 0 {
@@ -61,7 +188,7 @@ func (r *threadRepository) GetByTIDs(ctx context.Context, tids []int64) ([]*mode
 	}
 
 	query := fmt.Sprintf(
-		"SELECT tid, fid, uid, subject, views, replies, dateline, lastpost, status FROM thread WHERE tid IN (%s) ORDER BY FIELD(tid, %s)",
+		"SELECT tid, fid, uid, subject, views, replies, dateline, lastpost, status, publish_at, redirect_tid FROM thread WHERE tid IN (%s) ORDER BY FIELD(tid, %s)",
 		strings.Join(placeholders, ","),
 		strings.Join(fieldParts, ","),
 	)
@@ -87,7 +214,7 @@ func NewThreadRepository(db *sqlx.DB) ThreadRepository {
 // GetByID 根据ID获取Thread
 func (r *threadRepository) GetByID(ctx context.Context, tid int64) (*model.Thread, error) {
 	var thread model.Thread
-	err := r.db.GetContext(ctx, &thread, "SELECT tid, fid, uid, subject, views, replies, dateline, lastpost, status FROM thread WHERE tid = ?", tid)
+	err := r.db.GetContext(ctx, &thread, "SELECT tid, fid, uid, subject, views, replies, dateline, lastpost, status, publish_at, deleted_at, prev_status, redirect_tid FROM thread WHERE tid = ?", tid)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -114,7 +241,7 @@ func (r *threadRepository) GetContentByID(ctx context.Context, tid int64) (*mode
 func (r *threadRepository) GetByFid(ctx context.Context, fid int, offset, limit int) ([]*model.Thread, error) {
 	var threads []*model.Thread
 	err := r.db.SelectContext(ctx, &threads,
-		"SELECT tid, fid, uid, subject, views, replies, dateline, lastpost, status FROM thread WHERE fid = ? ORDER BY lastpost DESC LIMIT ?, ?",
+		"SELECT tid, fid, uid, subject, views, replies, dateline, lastpost, status, publish_at, redirect_tid FROM thread WHERE fid = ? AND status = 0 ORDER BY lastpost DESC LIMIT ?, ?",
 		fid, offset, limit)
 	if err != nil {
 		return nil, err
@@ -132,12 +259,20 @@ func (r *threadRepository) Create(ctx context.Context, thread *model.Thread, con
 
 	// 插入主表
 	result, err := tx.ExecContext(ctx,
-		"INSERT INTO thread (tid, fid, uid, subject, views, replies, dateline, lastpost, status) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
-		thread.Tid, thread.Fid, thread.Uid, thread.Subject, thread.Views, thread.Replies, thread.Dateline, thread.Lastpost, thread.Status)
+		"INSERT INTO thread (tid, fid, uid, subject, views, replies, dateline, lastpost, status, publish_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		thread.Tid, thread.Fid, thread.Uid, thread.Subject, thread.Views, thread.Replies, thread.Dateline, thread.Lastpost, thread.Status, thread.PublishAt)
 	if err != nil {
 		return 0, err
 	}
 
+	// 直接发布时同步版块计数
+	if thread.Status == model.ThreadPublished {
+		_, err = tx.ExecContext(ctx, "UPDATE forum SET threads = threads + 1, today = today + 1 WHERE fid = ?", thread.Fid)
+		if err != nil {
+			return 0, err
+		}
+	}
+
 	// 插入内容表
 	_, err = tx.ExecContext(ctx,
 		"INSERT INTO thread_data (tid, message) VALUES (?, ?)",
@@ -146,6 +281,14 @@ func (r *threadRepository) Create(ctx context.Context, thread *model.Thread, con
 		return 0, err
 	}
 
+	// 初始修订版本
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO thread_revision (tid, rev, uid, subject, message, action, from_rev, dateline) VALUES (?, 1, ?, ?, ?, ?, 0, ?)",
+		thread.Tid, thread.Uid, thread.Subject, content.Message, model.RevisionCreate, thread.Dateline)
+	if err != nil {
+		return 0, err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return 0, err
 	}
@@ -155,38 +298,485 @@ func (r *threadRepository) Create(ctx context.Context, thread *model.Thread, con
 }
 
 // Update 更新Thread
-func (r *threadRepository) Update(ctx context.Context, thread *model.Thread) error {
-	_, err := r.db.ExecContext(ctx,
-		"UPDATE thread SET subject = ?, status = ?, lastpost = ? WHERE tid = ?",
-		thread.Subject, thread.Status, thread.Lastpost, thread.Tid)
+func (r *threadRepository) Update(ctx context.Context, thread *model.Thread, prevStatus int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockThreadStatus(ctx, tx, thread.Tid, prevStatus); err != nil {
+		return err
+	}
+	if err := updateThreadTx(ctx, tx, thread, prevStatus); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// lockThreadStatus 锁主题行并确认状态仍为 prevStatus，串行化同一主题的编辑与上下线
+func lockThreadStatus(ctx context.Context, tx *sqlx.Tx, tid int64, prevStatus int) error {
+	var status int
+	if err := tx.GetContext(ctx, &status, "SELECT status FROM thread WHERE tid = ? FOR UPDATE", tid); err != nil {
+		return err
+	}
+	if status != prevStatus {
+		return ErrThreadStatusChanged
+	}
+	return nil
+}
+
+// updateThreadTx 写入标题等字段，状态未变化时不写 status
+func updateThreadTx(ctx context.Context, tx *sqlx.Tx, thread *model.Thread, prevStatus int) error {
+	if thread.Status == prevStatus {
+		_, err := tx.ExecContext(ctx,
+			"UPDATE thread SET subject = ?, lastpost = ?, publish_at = ? WHERE tid = ?",
+			thread.Subject, thread.Lastpost, thread.PublishAt, thread.Tid)
+		return err
+	}
+	_, err := tx.ExecContext(ctx,
+		"UPDATE thread SET subject = ?, status = ?, lastpost = ?, publish_at = ? WHERE tid = ? AND status = ?",
+		thread.Subject, thread.Status, thread.Lastpost, thread.PublishAt, thread.Tid, prevStatus)
 	return err
 }
 
-// Delete 删除Thread
-func (r *threadRepository) Delete(ctx context.Context, tid int64) error {
+// Edit 更新主题并记录修订
+// 历史主题（上线修订功能前创建，无修订记录）先以当前内容补一条初始版本，保证可回滚到编辑前
+func (r *threadRepository) Edit(ctx context.Context, thread *model.Thread, prevStatus int, message *string, rev *model.ThreadRevision) error {
 	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	_, err = tx.ExecContext(ctx, "DELETE FROM thread_data WHERE tid = ?", tid)
-	if err != nil {
+	// 锁主题行，串行化同一主题的并发编辑以分配版本号
+	if err := lockThreadStatus(ctx, tx, thread.Tid, prevStatus); err != nil {
+		return err
+	}
+
+	var last int
+	if err := tx.GetContext(ctx, &last, "SELECT COALESCE(MAX(rev), 0) FROM thread_revision WHERE tid = ?", thread.Tid); err != nil {
 		return err
 	}
+	if last == 0 {
+		var orig model.Thread
+		if err := tx.GetContext(ctx, &orig, "SELECT tid, uid, subject, dateline FROM thread WHERE tid = ?", thread.Tid); err != nil {
+			return err
+		}
+		var message string
+		err := tx.GetContext(ctx, &message, "SELECT COALESCE(message, '') FROM thread_data WHERE tid = ?", thread.Tid)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO thread_revision (tid, rev, uid, subject, message, action, from_rev, dateline) VALUES (?, 1, ?, ?, ?, ?, 0, ?)",
+			orig.Tid, orig.Uid, orig.Subject, message, model.RevisionCreate, orig.Dateline)
+		if err != nil {
+			return err
+		}
+		last = 1
+	}
 
-	_, err = tx.ExecContext(ctx, "DELETE FROM thread WHERE tid = ?", tid)
+	if err := updateThreadTx(ctx, tx, thread, prevStatus); err != nil {
+		return err
+	}
+	if message != nil {
+		_, err = tx.ExecContext(ctx, "UPDATE thread_data SET message = ? WHERE tid = ?", *message, thread.Tid)
+		if err != nil {
+			return err
+		}
+	}
+
+	rev.Tid = thread.Tid
+	rev.Rev = last + 1
+	result, err := tx.ExecContext(ctx,
+		"INSERT INTO thread_revision (tid, rev, uid, subject, message, action, from_rev, dateline) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		rev.Tid, rev.Rev, rev.Uid, rev.Subject, rev.Message, rev.Action, rev.FromRev, rev.Dateline)
 	if err != nil {
 		return err
 	}
+	rev.ID, _ = result.LastInsertId()
 
 	return tx.Commit()
 }
 
-// IncViews 增加浏览量
-func (r *threadRepository) IncViews(ctx context.Context, tid int64) error {
-	_, err := r.db.ExecContext(ctx, "UPDATE thread SET views = views + 1 WHERE tid = ?", tid)
-	return err
+// Publish 将未发布主题置为已发布并增加版块计数；仅定时发布的主题发布时间重置为 now，
+// 草稿/待审核/隐藏的主题保留原发布时间
+// （MySQL 按书写顺序赋值，dateline/lastpost 须在 status 之前以读取原状态）
+func (r *threadRepository) Publish(ctx context.Context, tid int64, now int) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
+		`UPDATE thread SET dateline = IF(status = ?, ?, dateline), lastpost = IF(status = ?, GREATEST(lastpost, ?), lastpost),
+		status = ?, publish_at = 0 WHERE tid = ? AND status NOT IN (?, ?)`,
+		model.ThreadScheduled, now, model.ThreadScheduled, now,
+		model.ThreadPublished, tid, model.ThreadPublished, model.ThreadTrashed)
+	if err != nil {
+		return false, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return false, nil
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE forum SET threads = threads + 1, today = today + 1 WHERE fid = (SELECT fid FROM thread WHERE tid = ?)", tid)
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// Unpublish 将已发布主题置为指定状态，并减少版块计数
+func (r *threadRepository) Unpublish(ctx context.Context, tid int64, status int) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
+		"UPDATE thread SET status = ? WHERE tid = ? AND status = ?",
+		status, tid, model.ThreadPublished)
+	if err != nil {
+		return false, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return false, nil
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE forum SET threads = GREATEST(CAST(threads AS SIGNED) - 1, 0) WHERE fid = (SELECT fid FROM thread WHERE tid = ?)", tid)
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// GetDueScheduled 获取已到发布时间的定时主题
+func (r *threadRepository) GetDueScheduled(ctx context.Context, now int, limit int) ([]int64, error) {
+	var tids []int64
+	err := r.db.SelectContext(ctx, &tids,
+		"SELECT tid FROM thread WHERE status = ? AND publish_at <= ? ORDER BY publish_at ASC LIMIT ?",
+		model.ThreadScheduled, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	return tids, nil
+}
+
+// Trash 将主题移入回收站：记录原状态，已发布主题减少版块主题数，回帖移出版块回帖数，关联标签减少主题数
+// 跳转占位不计入版块主题数，不做扣减
+func (r *threadRepository) Trash(ctx context.Context, tid int64, now int) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var thread model.Thread
+	err = tx.GetContext(ctx, &thread, "SELECT tid, fid, status, redirect_tid FROM thread WHERE tid = ? FOR UPDATE", tid)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if thread.Status == model.ThreadTrashed {
+		return false, nil
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE thread SET prev_status = status, status = ?, deleted_at = ? WHERE tid = ?",
+		model.ThreadTrashed, now, tid)
+	if err != nil {
+		return false, err
+	}
+
+	if thread.Status == model.ThreadPublished && thread.RedirectTid == 0 {
+		_, err = tx.ExecContext(ctx, "UPDATE forum SET threads = GREATEST(CAST(threads AS SIGNED) - 1, 0) WHERE fid = ?", thread.Fid)
+		if err != nil {
+			return false, err
+		}
+	}
+	_, err = tx.ExecContext(ctx,
+		"UPDATE forum SET posts = GREATEST(CAST(posts AS SIGNED) - (SELECT COUNT(*) FROM post WHERE tid = ?), 0) WHERE fid = ?",
+		tid, thread.Fid)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE tag SET threads = GREATEST(CAST(threads AS SIGNED) - 1, 0) WHERE tag_id IN (SELECT tag_id FROM thread_tag WHERE tid = ?)", tid)
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// Restore 从回收站恢复主题到原状态，并恢复版块/标签计数
+func (r *threadRepository) Restore(ctx context.Context, tid int64) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var thread model.Thread
+	err = tx.GetContext(ctx, &thread, "SELECT tid, fid, status, prev_status, redirect_tid FROM thread WHERE tid = ? FOR UPDATE", tid)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if thread.Status != model.ThreadTrashed {
+		return false, nil
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE thread SET status = prev_status, prev_status = 0, deleted_at = 0 WHERE tid = ?", tid)
+	if err != nil {
+		return false, err
+	}
+
+	if thread.PrevStatus == model.ThreadPublished && thread.RedirectTid == 0 {
+		_, err = tx.ExecContext(ctx, "UPDATE forum SET threads = threads + 1 WHERE fid = ?", thread.Fid)
+		if err != nil {
+			return false, err
+		}
+	}
+	_, err = tx.ExecContext(ctx,
+		"UPDATE forum SET posts = posts + (SELECT COUNT(*) FROM post WHERE tid = ?) WHERE fid = ?", tid, thread.Fid)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE tag SET threads = threads + 1 WHERE tag_id IN (SELECT tag_id FROM thread_tag WHERE tid = ?)", tid)
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// Purge 彻底删除回收站中的主题及其内容、修订、回帖与标签关联
+// 版块主题数、回帖数与标签主题数均已在 Trash 时扣减，这里不再修改计数
+func (r *threadRepository) Purge(ctx context.Context, tid int64) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var thread model.Thread
+	err = tx.GetContext(ctx, &thread, "SELECT tid, fid, status FROM thread WHERE tid = ? FOR UPDATE", tid)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if thread.Status != model.ThreadTrashed {
+		return false, nil
+	}
+
+	for _, query := range []string{
+		"DELETE FROM post WHERE tid = ?",
+		"DELETE FROM thread_tag WHERE tid = ?",
+		"DELETE FROM thread_revision WHERE tid = ?",
+		"DELETE FROM thread_data WHERE tid = ?",
+		"DELETE FROM thread WHERE tid = ?",
+		"DELETE FROM thread WHERE redirect_tid = ?", // 指向该主题的跳转占位
+	} {
+		if _, err := tx.ExecContext(ctx, query, tid); err != nil {
+			return false, err
+		}
+	}
+
+	return true, tx.Commit()
+}
+
+// Move 批量移动主题到 toFid，同一事务内对账两侧版块计数：
+//   - forum.threads 只计已发布主题，forum.posts 按实际回帖行数（回收站主题除外）
+//   - stubs[tid] 非 0 时在原版块插入指向该主题的跳转占位（仅已发布主题；占位不计入版块主题数）
+//   - 目标版块中指向被移动主题的旧占位会被删除（移回原版块时不重复显示）
+//
+// 已在目标版块的主题与跳转占位本身会被跳过，返回实际移动的主题（Fid 为原版块）。
+// 目标版块不存在或在回收站时返回 sql.ErrNoRows。
+func (r *threadRepository) Move(ctx context.Context, tids []int64, toFid int, stubs map[int64]int64) ([]*model.Thread, error) {
+	if len(tids) == 0 {
+		return []*model.Thread{}, nil
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var target int
+	if err := tx.GetContext(ctx, &target, "SELECT fid FROM forum WHERE fid = ? AND deleted_at = 0 FOR UPDATE", toFid); err != nil {
+		return nil, err
+	}
+
+	placeholders, args := int64Placeholders(tids)
+	var threads []*model.Thread
+	err = tx.SelectContext(ctx, &threads,
+		fmt.Sprintf("SELECT tid, fid, uid, subject, dateline, lastpost, status, redirect_tid FROM thread WHERE tid IN (%s) FOR UPDATE", placeholders),
+		args...)
+	if err != nil {
+		return nil, err
+	}
+
+	moved := make([]*model.Thread, 0, len(threads))
+	movedTids := make([]int64, 0, len(threads))
+	for _, t := range threads {
+		if t.Fid == toFid || t.RedirectTid > 0 {
+			continue
+		}
+		moved = append(moved, t)
+		movedTids = append(movedTids, t.Tid)
+	}
+	if len(moved) == 0 {
+		return moved, tx.Commit()
+	}
+
+	placeholders, args = int64Placeholders(movedTids)
+	var postCounts []struct {
+		Tid int64 `db:"tid"`
+		N   int   `db:"n"`
+	}
+	err = tx.SelectContext(ctx, &postCounts,
+		fmt.Sprintf("SELECT tid, COUNT(*) AS n FROM post WHERE tid IN (%s) GROUP BY tid", placeholders), args...)
+	if err != nil {
+		return nil, err
+	}
+	posts := make(map[int64]int, len(postCounts))
+	for _, pc := range postCounts {
+		posts[pc.Tid] = pc.N
+	}
+
+	type counter struct{ threads, posts int }
+	from := make(map[int]*counter)
+	to := &counter{}
+	for _, t := range moved {
+		c := from[t.Fid]
+		if c == nil {
+			c = &counter{}
+			from[t.Fid] = c
+		}
+		if t.Status == model.ThreadPublished {
+			c.threads++
+			to.threads++
+		}
+		if t.Status != model.ThreadTrashed { // 回收站主题的回帖已移出版块回帖数
+			c.posts += posts[t.Tid]
+			to.posts += posts[t.Tid]
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, fmt.Sprintf("UPDATE thread SET fid = ? WHERE tid IN (%s)", placeholders), append([]interface{}{toFid}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM thread WHERE fid = ? AND redirect_tid IN (%s)", placeholders), append([]interface{}{toFid}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	for fid, c := range from {
+		_, err = tx.ExecContext(ctx,
+			"UPDATE forum SET threads = GREATEST(CAST(threads AS SIGNED) - ?, 0), posts = GREATEST(CAST(posts AS SIGNED) - ?, 0) WHERE fid = ?",
+			c.threads, c.posts, fid)
+		if err != nil {
+			return nil, err
+		}
+	}
+	_, err = tx.ExecContext(ctx, "UPDATE forum SET threads = threads + ?, posts = posts + ? WHERE fid = ?", to.threads, to.posts, toFid)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range moved {
+		stubTid := stubs[t.Tid]
+		if stubTid == 0 || t.Status != model.ThreadPublished {
+			continue
+		}
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO thread (tid, fid, uid, subject, views, replies, dateline, lastpost, status, publish_at, redirect_tid) VALUES (?, ?, ?, ?, 0, 0, ?, ?, ?, 0, ?)",
+			stubTid, t.Fid, t.Uid, t.Subject, t.Dateline, t.Lastpost, model.ThreadPublished, t.Tid)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return moved, tx.Commit()
+}
+
+// int64Placeholders 生成 IN 查询占位符与参数
+func int64Placeholders(ids []int64) (string, []interface{}) {
+	placeholders := make([]string, 0, len(ids))
+	args := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		placeholders = append(placeholders, "?")
+		args = append(args, id)
+	}
+	return strings.Join(placeholders, ","), args
+}
+
+// GetTrashed 获取回收站主题（按删除时间倒序）
+func (r *threadRepository) GetTrashed(ctx context.Context, offset, limit int) ([]*model.Thread, error) {
+	var threads []*model.Thread
+	err := r.db.SelectContext(ctx, &threads,
+		"SELECT tid, fid, uid, subject, views, replies, dateline, lastpost, status, publish_at, deleted_at, prev_status FROM thread WHERE status = ? ORDER BY deleted_at DESC LIMIT ?, ?",
+		model.ThreadTrashed, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return threads, nil
+}
+
+// CountTrashed 回收站主题数
+func (r *threadRepository) CountTrashed(ctx context.Context) (int, error) {
+	var count int
+	err := r.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM thread WHERE status = ?", model.ThreadTrashed)
+	return count, err
+}
+
+// GetExpiredTrash 获取删除时间早于 before 的回收站主题
+func (r *threadRepository) GetExpiredTrash(ctx context.Context, before int, limit int) ([]int64, error) {
+	var tids []int64
+	err := r.db.SelectContext(ctx, &tids,
+		"SELECT tid FROM thread WHERE status = ? AND deleted_at < ? ORDER BY deleted_at ASC LIMIT ?",
+		model.ThreadTrashed, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	return tids, nil
+}
+
+// AddViews 批量累加浏览量（同一事务）
+func (r *threadRepository) AddViews(ctx context.Context, views map[int64]int64) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for tid, n := range views {
+		if _, err := tx.ExecContext(ctx, "UPDATE thread SET views = views + ? WHERE tid = ?", n, tid); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
 }
 
 // IncReplies 增加回复数
@@ -195,11 +785,11 @@ func (r *threadRepository) IncReplies(ctx context.Context, tid int64) error {
 	return err
 }
 
-// GetSitemapList 获取sitemap列表（只取tid和lastpost）
+// GetSitemapList 获取sitemap列表（只取已发布主题的tid和lastpost）
 func (r *threadRepository) GetSitemapList(ctx context.Context, offset, limit int) ([]*model.Thread, error) {
 	var threads []*model.Thread
 	err := r.db.SelectContext(ctx, &threads,
-		"SELECT tid, lastpost FROM thread ORDER BY tid ASC LIMIT ?, ?",
+		"SELECT tid, lastpost FROM thread WHERE status = 0 AND redirect_tid = 0 ORDER BY tid ASC LIMIT ?, ?",
 		offset, limit)
 	if err != nil {
 		return nil, err
@@ -207,12 +797,46 @@ func (r *threadRepository) GetSitemapList(ctx context.Context, offset, limit int
 	return threads, nil
 }
 
-// Count 获取线程总数
+// Count 获取已发布主题总数
 func (r *threadRepository) Count(ctx context.Context) (int, error) {
 	var count int
-	err := r.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM thread")
+	err := r.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM thread WHERE status = 0 AND redirect_tid = 0")
 	if err != nil {
 		return 0, err
 	}
 	return count, nil
 }
+
+// GetContentsByTIDs 批量获取主题内容
+func (r *threadRepository) GetContentsByTIDs(ctx context.Context, tids []int64) ([]*model.ThreadData, error) {
+	if len(tids) == 0 {
+		return []*model.ThreadData{}, nil
+	}
+
+	placeholders := make([]string, 0, len(tids))
+	args := make([]interface{}, 0, len(tids))
+	for _, tid := range tids {
+		placeholders = append(placeholders, "?")
+		args = append(args, tid)
+	}
+
+	query := fmt.Sprintf("SELECT tid, message FROM thread_data WHERE tid IN (%s)", strings.Join(placeholders, ","))
+
+	var list []*model.ThreadData
+	if err := r.db.SelectContext(ctx, &list, query, args...); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// GetBatchAfter 按 tid 升序游标分批读取主题（索引重建用）
+func (r *threadRepository) GetBatchAfter(ctx context.Context, afterTid int64, limit int) ([]*model.Thread, error) {
+	var threads []*model.Thread
+	err := r.db.SelectContext(ctx, &threads,
+		"SELECT tid, fid, uid, subject, views, replies, dateline, lastpost, status, publish_at, redirect_tid FROM thread WHERE tid > ? ORDER BY tid ASC LIMIT ?",
+		afterTid, limit)
+	if err != nil {
+		return nil, err
+	}
+	return threads, nil
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"well_go/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ThreadRevisionRepository 主题修订记录数据访问接口（写入随 ThreadRepository.Create/Edit 在事务内完成）
+type ThreadRevisionRepository interface {
+	// GetByTid 按版本号倒序列出修订（不含 message，避免大字段回表）
+	GetByTid(ctx context.Context, tid int64, offset, limit int) ([]*model.ThreadRevision, error)
+	Get(ctx context.Context, tid int64, rev int) (*model.ThreadRevision, error)
+	Count(ctx context.Context, tid int64) (int, error)
+}
+
+// threadRevisionRepository 主题修订记录数据访问实现
+type threadRevisionRepository struct {
+	db *sqlx.DB
+}
+
+// NewThreadRevisionRepository 创建 ThreadRevisionRepository 实例
+func NewThreadRevisionRepository(db *sqlx.DB) ThreadRevisionRepository {
+	return &threadRevisionRepository{db: db}
+}
+
+// GetByTid 按版本号倒序列出修订
+func (r *threadRevisionRepository) GetByTid(ctx context.Context, tid int64, offset, limit int) ([]*model.ThreadRevision, error) {
+	var revs []*model.ThreadRevision
+	err := r.db.SelectContext(ctx, &revs,
+		"SELECT id, tid, rev, uid, subject, action, from_rev, dateline FROM thread_revision WHERE tid = ? ORDER BY rev DESC LIMIT ?, ?",
+		tid, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return revs, nil
+}
+
+// Get 获取指定版本（含 message），不存在时返回 nil, nil
+func (r *threadRevisionRepository) Get(ctx context.Context, tid int64, rev int) (*model.ThreadRevision, error) {
+	var revision model.ThreadRevision
+	err := r.db.GetContext(ctx, &revision,
+		"SELECT id, tid, rev, uid, subject, COALESCE(message, '') AS message, action, from_rev, dateline FROM thread_revision WHERE tid = ? AND rev = ?",
+		tid, rev)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// Count 主题修订总数
+func (r *threadRevisionRepository) Count(ctx context.Context, tid int64) (int, error) {
+	var count int
+	err := r.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM thread_revision WHERE tid = ?", tid)
+	return count, err
+}
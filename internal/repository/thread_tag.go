@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"strings"
 
 	"well_go/internal/model"
 
@@ -16,6 +17,13 @@ type ThreadTagRepository interface {
 	Delete(ctx context.Context, tid int64, tagID int) error
 	DeleteByThread(ctx context.Context, tid int64) error
 	DeleteByTag(ctx context.Context, tagID int) error
+	// Replace 将主题标签替换为 tagIDs 与新建的 create（同一事务内创建、恢复回收站中的标签），
+	// 并同步各标签关联数；返回实际新增与移除的 tagID
+	Replace(ctx context.Context, tid int64, tagIDs []int, create []*model.Tag) (added, removed []int, err error)
+	// GetByTags 每个标签最近关联的至多 perTag 条记录（按关联 id 倒序，走 idx_tag_id）
+	GetByTags(ctx context.Context, tagIDs []int, perTag int) ([]*model.ThreadTag, error)
+	// GetByThreads 批量获取主题的标签关联
+	GetByThreads(ctx context.Context, tids []int64) ([]*model.ThreadTag, error)
 }
 
 // threadTagRepository ThreadTag 数据访问实现
@@ -73,3 +81,133 @@ func (r *threadTagRepository) DeleteByTag(ctx context.Context, tagID int) error
 	_, err := r.db.ExecContext(ctx, "DELETE FROM thread_tag WHERE tag_id = ?", tagID)
 	return err
 }
+
+// Replace 在一个事务内替换主题标签集合（锁主题行，串行化同一主题的并发编辑）
+// 任一步失败整体回滚：不会留下未关联的新标签，也不会改动原有关联
+func (r *threadTagRepository) Replace(ctx context.Context, tid int64, tagIDs []int, create []*model.Tag) ([]int, []int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	var locked int64
+	if err := tx.GetContext(ctx, &locked, "SELECT tid FROM thread WHERE tid = ? FOR UPDATE", tid); err != nil {
+		return nil, nil, err
+	}
+
+	for _, tag := range create {
+		result, err := tx.ExecContext(ctx,
+			"INSERT INTO tag (name, slug, threads, view, status) VALUES (?, ?, 0, 0, ?)",
+			tag.Name, tag.Slug, tag.Status)
+		if err != nil {
+			return nil, nil, slugConflict(err)
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return nil, nil, err
+		}
+		tag.TagID = int(id)
+		tagIDs = append(tagIDs, tag.TagID)
+	}
+
+	// 使用回收站中的标签时将其恢复
+	if len(tagIDs) > 0 {
+		query, args, err := sqlx.In("UPDATE tag SET deleted_at = 0 WHERE tag_id IN (?) AND deleted_at > 0", tagIDs)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, err := tx.ExecContext(ctx, tx.Rebind(query), args...); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var current []int
+	if err := tx.SelectContext(ctx, &current, "SELECT tag_id FROM thread_tag WHERE tid = ?", tid); err != nil {
+		return nil, nil, err
+	}
+
+	want := make(map[int]bool, len(tagIDs))
+	for _, id := range tagIDs {
+		want[id] = true
+	}
+	have := make(map[int]bool, len(current))
+	var removed []int
+	for _, id := range current {
+		have[id] = true
+		if !want[id] {
+			removed = append(removed, id)
+		}
+	}
+	var added []int
+	for _, id := range tagIDs {
+		if !have[id] {
+			added = append(added, id)
+			have[id] = true
+		}
+	}
+
+	for _, id := range added {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO thread_tag (tid, tag_id) VALUES (?, ?)", tid, id); err != nil {
+			return nil, nil, err
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE tag SET threads = threads + 1 WHERE tag_id = ?", id); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, id := range removed {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM thread_tag WHERE tid = ? AND tag_id = ?", tid, id); err != nil {
+			return nil, nil, err
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE tag SET threads = GREATEST(CAST(threads AS SIGNED) - 1, 0) WHERE tag_id = ?", id); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+	return added, removed, nil
+}
+
+// GetByTags 每个标签一条有 LIMIT 的单表查询，UNION ALL 合并，热门标签不会扫描全部关联
+func (r *threadTagRepository) GetByTags(ctx context.Context, tagIDs []int, perTag int) ([]*model.ThreadTag, error) {
+	if len(tagIDs) == 0 {
+		return []*model.ThreadTag{}, nil
+	}
+
+	parts := make([]string, 0, len(tagIDs))
+	args := make([]interface{}, 0, len(tagIDs)*2)
+	for _, id := range tagIDs {
+		parts = append(parts, "(SELECT tid, tag_id FROM thread_tag WHERE tag_id = ? ORDER BY id DESC LIMIT ?)")
+		args = append(args, id, perTag)
+	}
+
+	var list []*model.ThreadTag
+	if err := r.db.SelectContext(ctx, &list, strings.Join(parts, " UNION ALL "), args...); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// GetByThreads 批量获取主题的标签关联（走 idx_tid）
+func (r *threadTagRepository) GetByThreads(ctx context.Context, tids []int64) ([]*model.ThreadTag, error) {
+	if len(tids) == 0 {
+		return []*model.ThreadTag{}, nil
+	}
+
+	placeholders := make([]string, 0, len(tids))
+	args := make([]interface{}, 0, len(tids))
+	for _, tid := range tids {
+		placeholders = append(placeholders, "?")
+		args = append(args, tid)
+	}
+
+	var list []*model.ThreadTag
+	err := r.db.SelectContext(ctx, &list,
+		"SELECT tid, tag_id FROM thread_tag WHERE tid IN ("+strings.Join(placeholders, ",")+")", args...)
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
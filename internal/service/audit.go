@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"well_go/internal/core/logger"
+	"well_go/internal/model"
+	"well_go/internal/repository"
+)
+
+// AuditService 审计日志
+type AuditService struct {
+	repo repository.AuditLogRepository
+}
+
+// NewAuditService 创建 AuditService 实例
+func NewAuditService(repo repository.AuditLogRepository) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// Record 记录审计事件；写库失败只记日志，不影响业务流程
+func (s *AuditService) Record(ctx context.Context, action string, uid int64, target, ip, detail string) {
+	entry := &model.AuditLog{
+		Action:   action,
+		Uid:      uid,
+		Target:   target,
+		IP:       ip,
+		Detail:   detail,
+		Dateline: int(time.Now().Unix()),
+	}
+	logger.Info("audit",
+		logger.String("action", action),
+		logger.Int64("uid", uid),
+		logger.String("target", target),
+		logger.String("ip", ip),
+		logger.String("detail", detail))
+	if err := s.repo.Create(ctx, entry); err != nil {
+		logger.Error("audit: write failed", logger.String("action", action), logger.String("error", err.Error()))
+	}
+}
+
+// List 分页获取审计日志（按时间倒序）
+func (s *AuditService) List(ctx context.Context, action string, page, pageSize int) ([]*model.AuditLog, int, error) {
+	total, err := s.repo.Count(ctx, action)
+	if err != nil {
+		return nil, 0, err
+	}
+	logs, err := s.repo.List(ctx, action, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
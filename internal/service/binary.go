@@ -1,122 +1,131 @@
 package service
 
 import (
-	"encoding/binary"
 	"time"
+
+	"well_go/internal/pkg/codec"
+)
+
+// L2 二进制编码的 DTO 类型标识（codec kind）
+const (
+	kindThreadDTO byte = 1
+	kindTagDTO    byte = 2
+	kindForumDTO  byte = 3
 )
 
 // MarshalBinary 序列化ThreadDTO
 func (dto *ThreadDTO) MarshalBinary() ([]byte, error) {
-	buf := make([]byte, 0)
-
-	// tid (8 bytes)
-	tidBuf := make([]byte, 8)
-	binary.BigEndian.PutUint64(tidBuf, uint64(dto.Tid))
-	buf = append(buf, tidBuf...)
-
-	// fid (4 bytes)
-	fidBuf := make([]byte, 4)
-	binary.BigEndian.PutUint32(fidBuf, uint32(dto.Fid))
-	buf = append(buf, fidBuf...)
-
-	// uid (8 bytes)
-	uidBuf := make([]byte, 8)
-	binary.BigEndian.PutUint64(uidBuf, uint64(dto.Uid))
-	buf = append(buf, uidBuf...)
-
-	// subject length (2 bytes) + subject
-	subjLen := len(dto.Subject)
-	subjLenBuf := make([]byte, 2)
-	binary.BigEndian.PutUint16(subjLenBuf, uint16(subjLen))
-	buf = append(buf, subjLenBuf...)
-	buf = append(buf, []byte(dto.Subject)...)
-
-	// views (4 bytes)
-	viewsBuf := make([]byte, 4)
-	binary.BigEndian.PutUint32(viewsBuf, uint32(dto.Views))
-	buf = append(buf, viewsBuf...)
-
-	// replies (4 bytes)
-	repliesBuf := make([]byte, 4)
-	binary.BigEndian.PutUint32(repliesBuf, uint32(dto.Replies))
-	buf = append(buf, repliesBuf...)
-
-	// dateline (4 bytes)
-	dateBuf := make([]byte, 4)
-	binary.BigEndian.PutUint32(dateBuf, uint32(dto.Dateline))
-	buf = append(buf, dateBuf...)
-
-	// lastpost (4 bytes)
-	lastBuf := make([]byte, 4)
-	binary.BigEndian.PutUint32(lastBuf, uint32(dto.Lastpost))
-	buf = append(buf, lastBuf...)
-
-	// status (1 byte)
-	buf = append(buf, byte(dto.Status))
-
-	// message length (2 bytes) + message
-	msgLen := len(dto.Message)
-	msgLenBuf := make([]byte, 2)
-	binary.BigEndian.PutUint16(msgLenBuf, uint16(msgLen))
-	buf = append(buf, msgLenBuf...)
-	buf = append(buf, []byte(dto.Message)...)
-
-	return buf, nil
+	w := codec.NewWriter(kindThreadDTO, 48+len(dto.Subject)+len(dto.Message))
+	w.Int(dto.Tid)
+	w.Int(int64(dto.Fid))
+	w.Int(dto.Uid)
+	w.String(dto.Subject)
+	w.Int(int64(dto.Views))
+	w.Int(int64(dto.Replies))
+	w.Int(int64(dto.Dateline))
+	w.Int(int64(dto.Lastpost))
+	w.Int(int64(dto.Status))
+	w.String(dto.Message)
+	w.Int(int64(dto.PublishAt))
+	w.Int(dto.RedirectTid)
+	return w.Bytes(), nil
 }
 
 // UnmarshalBinary 反序列化ThreadDTO
 func (dto *ThreadDTO) UnmarshalBinary(data []byte) error {
-	offset := 0
-
-	// tid
-	dto.Tid = int64(binary.BigEndian.Uint64(data[offset : offset+8]))
-	offset += 8
-
-	// fid
-	dto.Fid = int(binary.BigEndian.Uint32(data[offset : offset+4]))
-	offset += 4
-
-	// uid
-	dto.Uid = int64(binary.BigEndian.Uint64(data[offset : offset+8]))
-	offset += 8
-
-	// subject length
-	subjLen := int(binary.BigEndian.Uint16(data[offset : offset+2]))
-	offset += 2
-
-	// subject
-	dto.Subject = string(data[offset : offset+subjLen])
-	offset += subjLen
-
-	// views
-	dto.Views = int(binary.BigEndian.Uint32(data[offset : offset+4]))
-	offset += 4
-
-	// replies
-	dto.Replies = int(binary.BigEndian.Uint32(data[offset : offset+4]))
-	offset += 4
-
-	// dateline
-	dto.Dateline = int(binary.BigEndian.Uint32(data[offset : offset+4]))
-	offset += 4
+	r, err := codec.NewReader(data, kindThreadDTO)
+	if err != nil {
+		return err
+	}
+	var v ThreadDTO
+	v.Tid = r.Int()
+	v.Fid = int(r.Int())
+	v.Uid = r.Int()
+	v.Subject = r.String()
+	v.Views = int(r.Int())
+	v.Replies = int(r.Int())
+	v.Dateline = int(r.Int())
+	v.Lastpost = int(r.Int())
+	v.Status = int(r.Int())
+	v.Message = r.String()
+	v.PublishAt = int(r.Int())
+	v.RedirectTid = r.Int()
+	if err := r.Finish(); err != nil {
+		return err
+	}
+	*dto = v
+	return nil
+}
 
-	// lastpost
-	dto.Lastpost = int(binary.BigEndian.Uint32(data[offset : offset+4]))
-	offset += 4
+// MarshalBinary 序列化TagDTO
+func (dto *TagDTO) MarshalBinary() ([]byte, error) {
+	w := codec.NewWriter(kindTagDTO, 24+len(dto.Name)+len(dto.Slug))
+	w.Int(int64(dto.TagID))
+	w.String(dto.Name)
+	w.String(dto.Slug)
+	w.Int(int64(dto.Threads))
+	w.Int(int64(dto.View))
+	w.Int(int64(dto.Status))
+	return w.Bytes(), nil
+}
 
-	// status
-	dto.Status = int(data[offset])
-	offset += 1
+// UnmarshalBinary 反序列化TagDTO
+func (dto *TagDTO) UnmarshalBinary(data []byte) error {
+	r, err := codec.NewReader(data, kindTagDTO)
+	if err != nil {
+		return err
+	}
+	var v TagDTO
+	v.TagID = int(r.Int())
+	v.Name = r.String()
+	v.Slug = r.String()
+	v.Threads = int(r.Int())
+	v.View = int(r.Int())
+	v.Status = int(r.Int())
+	if err := r.Finish(); err != nil {
+		return err
+	}
+	*dto = v
+	return nil
+}
 
-	// message length
-	msgLen := int(binary.BigEndian.Uint16(data[offset : offset+2]))
-	offset += 2
+// MarshalBinary 序列化ForumDTO
+func (dto *ForumDTO) MarshalBinary() ([]byte, error) {
+	w := codec.NewWriter(kindForumDTO, 40+len(dto.Name)+len(dto.Path))
+	w.Int(int64(dto.Fid))
+	w.String(dto.Name)
+	w.Int(int64(dto.Parent))
+	w.String(dto.Path)
+	w.Int(int64(dto.Depth))
+	w.Int(int64(dto.Order))
+	w.Int(int64(dto.Threads))
+	w.Int(int64(dto.Today))
+	w.Int(int64(dto.Posts))
+	w.Int(int64(dto.Status))
+	return w.Bytes(), nil
+}
 
-	// message
-	if msgLen > 0 {
-		dto.Message = string(data[offset : offset+msgLen])
+// UnmarshalBinary 反序列化ForumDTO
+func (dto *ForumDTO) UnmarshalBinary(data []byte) error {
+	r, err := codec.NewReader(data, kindForumDTO)
+	if err != nil {
+		return err
 	}
-
+	var v ForumDTO
+	v.Fid = int(r.Int())
+	v.Name = r.String()
+	v.Parent = int(r.Int())
+	v.Path = r.String()
+	v.Depth = int(r.Int())
+	v.Order = int(r.Int())
+	v.Threads = int(r.Int())
+	v.Today = int(r.Int())
+	v.Posts = int(r.Int())
+	v.Status = int(r.Int())
+	if err := r.Finish(); err != nil {
+		return err
+	}
+	*dto = v
 	return nil
 }
 
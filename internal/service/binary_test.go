@@ -0,0 +1,73 @@
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestThreadDTOBinaryRoundTrip(t *testing.T) {
+	in := &ThreadDTO{
+		Tid: 1 << 60, Fid: 3, Uid: 42, Subject: "标题", Views: 10, Replies: 2,
+		Dateline: 1700000000, Lastpost: 1700000100, Status: 3, PublishAt: 1700000200, RedirectTid: 42,
+		Message: strings.Repeat("m", 70000), // 旧格式会被截断
+	}
+	data, _ := in.MarshalBinary()
+
+	var out ThreadDTO
+	if err := out.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if out != *in {
+		t.Fatal("thread round trip mismatch")
+	}
+
+	// 类型不匹配视为无效数据
+	var forum ForumDTO
+	if err := forum.UnmarshalBinary(data); err == nil {
+		t.Fatal("thread data decoded as forum")
+	}
+}
+
+func TestForumTagDTOBinaryRoundTrip(t *testing.T) {
+	f := &ForumDTO{Fid: 7, Name: "版块", Parent: 1, Path: "0,1", Depth: 1, Order: 2, Threads: 30, Today: 4, Posts: 500, Status: 0}
+	data, _ := f.MarshalBinary()
+	var fo ForumDTO
+	if err := fo.UnmarshalBinary(data); err != nil || fo != *f {
+		t.Fatalf("forum round trip: %v %+v", err, fo)
+	}
+
+	tg := &TagDTO{TagID: 9, Name: "golang", Slug: "golang", Threads: 12, View: 99, Status: 0}
+	data, _ = tg.MarshalBinary()
+	var to TagDTO
+	if err := to.UnmarshalBinary(data); err != nil || to != *tg {
+		t.Fatalf("tag round trip: %v %+v", err, to)
+	}
+}
+
+func FuzzThreadDTOUnmarshal(f *testing.F) {
+	seed, _ := (&ThreadDTO{Tid: 1, Subject: "s", Message: "m"}).MarshalBinary()
+	f.Add(seed)
+	f.Add([]byte{0, 0, 0, 0, 0, 0, 0, 1}) // 旧格式数据
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var dto ThreadDTO
+		_ = dto.UnmarshalBinary(data)
+	})
+}
+
+func FuzzForumDTOUnmarshal(f *testing.F) {
+	seed, _ := (&ForumDTO{Fid: 1, Name: "n", Path: "0"}).MarshalBinary()
+	f.Add(seed)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var dto ForumDTO
+		_ = dto.UnmarshalBinary(data)
+	})
+}
+
+func FuzzTagDTOUnmarshal(f *testing.F) {
+	seed, _ := (&TagDTO{TagID: 1, Name: "n"}).MarshalBinary()
+	f.Add(seed)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var dto TagDTO
+		_ = dto.UnmarshalBinary(data)
+	})
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"time"
+
+	"well_go/internal/core/config"
+	"well_go/internal/pkg/pool"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// newTieredCache 按统一的缓存配置创建二级缓存
+// L1 固定使用 JSON，l2Codec 为 nil 时 L2 也使用 JSON
+func newTieredCache[T any](name string, l1 *pool.BigCache, l2 *redis.Client, cfg *config.CacheConfig, l2Codec pool.Codec[T]) *pool.TieredCache[T] {
+	return pool.NewTieredCache(pool.TieredOptions[T]{
+		Name:        name,
+		L1:          l1,
+		L2:          l2,
+		L2Codec:     l2Codec,
+		TTL:         time.Duration(cfg.L2TTL) * time.Second,
+		NegativeTTL: time.Duration(cfg.NegativeTTL) * time.Second,
+		Jitter:      cfg.TTLJitter,
+		Metrics:     pool.NewPromMetrics(),
+	})
+}
@@ -2,7 +2,8 @@ package service
 
 import (
 	"context"
-	"encoding/json"
+	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
@@ -13,16 +14,23 @@ import (
 	"well_go/internal/repository"
 
 	"github.com/redis/go-redis/v9"
-	"golang.org/x/sync/singleflight"
+)
+
+var (
+	ErrForumNotEmpty      = repository.ErrForumNotEmpty
+	ErrForumHasChildren   = repository.ErrForumHasChildren
+	ErrForumParentTrashed = fmt.Errorf("parent forum is in trash, restore it first")
+	ErrForumNotFound      = fmt.Errorf("forum not found")
+	ErrForumCycle         = repository.ErrForumCycle
 )
 
 // ForumService Forum 业务服务
 type ForumService struct {
-	repo   repository.ForumRepository
-	l1     *pool.BigCache // L1 缓存（零GC）
-	l2     *redis.Client
-	sf     *singleflight.Group
-	config *config.CacheConfig
+	repo       repository.ForumRepository
+	accessRepo repository.ForumAccessRepository
+	cache      *pool.TieredCache[ForumDTO]
+	l2         *redis.Client
+	config     *config.CacheConfig
 }
 
 // ForumDTO 版块数据传输对象
@@ -39,6 +47,22 @@ type ForumDTO struct {
 	Status  int    `json:"status"`
 }
 
+// ForumAccessDTO 版块权限数据传输对象
+type ForumAccessDTO struct {
+	Fid      int `json:"fid"`
+	Gid      int `json:"gid"`
+	Read     int `json:"read"`
+	Thread   int `json:"thread"`
+	Reply    int `json:"reply"`
+	Upload   int `json:"upload"`
+	Download int `json:"download"`
+}
+
+// Perms 按 model.Access* 权限位顺序返回权限值
+func (dto *ForumAccessDTO) Perms() []int {
+	return []int{dto.Read, dto.Thread, dto.Reply, dto.Upload, dto.Download}
+}
+
 // ForumTreeNode 论坛树节点
 type ForumTreeNode struct {
 	ForumDTO
@@ -46,54 +70,34 @@ type ForumTreeNode struct {
 }
 
 // NewForumService 创建 ForumService 实例
-func NewForumService(repo repository.ForumRepository, l2 *redis.Client, cfg *config.CacheConfig) *ForumService {
+func NewForumService(repo repository.ForumRepository, accessRepo repository.ForumAccessRepository, l2 *redis.Client, cfg *config.CacheConfig) *ForumService {
 	l1Cache, _ := pool.NewBigCache(cfg.L1Cap, time.Duration(cfg.L2TTL)*time.Second)
 	return &ForumService{
-		repo:   repo,
-		l1:     l1Cache,
-		l2:     l2,
-		sf:     &singleflight.Group{},
-		config: cfg,
+		repo:       repo,
+		accessRepo: accessRepo,
+		cache:      newTieredCache[ForumDTO]("forum", l1Cache, l2, cfg, pool.BinaryCodec[ForumDTO, *ForumDTO]{}),
+		l2:         l2,
+		config:     cfg,
 	}
 }
 
-// Get 获取单个 Forum
+func (s *ForumService) invalidateForumCache(fid int) {
+	s.cache.Delete(context.Background(), fmt.Sprintf("forum:%d", fid))
+}
+
+// Get 获取单个 Forum（不存在时返回 nil, nil）
 func (s *ForumService) Get(ctx context.Context, fid int) (*ForumDTO, error) {
 	key := fmt.Sprintf("forum:%d", fid)
 
-	// L1 Cache
-	if data, ok := s.l1.Get(key); ok {
-		if data != nil {
-			var dto ForumDTO
-			if err := json.Unmarshal(data, &dto); err == nil {
-				return &dto, nil
-			}
-		}
-	}
-
-	// L2 Cache
-	ctxL2 := context.Background()
-	if v, err := s.l2.Get(ctxL2, key).Bytes(); err == nil {
-		var dto ForumDTO
-		if err := dto.UnmarshalBinary(v); err == nil {
-			// Write L1
-			if bytes, _ := json.Marshal(&dto); bytes != nil {
-				s.l1.Set(key, bytes)
-			}
-			return &dto, nil
-		}
-	}
-
-	// SingleFlight + DB
-	v, err, _ := s.sf.Do(key, func() (interface{}, error) {
+	return s.cache.Get(ctx, key, func(ctx context.Context) (*ForumDTO, error) {
 		f, err := s.repo.GetByID(ctx, fid)
 		if err != nil {
 			return nil, err
 		}
-		if f == nil {
+		if f == nil || f.DeletedAt > 0 {
 			return nil, nil
 		}
-		dto := &ForumDTO{
+		return &ForumDTO{
 			Fid:     f.Fid,
 			Name:    f.Name,
 			Parent:  f.Parent,
@@ -104,22 +108,8 @@ func (s *ForumService) Get(ctx context.Context, fid int) (*ForumDTO, error) {
 			Today:   f.Today,
 			Posts:   f.Posts,
 			Status:  f.Status,
-		}
-		// Write Cache
-		if bytes, err := dto.MarshalBinary(); err == nil {
-			s.l2.Set(ctxL2, key, bytes, time.Duration(s.config.L2TTL)*time.Second)
-		}
-		// Write L1
-		if bytes, _ := json.Marshal(&dto); bytes != nil {
-			s.l1.Set(key, bytes)
-		}
-		return dto, nil
+		}, nil
 	})
-
-	if err != nil {
-		return nil, err
-	}
-	return v.(*ForumDTO), nil
 }
 
 // GetAll 获取所有 Forum
@@ -231,6 +221,7 @@ func (s *ForumService) Create(ctx context.Context, name string, parent int) (*Fo
 		logger.Error("create forum failed", logger.String("error", err.Error()))
 		return nil, err
 	}
+	s.invalidateForumCache(id) // 自增 fid 可能已被负缓存
 
 	return &ForumDTO{
 		Fid:    id,
@@ -260,91 +251,167 @@ func (s *ForumService) Update(ctx context.Context, fid int, name string, status
 	}
 
 	// Invalidate Cache
-	key := fmt.Sprintf("forum:%d", fid)
-	s.l1.Remove(key)
-	s.l2.Del(context.Background(), key)
+	s.invalidateForumCache(fid)
 
 	return nil
 }
 
-// Delete 删除 Forum
-func (s *ForumService) Delete(ctx context.Context, fid int) error {
-	forum, err := s.repo.GetByID(ctx, fid)
+// Move 将版块（连同子树）移动到新的父版块下，parent=0 表示移为一级版块
+// order 非 nil 时同时设置排序值
+func (s *ForumService) Move(ctx context.Context, fid, parent int, order *int) error {
+	fids, err := s.repo.Move(ctx, fid, parent, order)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrForumNotFound
+	}
 	if err != nil {
 		return err
 	}
-	if forum == nil {
-		return fmt.Errorf("forum not found")
+
+	// 子树各版块的 path/depth 均已变化
+	for _, id := range fids {
+		s.invalidateForumCache(id)
+	}
+	return nil
+}
+
+// Reorder 批量设置版块排序值（fid → order）
+func (s *ForumService) Reorder(ctx context.Context, orders map[int]int) error {
+	if err := s.repo.Reorder(ctx, orders); err != nil {
+		return err
+	}
+	for fid := range orders {
+		s.invalidateForumCache(fid)
 	}
+	return nil
+}
 
-	if err := s.repo.Delete(ctx, fid); err != nil {
+// Delete 将 Forum 移入回收站
+// 仍有主题（含草稿、回收站中的主题）或子版块时拒绝删除，避免清除后留下孤儿数据
+func (s *ForumService) Delete(ctx context.Context, fid int) error {
+	ok, err := s.repo.Trash(ctx, fid, int(time.Now().Unix()))
+	if err != nil {
 		return err
 	}
+	if !ok {
+		return ErrForumNotFound
+	}
 
 	// Invalidate Cache
-	key := fmt.Sprintf("forum:%d", fid)
-	s.l1.Remove(key)
-	s.l1.Flush() // 简单起见，删除时刷新整个缓存
-	s.l2.Del(context.Background(), key)
+	s.invalidateForumCache(fid)
+	pool.InvalidatePrefix(ctx, "forum:") // 简单起见，删除时刷新所有实例的版块 L1
 
 	return nil
 }
 
-// FlushCache 刷新缓存
-func (s *ForumService) FlushCache(ctx context.Context) error {
-	s.l1.Flush()
-	return nil
+// Restore 从回收站恢复 Forum，父版块仍在回收站时拒绝
+func (s *ForumService) Restore(ctx context.Context, fid int) (bool, error) {
+	forum, err := s.repo.GetByID(ctx, fid)
+	if err != nil {
+		return false, err
+	}
+	if forum == nil || forum.DeletedAt == 0 {
+		return false, nil
+	}
+	if forum.Parent > 0 {
+		parent, err := s.repo.GetByID(ctx, forum.Parent)
+		if err != nil {
+			return false, err
+		}
+		if parent == nil || parent.DeletedAt > 0 {
+			return false, ErrForumParentTrashed
+		}
+	}
+
+	ok, err := s.repo.Restore(ctx, fid)
+	if err != nil || !ok {
+		return false, err
+	}
+	s.invalidateForumCache(fid)
+	return true, nil
+}
+
+// Purge 彻底删除回收站中的 Forum，不满足条件（不在回收站/仍有主题或子版块）时返回 false
+func (s *ForumService) Purge(ctx context.Context, fid int) (bool, error) {
+	ok, err := s.repo.Purge(ctx, fid)
+	if err != nil || !ok {
+		return false, err
+	}
+	s.invalidateForumCache(fid)
+	return true, nil
+}
+
+// GetAllAccess 获取全部版块权限（runtime 预热用）
+func (s *ForumService) GetAllAccess(ctx context.Context) ([]*ForumAccessDTO, error) {
+	rows, err := s.accessRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newForumAccessDTOs(rows), nil
 }
 
-// MarshalBinary 序列化
-func (dto *ForumDTO) MarshalBinary() ([]byte, error) {
-	buf := make([]byte, 0)
-	buf = append(buf, byte(dto.Fid))
-	buf = append(buf, byte(dto.Fid>>8))
-	buf = append(buf, byte(dto.Fid>>16))
-	buf = append(buf, byte(dto.Fid>>24))
-	buf = append(buf, byte(dto.Fid>>32))
-	buf = append(buf, byte(dto.Fid>>40))
-	buf = append(buf, byte(dto.Fid>>48))
-	buf = append(buf, byte(dto.Fid>>56))
-
-	nameLen := len(dto.Name)
-	buf = append(buf, byte(nameLen))
-	buf = append(buf, []byte(dto.Name)...)
-
-	buf = append(buf, byte(dto.Parent))
-	buf = append(buf, byte(dto.Parent>>8))
-	buf = append(buf, byte(dto.Parent>>16))
-	buf = append(buf, byte(dto.Parent>>24))
-
-	buf = append(buf, byte(dto.Status))
-
-	return buf, nil
+// GetAccess 获取版块权限
+func (s *ForumService) GetAccess(ctx context.Context, fid int) ([]*ForumAccessDTO, error) {
+	rows, err := s.accessRepo.GetByFid(ctx, fid)
+	if err != nil {
+		return nil, err
+	}
+	return newForumAccessDTOs(rows), nil
 }
 
-// UnmarshalBinary 反序列化
-func (dto *ForumDTO) UnmarshalBinary(data []byte) error {
-	if len(data) < 10 {
-		return fmt.Errorf("invalid data length")
+// SaveAccess 整体替换版块权限（空列表表示取消限制）
+func (s *ForumService) SaveAccess(ctx context.Context, fid int, list []*ForumAccessDTO) error {
+	forum, err := s.repo.GetByID(ctx, fid)
+	if err != nil {
+		return err
+	}
+	if forum == nil {
+		return fmt.Errorf("forum not found")
 	}
 
-	offset := 0
-	dto.Fid = int(int64(data[0]) | int64(data[1])<<8 | int64(data[2])<<16 | int64(data[3])<<24 |
-		int64(data[4])<<32 | int64(data[5])<<40 | int64(data[6])<<48 | int64(data[7])<<56)
-	offset = 8
+	rows := make([]*model.ForumAccess, 0, len(list))
+	for _, dto := range list {
+		dto.Fid = fid
+		rows = append(rows, &model.ForumAccess{
+			Fid:      fid,
+			GID:      dto.Gid,
+			Read:     dto.Read,
+			Thread:   dto.Thread,
+			Reply:    dto.Reply,
+			Upload:   dto.Upload,
+			Download: dto.Download,
+		})
+	}
+	return s.accessRepo.Replace(ctx, fid, rows)
+}
 
-	nameLen := int(data[offset])
-	offset++
-	if len(data) < offset+nameLen {
-		return fmt.Errorf("invalid name length")
+func newForumAccessDTOs(rows []*model.ForumAccess) []*ForumAccessDTO {
+	list := make([]*ForumAccessDTO, 0, len(rows))
+	for _, a := range rows {
+		list = append(list, &ForumAccessDTO{
+			Fid:      a.Fid,
+			Gid:      a.GID,
+			Read:     a.Read,
+			Thread:   a.Thread,
+			Reply:    a.Reply,
+			Upload:   a.Upload,
+			Download: a.Download,
+		})
 	}
-	dto.Name = string(data[offset : offset+nameLen])
-	offset += nameLen
+	return list
+}
 
-	dto.Parent = int(int64(data[offset]) | int64(data[offset+1])<<8 | int64(data[offset+2])<<16 | int64(data[offset+3])<<24)
-	offset += 4
+// OnThreadPublish 主题上下线后刷新版块缓存（计数已在同一事务中更新）
+func (s *ForumService) OnThreadPublish(ctx context.Context, thread *model.Thread, published bool) {
+	s.invalidateForumCache(thread.Fid)
+}
 
-	dto.Status = int(data[offset])
+// OnCountersChanged 主题移动后刷新版块缓存（计数已在同一事务中对账）
+func (s *ForumService) OnCountersChanged(ctx context.Context, fid int) {
+	s.invalidateForumCache(fid)
+}
 
+// FlushCache 刷新缓存
+func (s *ForumService) FlushCache(ctx context.Context) error {
+	pool.InvalidatePrefix(ctx, "forum:")
 	return nil
 }
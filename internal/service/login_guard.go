@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"well_go/internal/core/config"
+	"well_go/internal/model"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var ErrTooManyAttempts = fmt.Errorf("登录尝试过多，请稍后再试")
+
+// LoginThrottledError 登录被限制（退避等待中或已锁定），RetryAfter 后可重试
+// 无论用户名是否存在都按同样规则计数和限制，响应不暴露账号是否存在
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string { return ErrTooManyAttempts.Error() }
+
+func (e *LoginThrottledError) Unwrap() error { return ErrTooManyAttempts }
+
+// 登录防爆破状态（Redis）：
+//
+//	login:fail:user:{name} / login:fail:ip:{ip}  窗口内失败次数
+//	login:wait:user:{name}                       指数退避，存在期间拒绝该用户名登录
+//	login:lock:user:{name} / login:lock:ip:{ip}  锁定，到期自动解除或由管理员解除
+const (
+	loginFailKeyPrefix = "login:fail:"
+	loginWaitKeyPrefix = "login:wait:"
+	loginLockKeyPrefix = "login:lock:"
+)
+
+// incrFailScript 失败计数 +1，首次计数时设置窗口过期
+var incrFailScript = redis.NewScript(`
+local n = redis.call('INCR', KEYS[1])
+if n == 1 then redis.call('EXPIRE', KEYS[1], ARGV[1]) end
+return n`)
+
+// LoginGuard 按用户名与 IP 统计登录失败，实施指数退避与临时锁定
+type LoginGuard struct {
+	l2    *redis.Client
+	cfg   *config.LoginConfig
+	audit *AuditService
+}
+
+// NewLoginGuard 创建 LoginGuard 实例
+func NewLoginGuard(l2 *redis.Client, cfg *config.LoginConfig, audit *AuditService) *LoginGuard {
+	return &LoginGuard{l2: l2, cfg: cfg, audit: audit}
+}
+
+// normalizeLoginName 用户名按不区分大小写计数（与库中用户名排序规则一致）
+func normalizeLoginName(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+func userScope(username string) string { return "user:" + normalizeLoginName(username) }
+
+func ipScope(ip string) string { return "ip:" + ip }
+
+// Check 登录前检查，被限制时返回 *LoginThrottledError
+func (g *LoginGuard) Check(ctx context.Context, username, ip string) error {
+	pipe := g.l2.Pipeline()
+	cmds := []*redis.DurationCmd{
+		pipe.PTTL(ctx, loginLockKeyPrefix+userScope(username)),
+		pipe.PTTL(ctx, loginWaitKeyPrefix+userScope(username)),
+		pipe.PTTL(ctx, loginLockKeyPrefix+ipScope(ip)),
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return err
+	}
+
+	var wait time.Duration
+	for _, cmd := range cmds {
+		if d := cmd.Val(); d > wait {
+			wait = d
+		}
+	}
+	if wait > 0 {
+		return &LoginThrottledError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// Fail 记录一次失败：用户名进入指数退避，达到阈值时锁定用户名/IP 并写审计日志
+func (g *LoginGuard) Fail(ctx context.Context, username, ip string) error {
+	window := g.cfg.FailureWindow
+	userFails, err := incrFailScript.Run(ctx, g.l2, []string{loginFailKeyPrefix + userScope(username)}, window).Int()
+	if err != nil {
+		return err
+	}
+	ipFails, err := incrFailScript.Run(ctx, g.l2, []string{loginFailKeyPrefix + ipScope(ip)}, window).Int()
+	if err != nil {
+		return err
+	}
+
+	if userFails >= g.cfg.MaxFailures {
+		if err := g.lock(ctx, userScope(username)); err != nil {
+			return err
+		}
+		g.audit.Record(ctx, model.AuditLoginLockout, 0, normalizeLoginName(username), ip,
+			fmt.Sprintf("%d failed attempts, locked %ds", userFails, g.cfg.Lockout))
+	} else if err := g.l2.Set(ctx, loginWaitKeyPrefix+userScope(username), 1, g.backoff(userFails)).Err(); err != nil {
+		return err
+	}
+
+	if ipFails >= g.cfg.IPMaxFailures {
+		if err := g.lock(ctx, ipScope(ip)); err != nil {
+			return err
+		}
+		g.audit.Record(ctx, model.AuditLoginLockout, 0, ip, ip,
+			fmt.Sprintf("%d failed attempts from ip, locked %ds", ipFails, g.cfg.Lockout))
+	}
+	return nil
+}
+
+// backoff 第 n 次失败后的等待时间：base × 2^(n-1)，不超过上限
+func (g *LoginGuard) backoff(n int) time.Duration {
+	wait := time.Duration(g.cfg.BackoffBase) * time.Second
+	limit := time.Duration(g.cfg.BackoffMax) * time.Second
+	for i := 1; i < n && wait < limit; i++ {
+		wait *= 2
+	}
+	if wait > limit {
+		wait = limit
+	}
+	return wait
+}
+
+// lock 锁定并清零失败计数（解锁后重新计数）
+func (g *LoginGuard) lock(ctx context.Context, scope string) error {
+	pipe := g.l2.TxPipeline()
+	pipe.Set(ctx, loginLockKeyPrefix+scope, 1, time.Duration(g.cfg.Lockout)*time.Second)
+	pipe.Del(ctx, loginFailKeyPrefix+scope, loginWaitKeyPrefix+scope)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Success 登录成功后清除该用户名的失败计数与退避（IP 计数保留，避免用一个有效账号重置）
+func (g *LoginGuard) Success(ctx context.Context, username string) error {
+	scope := userScope(username)
+	return g.l2.Del(ctx, loginFailKeyPrefix+scope, loginWaitKeyPrefix+scope).Err()
+}
+
+// Unlock 管理员解除用户名或 IP 的锁定（两者可同时给出），返回实际解除的锁定数
+func (g *LoginGuard) Unlock(ctx context.Context, operator int64, username, ip, clientIP string) (int, error) {
+	var scopes, targets []string
+	if username != "" {
+		scopes = append(scopes, userScope(username))
+		targets = append(targets, normalizeLoginName(username))
+	}
+	if ip != "" {
+		scopes = append(scopes, ipScope(ip))
+		targets = append(targets, ip)
+	}
+
+	unlocked := 0
+	for i, scope := range scopes {
+		pipe := g.l2.TxPipeline()
+		del := pipe.Del(ctx, loginLockKeyPrefix+scope)
+		pipe.Del(ctx, loginFailKeyPrefix+scope, loginWaitKeyPrefix+scope)
+		if _, err := pipe.Exec(ctx); err != nil {
+			return unlocked, err
+		}
+		if del.Val() > 0 {
+			unlocked++
+			g.audit.Record(ctx, model.AuditLoginUnlock, operator, targets[i], clientIP, "")
+		}
+	}
+	return unlocked, nil
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"well_go/internal/core/config"
+	"well_go/internal/core/logger"
+	"well_go/internal/model"
+	"well_go/internal/repository"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fakeAuditRepo 记录写入的审计日志
+type fakeAuditRepo struct {
+	repository.AuditLogRepository
+	logs []*model.AuditLog
+}
+
+func (r *fakeAuditRepo) Create(ctx context.Context, log *model.AuditLog) error {
+	r.logs = append(r.logs, log)
+	return nil
+}
+
+func (r *fakeAuditRepo) actions() []string {
+	actions := make([]string, 0, len(r.logs))
+	for _, l := range r.logs {
+		actions = append(actions, l.Action+":"+l.Target)
+	}
+	return actions
+}
+
+var testLoginConfig = config.LoginConfig{
+	MaxFailures:   4,
+	IPMaxFailures: 6,
+	FailureWindow: 900,
+	Lockout:       600,
+	BackoffBase:   1,
+	BackoffMax:    4,
+}
+
+func newTestLoginGuard(t *testing.T) (*LoginGuard, *miniredis.Miniredis, *fakeAuditRepo) {
+	t.Helper()
+	if err := logger.Init(&config.LoggingConfig{Level: "error"}); err != nil {
+		t.Fatal(err)
+	}
+	mr := miniredis.RunT(t)
+	l2 := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { l2.Close() })
+
+	cfg := testLoginConfig
+	audit := &fakeAuditRepo{}
+	return NewLoginGuard(l2, &cfg, NewAuditService(audit)), mr, audit
+}
+
+// retryAfter Check 返回的等待时间，未被限制时为 0
+func retryAfter(t *testing.T, g *LoginGuard, username, ip string) time.Duration {
+	t.Helper()
+	err := g.Check(context.Background(), username, ip)
+	var throttled *LoginThrottledError
+	if errors.As(err, &throttled) {
+		return throttled.RetryAfter
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return 0
+}
+
+func TestLoginBackoff(t *testing.T) {
+	g, _, _ := newTestLoginGuard(t)
+	for n, want := range map[int]time.Duration{
+		1: time.Second,
+		2: 2 * time.Second,
+		3: 4 * time.Second,
+		4: 4 * time.Second, // 上限
+		9: 4 * time.Second,
+	} {
+		if got := g.backoff(n); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", n, got, want)
+		}
+	}
+
+	ctx := context.Background()
+	for i, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		if err := g.Fail(ctx, "Alice", "10.0.0.1"); err != nil {
+			t.Fatal(err)
+		}
+		// 用户名不区分大小写
+		if got := retryAfter(t, g, "alice", "10.0.0.2"); got != want {
+			t.Fatalf("after %d failures retry after %v, want %v", i+1, got, want)
+		}
+	}
+}
+
+func TestLoginLockout(t *testing.T) {
+	g, mr, audit := newTestLoginGuard(t)
+	ctx := context.Background()
+
+	for i := 0; i < testLoginConfig.MaxFailures; i++ {
+		if err := g.Fail(ctx, "alice", "10.0.0.1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := retryAfter(t, g, "alice", "10.0.0.2"); got != 600*time.Second {
+		t.Fatalf("locked retry after %v, want 600s", got)
+	}
+	if got := audit.actions(); len(got) != 1 || got[0] != model.AuditLoginLockout+":alice" {
+		t.Fatalf("audit = %v", got)
+	}
+
+	// 锁定到期后重新计数
+	mr.FastForward(600 * time.Second)
+	if got := retryAfter(t, g, "alice", "10.0.0.2"); got != 0 {
+		t.Fatalf("lock should expire, retry after %v", got)
+	}
+	if err := g.Fail(ctx, "alice", "10.0.0.3"); err != nil {
+		t.Fatal(err)
+	}
+	if got := retryAfter(t, g, "alice", "10.0.0.3"); got != time.Second {
+		t.Fatalf("failures should restart after lockout, retry after %v", got)
+	}
+
+	// IP 达到阈值后锁定，任何用户名都被拒绝
+	for i := 0; i < testLoginConfig.IPMaxFailures; i++ {
+		if err := g.Fail(ctx, "user"+string(rune('a'+i)), "10.0.0.9"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := retryAfter(t, g, "nobody", "10.0.0.9"); got != 600*time.Second {
+		t.Fatalf("ip locked retry after %v, want 600s", got)
+	}
+}
+
+func TestLoginSuccessResets(t *testing.T) {
+	g, mr, _ := newTestLoginGuard(t)
+	ctx := context.Background()
+
+	for i := 0; i < testLoginConfig.MaxFailures-1; i++ {
+		if err := g.Fail(ctx, "alice", "10.0.0.1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := g.Success(ctx, "ALICE"); err != nil {
+		t.Fatal(err)
+	}
+	if got := retryAfter(t, g, "alice", "10.0.0.1"); got != 0 {
+		t.Fatalf("success should clear backoff, retry after %v", got)
+	}
+	for _, key := range []string{"login:fail:user:alice", "login:wait:user:alice"} {
+		if mr.Exists(key) {
+			t.Fatalf("%s should be cleared", key)
+		}
+	}
+	// IP 计数不因成功登录重置
+	if got, _ := mr.Get("login:fail:ip:10.0.0.1"); got != "3" {
+		t.Fatalf("ip failures = %q, want 3", got)
+	}
+
+	// 重置后需要再失败 MaxFailures 次才锁定
+	for i := 0; i < testLoginConfig.MaxFailures-1; i++ {
+		if err := g.Fail(ctx, "alice", "10.0.0.2"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if mr.Exists("login:lock:user:alice") {
+		t.Fatal("should not be locked before MaxFailures after reset")
+	}
+}
+
+func TestLoginUnlock(t *testing.T) {
+	g, mr, audit := newTestLoginGuard(t)
+	ctx := context.Background()
+
+	for i := 0; i < testLoginConfig.IPMaxFailures; i++ {
+		if err := g.Fail(ctx, "alice", "10.0.0.1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	n, err := g.Unlock(ctx, 1, "Alice", "10.0.0.1", "127.0.0.1")
+	if err != nil || n != 2 {
+		t.Fatalf("unlock = %d, %v, want 2", n, err)
+	}
+	if got := retryAfter(t, g, "alice", "10.0.0.1"); got != 0 {
+		t.Fatalf("unlocked retry after %v", got)
+	}
+	for _, key := range mr.Keys() {
+		t.Errorf("key %s should be cleared", key)
+	}
+	want := []string{
+		model.AuditLoginLockout + ":alice",
+		model.AuditLoginLockout + ":10.0.0.1",
+		model.AuditLoginUnlock + ":alice",
+		model.AuditLoginUnlock + ":10.0.0.1",
+	}
+	if got := audit.actions(); len(got) != len(want) || got[2] != want[2] || got[3] != want[3] {
+		t.Fatalf("audit = %v, want %v", got, want)
+	}
+
+	// 未锁定时不计数、不记审计
+	if n, err := g.Unlock(ctx, 1, "alice", "", "127.0.0.1"); err != nil || n != 0 {
+		t.Fatalf("second unlock = %d, %v, want 0", n, err)
+	}
+	if len(audit.logs) != len(want) {
+		t.Fatalf("audit = %v", audit.actions())
+	}
+}
+
+// TestLoginUnknownUser 用户名不存在与密码错误的响应和限制完全一致
+func TestLoginUnknownUser(t *testing.T) {
+	g, mr, _ := newTestLoginGuard(t)
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := &fakeUserRepo{users: map[int64]*model.User{
+		snowflakeUID: {Uid: snowflakeUID, Username: "alice", Password: string(hash)},
+	}}
+	s := &UserService{repo: users, guard: g}
+	ctx := context.Background()
+
+	for i := 0; i < testLoginConfig.MaxFailures+1; i++ {
+		_, errExisting := s.Login(ctx, "alice", "wrong", "10.0.0.1")
+		_, errUnknown := s.Login(ctx, "bob", "wrong", "10.0.0.2")
+		if errExisting == nil || errUnknown == nil || errExisting.Error() != errUnknown.Error() {
+			t.Fatalf("attempt %d: existing = %v, unknown = %v", i+1, errExisting, errUnknown)
+		}
+		if a, b := retryAfter(t, g, "alice", "10.0.0.3"), retryAfter(t, g, "bob", "10.0.0.3"); a != b {
+			t.Fatalf("attempt %d: retry after existing = %v, unknown = %v", i+1, a, b)
+		}
+		mr.FastForward(time.Duration(testLoginConfig.BackoffMax) * time.Second) // 等过退避，继续校验密码
+	}
+	if a, b := retryAfter(t, g, "alice", "10.0.0.3"), retryAfter(t, g, "bob", "10.0.0.3"); a == 0 || a != b {
+		t.Fatalf("both should be locked: existing = %v, unknown = %v", a, b)
+	}
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"well_go/internal/core/config"
+	"well_go/internal/core/logger"
+	"well_go/internal/core/snowflake"
+	"well_go/internal/model"
+	"well_go/internal/pkg/pool"
+	"well_go/internal/repository"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrPostNotFound      = fmt.Errorf("post not found")
+	ErrInvalidPostStatus = fmt.Errorf("invalid post status")
+)
+
+// PostService 回帖业务服务
+type PostService struct {
+	repo       repository.PostRepository
+	threadRepo repository.ThreadRepository
+	threadSvc  *ThreadService
+	forumSvc   *ForumService
+	cache      *pool.TieredCache[PostDTO]
+	listCache  *pool.TieredCache[[]*PostDTO]
+	countCache *pool.TieredCache[int]
+	gen        *pool.Generation // 按 tid 的回帖列表代际
+	l2Config   *config.CacheConfig
+}
+
+// PostDTO 回帖数据传输对象
+type PostDTO struct {
+	Pid      int64  `json:"pid"`
+	Tid      int64  `json:"tid"`
+	Uid      int64  `json:"uid"`
+	Message  string `json:"message"`
+	Dateline int    `json:"dateline"`
+	Status   int    `json:"status"`
+}
+
+// NewPostService 创建 PostService 实例
+func NewPostService(repo repository.PostRepository, threadRepo repository.ThreadRepository, threadSvc *ThreadService, forumSvc *ForumService, l2 *redis.Client, l2Config *config.CacheConfig) *PostService {
+	l1Cache, _ := pool.NewBigCache(l2Config.L1Cap, time.Duration(l2Config.L2TTL)*time.Second)
+
+	return &PostService{
+		repo:       repo,
+		threadRepo: threadRepo,
+		threadSvc:  threadSvc,
+		forumSvc:   forumSvc,
+		cache:      newTieredCache[PostDTO]("post", l1Cache, l2, l2Config, nil),
+		listCache:  newTieredCache[[]*PostDTO]("post_list", l1Cache, l2, l2Config, nil),
+		countCache: newTieredCache[int]("post_count", l1Cache, l2, l2Config, nil),
+		gen:        pool.NewGeneration(l2, "post:list:gen"),
+		l2Config:   l2Config,
+	}
+}
+
+func newPostDTO(p *model.Post) *PostDTO {
+	return &PostDTO{
+		Pid:      p.Pid,
+		Tid:      p.Tid,
+		Uid:      p.Uid,
+		Message:  p.Message,
+		Dateline: p.Dateline,
+		Status:   p.Status,
+	}
+}
+
+func (s *PostService) invalidatePostCache(ctx context.Context, pid, tid int64) {
+	s.cache.Delete(ctx, fmt.Sprintf("post:%d", pid))
+	s.gen.BumpInt64(ctx, tid)
+}
+
+// Get 获取单个回帖
+func (s *PostService) Get(ctx context.Context, pid int64) (*PostDTO, error) {
+	key := fmt.Sprintf("post:%d", pid)
+
+	return s.cache.Get(ctx, key, func(ctx context.Context) (*PostDTO, error) {
+		p, err := s.repo.GetByID(ctx, pid)
+		if err != nil {
+			return nil, err
+		}
+		if p == nil {
+			return nil, nil
+		}
+		return newPostDTO(p), nil
+	})
+}
+
+// List 获取主题回帖列表（仅正常状态），total 为同样条件下的回帖总数
+func (s *PostService) List(ctx context.Context, tid int64, page, pageSize int) ([]*PostDTO, int, error) {
+	gen := s.gen.GetInt64(ctx, tid)
+	total, err := s.countCache.Get(ctx, fmt.Sprintf("post:count:%d:%d", tid, gen), func(ctx context.Context) (*int, error) {
+		n, err := s.repo.CountByTid(ctx, tid)
+		if err != nil {
+			return nil, err
+		}
+		return &n, nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	key := fmt.Sprintf("post:list:%d:%d:%d:%d", tid, gen, page, pageSize)
+
+	list, err := s.listCache.Get(ctx, key, func(ctx context.Context) (*[]*PostDTO, error) {
+		offset := (page - 1) * pageSize
+		pids, err := s.repo.GetListPIDsByTid(ctx, tid, offset, pageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		list := make([]*PostDTO, 0, len(pids))
+		if len(pids) > 0 {
+			posts, err := s.repo.GetByPIDs(ctx, pids)
+			if err != nil {
+				return nil, err
+			}
+			for _, p := range posts {
+				list = append(list, newPostDTO(p))
+			}
+		}
+		return &list, nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return *list, *total, nil
+}
+
+// Create 创建回帖
+func (s *PostService) Create(ctx context.Context, tid int64, uid int64, message string) (*PostDTO, error) {
+	thread, err := s.threadRepo.GetByID(ctx, tid)
+	if err != nil {
+		return nil, err
+	}
+	if thread == nil || thread.Status != model.ThreadPublished {
+		return nil, ErrThreadNotFound
+	}
+
+	post := &model.Post{
+		Pid:      snowflake.Generate(),
+		Tid:      tid,
+		Uid:      uid,
+		Message:  message,
+		Dateline: int(time.Now().Unix()),
+		Status:   model.PostNormal,
+	}
+
+	if err := s.repo.Create(ctx, post, thread.Fid); err != nil {
+		logger.Error("create post failed", logger.String("error", err.Error()))
+		return nil, err
+	}
+
+	// Invalidate Cache
+	s.gen.BumpInt64(ctx, tid)
+	s.threadSvc.invalidateThreadCache(tid)
+	s.forumSvc.invalidateForumCache(thread.Fid)
+	s.threadSvc.invalidateThreadList(ctx, thread.Fid) // 回复数/最后回复时间影响列表
+
+	return newPostDTO(post), nil
+}
+
+// Update 更新回帖；屏蔽/恢复时同步主题回复数与版块回帖数
+func (s *PostService) Update(ctx context.Context, pid int64, message string, status int) error {
+	if !model.ValidPostStatus(status) {
+		return ErrInvalidPostStatus
+	}
+	post, err := s.repo.GetByID(ctx, pid)
+	if err != nil {
+		return err
+	}
+	if post == nil {
+		return ErrPostNotFound
+	}
+
+	thread, err := s.threadRepo.GetByID(ctx, post.Tid)
+	if err != nil {
+		return err
+	}
+	fid := 0
+	if thread != nil {
+		fid = thread.Fid
+	}
+
+	statusChanged := post.Status != status
+	post.Message = message
+	post.Status = status
+
+	if err := s.repo.Update(ctx, post, fid); err != nil {
+		return err
+	}
+
+	// Invalidate Cache
+	s.invalidatePostCache(ctx, pid, post.Tid)
+	if statusChanged {
+		s.threadSvc.invalidateThreadCache(post.Tid)
+		if fid > 0 {
+			s.forumSvc.invalidateForumCache(fid)
+			s.threadSvc.invalidateThreadList(ctx, fid)
+		}
+	}
+
+	return nil
+}
+
+// Delete 删除回帖
+func (s *PostService) Delete(ctx context.Context, pid int64) error {
+	post, err := s.repo.GetByID(ctx, pid)
+	if err != nil {
+		return err
+	}
+	if post == nil {
+		return ErrPostNotFound
+	}
+
+	thread, err := s.threadRepo.GetByID(ctx, post.Tid)
+	if err != nil {
+		return err
+	}
+	fid := 0
+	if thread != nil {
+		fid = thread.Fid
+	}
+
+	if err := s.repo.Delete(ctx, post, fid); err != nil {
+		return err
+	}
+
+	// Invalidate Cache
+	s.invalidatePostCache(ctx, pid, post.Tid)
+	s.threadSvc.invalidateThreadCache(post.Tid)
+	if fid > 0 {
+		s.forumSvc.invalidateForumCache(fid)
+		s.threadSvc.invalidateThreadList(ctx, fid)
+	}
+
+	return nil
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"well_go/internal/core/config"
+	"well_go/internal/model"
+	"well_go/internal/pkg/pool"
+	"well_go/internal/repository"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrRoleNotFound      = fmt.Errorf("role not found")
+	ErrRoleBuiltin       = fmt.Errorf("built-in admin role cannot be modified or deleted")
+	ErrInvalidPermission = fmt.Errorf("invalid permission")
+	ErrRoleNotScopable   = fmt.Errorf("role has no forum-scoped permissions (thread.*/post.*)")
+	ErrUserNotFound      = fmt.Errorf("user not found")
+)
+
+// permissionModules 可在角色中使用 "模块.*" 的模块
+var permissionModules = map[string]bool{"forum": true, "thread": true, "post": true, "tag": true}
+
+// knownPermissions 全部权限
+var knownPermissions = map[string]bool{
+	model.PermForumCreate: true, model.PermForumUpdate: true, model.PermForumDelete: true, model.PermForumAccess: true,
+	model.PermThreadCreate: true, model.PermThreadUpdate: true, model.PermThreadDelete: true, model.PermThreadMove: true, model.PermThreadPublish: true,
+	model.PermPostCreate: true, model.PermPostUpdate: true, model.PermPostDelete: true,
+	model.PermTagCreate: true, model.PermTagUpdate: true, model.PermTagDelete: true,
+	model.PermTrashManage: true, model.PermSearchManage: true, model.PermCacheManage: true, model.PermRoleManage: true,
+	model.PermUserManage: true,
+}
+
+// RBACService 角色权限：角色管理、用户授予与有效权限查询
+type RBACService struct {
+	repo      repository.RoleRepository
+	userRepo  repository.UserRepository
+	forumRepo repository.ForumRepository
+	cache     *pool.TieredCache[model.Grants]
+	gen       *pool.Generation // 角色权限变更时整体失效
+}
+
+// RoleDTO 角色数据传输对象
+type RoleDTO struct {
+	RoleID      int      `json:"role_id"`
+	Name        string   `json:"name"`
+	Title       string   `json:"title"`
+	Permissions []string `json:"permissions"`
+}
+
+// NewRBACService 创建 RBACService 实例
+func NewRBACService(repo repository.RoleRepository, userRepo repository.UserRepository, forumRepo repository.ForumRepository,
+	l2 *redis.Client, cfg *config.CacheConfig) *RBACService {
+	l1Cache, _ := pool.NewBigCache(cfg.L1Cap, time.Duration(cfg.L2TTL)*time.Second)
+	return &RBACService{
+		repo:      repo,
+		userRepo:  userRepo,
+		forumRepo: forumRepo,
+		cache:     newTieredCache[model.Grants]("rbac_grants", l1Cache, l2, cfg, nil),
+		gen:       pool.NewGeneration(l2, "rbac:gen"),
+	}
+}
+
+func newRoleDTO(r *model.Role) *RoleDTO {
+	return &RoleDTO{RoleID: r.RoleID, Name: r.Name, Title: r.Title, Permissions: r.Perms()}
+}
+
+func (s *RBACService) grantsKey(ctx context.Context, uid int64) string {
+	return fmt.Sprintf("rbac:grants:%d:%d", s.gen.Get(ctx, "all"), uid)
+}
+
+// Grants 获取用户的有效权限（缓存）
+func (s *RBACService) Grants(ctx context.Context, uid int64) (*model.Grants, error) {
+	return s.cache.Get(ctx, s.grantsKey(ctx, uid), func(ctx context.Context) (*model.Grants, error) {
+		roles, err := s.UserRoles(ctx, uid)
+		if err != nil {
+			return nil, err
+		}
+		return model.NewGrants(roles), nil
+	})
+}
+
+// Roles 获取全部角色
+func (s *RBACService) Roles(ctx context.Context) ([]*RoleDTO, error) {
+	roles, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*RoleDTO, 0, len(roles))
+	for _, r := range roles {
+		list = append(list, newRoleDTO(r))
+	}
+	return list, nil
+}
+
+// normalizePerms 校验并去重权限列表
+func normalizePerms(perms []string) (string, error) {
+	seen := make(map[string]bool, len(perms))
+	list := make([]string, 0, len(perms))
+	for _, p := range perms {
+		p = strings.TrimSpace(p)
+		if p == "" || seen[p] {
+			continue
+		}
+		module, action, _ := strings.Cut(p, ".")
+		if !knownPermissions[p] && p != "*" && !(action == "*" && permissionModules[module]) {
+			return "", fmt.Errorf("%w: %s", ErrInvalidPermission, p)
+		}
+		seen[p] = true
+		list = append(list, p)
+	}
+	return strings.Join(list, ","), nil
+}
+
+// CreateRole 创建角色
+func (s *RBACService) CreateRole(ctx context.Context, name, title string, perms []string) (*RoleDTO, error) {
+	joined, err := normalizePerms(perms)
+	if err != nil {
+		return nil, err
+	}
+	role := &model.Role{Name: name, Title: title, Permissions: joined}
+	id, err := s.repo.Create(ctx, role)
+	if err != nil {
+		return nil, err
+	}
+	role.RoleID = id
+	return newRoleDTO(role), nil
+}
+
+// UpdateRole 修改角色名称与权限（所有用户的有效权限随之失效）
+func (s *RBACService) UpdateRole(ctx context.Context, roleID int, name, title string, perms []string) (*RoleDTO, error) {
+	if roleID == model.RoleAdmin {
+		return nil, ErrRoleBuiltin
+	}
+	role, err := s.repo.GetByID(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, ErrRoleNotFound
+	}
+	joined, err := normalizePerms(perms)
+	if err != nil {
+		return nil, err
+	}
+
+	role.Name, role.Title, role.Permissions = name, title, joined
+	if err := s.repo.Update(ctx, role); err != nil {
+		return nil, err
+	}
+	s.gen.Bump(ctx, "all")
+	return newRoleDTO(role), nil
+}
+
+// DeleteRole 删除角色及其全部授予
+func (s *RBACService) DeleteRole(ctx context.Context, roleID int) error {
+	if roleID == model.RoleAdmin {
+		return ErrRoleBuiltin
+	}
+	ok, err := s.repo.Delete(ctx, roleID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrRoleNotFound
+	}
+	s.gen.Bump(ctx, "all")
+	return nil
+}
+
+// UserRoles 获取用户的角色
+func (s *RBACService) UserRoles(ctx context.Context, uid int64) ([]*model.UserRole, error) {
+	grants, err := s.repo.GetUserRoles(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	return s.fillRoles(ctx, grants)
+}
+
+// ForumRoles 获取版块的版主等限定角色
+func (s *RBACService) ForumRoles(ctx context.Context, fid int) ([]*model.UserRole, error) {
+	grants, err := s.repo.GetForumRoles(ctx, fid)
+	if err != nil {
+		return nil, err
+	}
+	return s.fillRoles(ctx, grants)
+}
+
+// fillRoles 按 role_id 补全授予的角色名称与权限，角色已不存在的授予被丢弃
+func (s *RBACService) fillRoles(ctx context.Context, grants []*model.UserRole) ([]*model.UserRole, error) {
+	if len(grants) == 0 {
+		return grants, nil
+	}
+	ids := make([]int, 0, len(grants))
+	seen := make(map[int]bool, len(grants))
+	for _, g := range grants {
+		if !seen[g.RoleID] {
+			seen[g.RoleID] = true
+			ids = append(ids, g.RoleID)
+		}
+	}
+	roles, err := s.repo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]*model.Role, len(roles))
+	for _, r := range roles {
+		byID[r.RoleID] = r
+	}
+
+	list := make([]*model.UserRole, 0, len(grants))
+	for _, g := range grants {
+		role, ok := byID[g.RoleID]
+		if !ok {
+			continue
+		}
+		g.Name, g.Permissions = role.Name, role.Permissions
+		list = append(list, g)
+	}
+	return list, nil
+}
+
+// Assign 授予用户角色；fid > 0 时仅在该版块及其子版块生效，角色须含可按版块授予的权限
+func (s *RBACService) Assign(ctx context.Context, uid int64, roleID, fid int) error {
+	user, err := s.userRepo.GetByID(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	role, err := s.repo.GetByID(ctx, roleID)
+	if err != nil {
+		return err
+	}
+	if role == nil {
+		return ErrRoleNotFound
+	}
+	if fid > 0 {
+		forum, err := s.forumRepo.GetByID(ctx, fid)
+		if err != nil {
+			return err
+		}
+		if forum == nil || forum.DeletedAt > 0 {
+			return ErrForumNotFound
+		}
+		if len(model.NewGrants([]*model.UserRole{{Fid: fid, Permissions: role.Permissions}}).Forums[fid]) == 0 {
+			return ErrRoleNotScopable
+		}
+	}
+
+	if err := s.repo.Assign(ctx, uid, roleID, fid); err != nil {
+		return err
+	}
+	s.cache.Delete(ctx, s.grantsKey(ctx, uid))
+	return nil
+}
+
+// Revoke 收回用户角色，不存在时返回 false
+func (s *RBACService) Revoke(ctx context.Context, uid int64, roleID, fid int) (bool, error) {
+	ok, err := s.repo.Revoke(ctx, uid, roleID, fid)
+	if err != nil || !ok {
+		return false, err
+	}
+	s.cache.Delete(ctx, s.grantsKey(ctx, uid))
+	return true, nil
+}
@@ -0,0 +1,358 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"well_go/internal/core/logger"
+	"well_go/internal/model"
+	"well_go/internal/pkg/search"
+	"well_go/internal/repository"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var ErrSearchRebuilding = fmt.Errorf("search index rebuild in progress")
+
+// searchSyncChannel 多实例索引同步频道
+const searchSyncChannel = "well:search:sync"
+
+// searchRebuildBatch 重建索引时每批读取的主题数
+const searchRebuildBatch = 500
+
+// searchSnippetRunes 摘要长度
+const searchSnippetRunes = 120
+
+// SearchService 主题全文搜索（进程内倒排索引）
+// 1. 启动时从 DB 全量构建，之后由 ThreadService 的增删改增量同步
+// 2. 增量变更通过 Redis Pub/Sub 通知其它实例，收到后各自从 DB 重新加载
+// 3. 重建期间的增量变更记入 pending，新索引切换后重放，避免丢失
+type SearchService struct {
+	idx        atomic.Pointer[search.Index]
+	threadRepo repository.ThreadRepository
+	threadTag  repository.ThreadTagRepository
+	l2         *redis.Client
+	node       string
+
+	rebuilding atomic.Bool
+	pendingMu  sync.Mutex
+	pending    map[int64]bool // tid -> 是否删除
+}
+
+// SearchQuery 搜索条件
+type SearchQuery struct {
+	Q        string
+	Fid      int            // 0 表示不限
+	TagID    int            // 0 表示不限
+	Allow    func(int) bool // 版块可读判断（按用户组）
+	Page     int
+	PageSize int
+}
+
+// SearchItem 搜索结果项（Subject/Snippet 已 HTML 转义并以 <em> 高亮）
+type SearchItem struct {
+	Tid      int64   `json:"tid"`
+	Fid      int     `json:"fid"`
+	Uid      int64   `json:"uid"`
+	Subject  string  `json:"subject"`
+	Snippet  string  `json:"snippet"`
+	Views    int     `json:"views"`
+	Replies  int     `json:"replies"`
+	Dateline int     `json:"dateline"`
+	Lastpost int     `json:"lastpost"`
+	Score    float64 `json:"score"`
+}
+
+// SearchResult 搜索结果
+type SearchResult struct {
+	List  []*SearchItem `json:"list"`
+	Total int           `json:"total"`
+}
+
+// searchEvent 索引同步事件
+type searchEvent struct {
+	Node   string `json:"n"`
+	Tid    int64  `json:"t"`
+	Delete bool   `json:"d,omitempty"`
+}
+
+// NewSearchService 创建 SearchService 实例
+// node: 本实例节点 ID，用于忽略自身发出的同步事件
+func NewSearchService(threadRepo repository.ThreadRepository, threadTag repository.ThreadTagRepository, l2 *redis.Client, node string) *SearchService {
+	s := &SearchService{
+		threadRepo: threadRepo,
+		threadTag:  threadTag,
+		l2:         l2,
+		node:       node,
+		pending:    make(map[int64]bool),
+	}
+	s.idx.Store(search.NewIndex())
+	return s
+}
+
+// Search 搜索主题
+func (s *SearchService) Search(ctx context.Context, q *SearchQuery) (*SearchResult, error) {
+	terms := search.QueryTerms(q.Q)
+	result := &SearchResult{List: []*SearchItem{}}
+	if len(terms) == 0 {
+		return result, nil
+	}
+
+	var tagTids map[int64]struct{}
+	if q.TagID > 0 {
+		tids, err := s.threadTag.GetByTag(ctx, q.TagID)
+		if err != nil {
+			return nil, err
+		}
+		tagTids = make(map[int64]struct{}, len(tids))
+		for _, tid := range tids {
+			tagTids[tid] = struct{}{}
+		}
+	}
+
+	hits, total := s.idx.Load().Search(search.Query{
+		Terms: terms,
+		Filter: func(tid int64, fid int) bool {
+			if q.Fid > 0 && fid != q.Fid {
+				return false
+			}
+			if tagTids != nil {
+				if _, ok := tagTids[tid]; !ok {
+					return false
+				}
+			}
+			return q.Allow == nil || q.Allow(fid)
+		},
+		Offset: (q.Page - 1) * q.PageSize,
+		Limit:  q.PageSize,
+	})
+	result.Total = total
+	if len(hits) == 0 {
+		return result, nil
+	}
+
+	tids := make([]int64, 0, len(hits))
+	for _, h := range hits {
+		tids = append(tids, h.ID)
+	}
+	threads, err := s.threadRepo.GetByTIDs(ctx, tids)
+	if err != nil {
+		return nil, err
+	}
+	contents, err := s.threadRepo.GetContentsByTIDs(ctx, tids)
+	if err != nil {
+		return nil, err
+	}
+	messages := make(map[int64]string, len(contents))
+	for _, c := range contents {
+		messages[c.Tid] = c.Message
+	}
+	scores := make(map[int64]float64, len(hits))
+	for _, h := range hits {
+		scores[h.ID] = h.Score
+	}
+
+	// 索引与 DB 短暂不一致时（已删除），直接跳过
+	for _, t := range threads {
+		result.List = append(result.List, &SearchItem{
+			Tid:      t.Tid,
+			Fid:      t.Fid,
+			Uid:      t.Uid,
+			Subject:  search.Highlight(t.Subject, terms, 0),
+			Snippet:  search.Highlight(messages[t.Tid], terms, searchSnippetRunes),
+			Views:    t.Views,
+			Replies:  t.Replies,
+			Dateline: t.Dateline,
+			Lastpost: t.Lastpost,
+			Score:    scores[t.Tid],
+		})
+	}
+	return result, nil
+}
+
+// IndexThread 从 DB 加载主题写入索引，并通知其它实例
+func (s *SearchService) IndexThread(ctx context.Context, tid int64) {
+	if err := s.indexLocal(ctx, tid); err != nil {
+		logger.Error("search: index thread failed", logger.Int64("tid", tid), logger.String("error", err.Error()))
+	}
+	s.publish(ctx, tid, false)
+}
+
+// RemoveThread 从索引删除主题，并通知其它实例
+func (s *SearchService) RemoveThread(ctx context.Context, tid int64) {
+	s.removeLocal(tid)
+	s.publish(ctx, tid, true)
+}
+
+func (s *SearchService) indexLocal(ctx context.Context, tid int64) error {
+	s.markPending(tid, false)
+
+	thread, err := s.threadRepo.GetByID(ctx, tid)
+	if err != nil {
+		return err
+	}
+	if thread == nil || thread.Status != model.ThreadPublished || thread.RedirectTid > 0 {
+		s.idx.Load().Remove(tid) // 未发布的主题与跳转占位不可被搜索
+		return nil
+	}
+	content, err := s.threadRepo.GetContentByID(ctx, tid)
+	if err != nil {
+		return err
+	}
+
+	doc := &search.Document{
+		ID:      thread.Tid,
+		Fid:     thread.Fid,
+		Subject: thread.Subject,
+		Time:    int64(thread.Lastpost),
+	}
+	if content != nil {
+		doc.Body = content.Message
+	}
+	s.idx.Load().Add(doc)
+	return nil
+}
+
+func (s *SearchService) removeLocal(tid int64) {
+	s.markPending(tid, true)
+	s.idx.Load().Remove(tid)
+}
+
+// markPending 重建期间记录增量变更
+func (s *SearchService) markPending(tid int64, del bool) {
+	if !s.rebuilding.Load() {
+		return
+	}
+	s.pendingMu.Lock()
+	s.pending[tid] = del
+	s.pendingMu.Unlock()
+}
+
+// Rebuilding 是否正在重建
+func (s *SearchService) Rebuilding() bool {
+	return s.rebuilding.Load()
+}
+
+// Len 已索引文档数
+func (s *SearchService) Len() int {
+	return s.idx.Load().Len()
+}
+
+// Rebuild 从 DB 全量重建索引，完成后原子切换
+func (s *SearchService) Rebuild(ctx context.Context) (int, error) {
+	if !s.rebuilding.CompareAndSwap(false, true) {
+		return 0, ErrSearchRebuilding
+	}
+	defer s.rebuilding.Store(false)
+
+	idx := search.NewIndex()
+	var after int64
+	for {
+		threads, err := s.threadRepo.GetBatchAfter(ctx, after, searchRebuildBatch)
+		if err != nil {
+			s.resetPending()
+			return 0, err
+		}
+		if len(threads) == 0 {
+			break
+		}
+
+		tids := make([]int64, 0, len(threads))
+		for _, t := range threads {
+			if t.Status == model.ThreadPublished && t.RedirectTid == 0 {
+				tids = append(tids, t.Tid)
+			}
+		}
+		contents, err := s.threadRepo.GetContentsByTIDs(ctx, tids)
+		if err != nil {
+			s.resetPending()
+			return 0, err
+		}
+		messages := make(map[int64]string, len(contents))
+		for _, c := range contents {
+			messages[c.Tid] = c.Message
+		}
+
+		for _, t := range threads {
+			if t.Status != model.ThreadPublished || t.RedirectTid > 0 {
+				continue
+			}
+			idx.Add(&search.Document{
+				ID:      t.Tid,
+				Fid:     t.Fid,
+				Subject: t.Subject,
+				Body:    messages[t.Tid],
+				Time:    int64(t.Lastpost),
+			})
+		}
+		after = threads[len(threads)-1].Tid
+	}
+
+	s.idx.Store(idx)
+
+	// 重放重建期间的增量变更
+	s.pendingMu.Lock()
+	pending := s.pending
+	s.pending = make(map[int64]bool)
+	s.pendingMu.Unlock()
+	for tid, del := range pending {
+		if del {
+			idx.Remove(tid)
+			continue
+		}
+		if err := s.indexLocal(ctx, tid); err != nil {
+			logger.Warn("search: replay pending failed", logger.Int64("tid", tid), logger.String("error", err.Error()))
+		}
+	}
+
+	return idx.Len(), nil
+}
+
+func (s *SearchService) resetPending() {
+	s.pendingMu.Lock()
+	s.pending = make(map[int64]bool)
+	s.pendingMu.Unlock()
+}
+
+func (s *SearchService) publish(ctx context.Context, tid int64, del bool) {
+	data, _ := json.Marshal(searchEvent{Node: s.node, Tid: tid, Delete: del})
+	s.l2.Publish(ctx, searchSyncChannel, data)
+}
+
+// Run 订阅其它实例的索引变更，阻塞直到 ctx 结束
+func (s *SearchService) Run(ctx context.Context, onError func(error)) {
+	sub := s.l2.Subscribe(ctx, searchSyncChannel)
+	defer sub.Close()
+
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			var ev searchEvent
+			if err := json.Unmarshal([]byte(msg.Payload), &ev); err != nil {
+				if onError != nil {
+					onError(err)
+				}
+				continue
+			}
+			if ev.Node == s.node {
+				continue
+			}
+			if ev.Delete {
+				s.removeLocal(ev.Tid)
+				continue
+			}
+			if err := s.indexLocal(ctx, ev.Tid); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
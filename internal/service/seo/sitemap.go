@@ -46,6 +46,13 @@ type URLEntry struct {
 	Priority   float64 `xml:"priority,omitempty"`
 }
 
+// Invalidate 清除 sitemap 索引缓存（主题上下线时调用）
+func (s *SitemapService) Invalidate() {
+	s.cacheMu.Lock()
+	s.cache = nil
+	s.cacheMu.Unlock()
+}
+
 // Handler SEO处理器
 func (s *SitemapService) GetIndex(ctx context.Context) ([]byte, error) {
 	s.cacheMu.RLock()
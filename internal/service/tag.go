@@ -2,28 +2,63 @@ package service
 
 import (
 	"context"
-	"encoding/json"
+	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"well_go/internal/core/config"
 	"well_go/internal/core/logger"
 	"well_go/internal/model"
+	"well_go/internal/pkg/pinyin"
 	"well_go/internal/pkg/pool"
 	"well_go/internal/repository"
 
 	"github.com/redis/go-redis/v9"
-	"golang.org/x/sync/singleflight"
+)
+
+var (
+	ErrTagNotFound    = fmt.Errorf("tag not found")
+	ErrInvalidSlug    = fmt.Errorf("invalid slug, expect letters, digits or hanzi")
+	ErrSlugTaken      = fmt.Errorf("slug is already used by another tag")
+	ErrTagNameUsed    = fmt.Errorf("name is already used by another tag or alias, merge them instead")
+	ErrMergeSelf      = fmt.Errorf("cannot merge a tag into itself")
+	ErrTagNameTooLong = fmt.Errorf("tag name is too long, max %d characters", model.TagNameMaxLen)
 )
 
 // TagService Tag 业务服务
 type TagService struct {
-	repo      repository.TagRepository
-	threadTag repository.ThreadTagRepository
-	l1        *pool.BigCache // L1 缓存（零GC）
-	l2        *redis.Client
-	sf        *singleflight.Group
-	config    *config.CacheConfig
+	repo            repository.TagRepository
+	threadRepo      repository.ThreadRepository
+	threadTag       repository.ThreadTagRepository
+	aliasRepo       repository.TagAliasRepository
+	cache           *pool.TieredCache[TagDTO]
+	threadTagsCache *pool.TieredCache[[]*TagDTO]
+	relatedCache    *pool.TieredCache[[]*RelatedTagDTO]
+	relatedInterval time.Duration
+	viewWindow      time.Duration // 浏览去重窗口
+	l2              *redis.Client
+	config          *config.CacheConfig
+
+	tagHooks []TagHook
+}
+
+// TagHook 主题与标签关联变化回调（标签主题列表、相关推荐等缓存）
+// 标签合并等批量变化时 tid 为 0
+type TagHook func(ctx context.Context, tid int64, tagID int)
+
+// AddTagHook 注册主题标签变化回调
+func (s *TagService) AddTagHook(hook TagHook) {
+	s.tagHooks = append(s.tagHooks, hook)
+}
+
+func (s *TagService) afterThreadTagChange(ctx context.Context, tid int64, tagID int) {
+	s.invalidateThreadTagCache(ctx, tid)
+	for _, hook := range s.tagHooks {
+		hook(ctx, tid, tagID)
+	}
 }
 
 // TagDTO 标签数据传输对象
@@ -37,15 +72,18 @@ type TagDTO struct {
 }
 
 // NewTagService 创建 TagService 实例
-func NewTagService(repo repository.TagRepository, threadTag repository.ThreadTagRepository, l2 *redis.Client, cfg *config.CacheConfig) *TagService {
+func NewTagService(repo repository.TagRepository, threadRepo repository.ThreadRepository, threadTag repository.ThreadTagRepository, aliasRepo repository.TagAliasRepository, l2 *redis.Client, cfg *config.CacheConfig) *TagService {
 	l1Cache, _ := pool.NewBigCache(cfg.L1Cap, time.Duration(cfg.L2TTL)*time.Second)
 	return &TagService{
-		repo:      repo,
-		threadTag: threadTag,
-		l1:        l1Cache,
-		l2:        l2,
-		sf:        &singleflight.Group{},
-		config:    cfg,
+		repo:            repo,
+		threadRepo:      threadRepo,
+		threadTag:       threadTag,
+		aliasRepo:       aliasRepo,
+		cache:           newTieredCache[TagDTO]("tag", l1Cache, l2, cfg, pool.BinaryCodec[TagDTO, *TagDTO]{}),
+		threadTagsCache: newTieredCache[[]*TagDTO]("thread_tags", l1Cache, l2, cfg, nil),
+		relatedCache:    newTieredCache[[]*RelatedTagDTO]("tag_related", l1Cache, l2, cfg, nil),
+		l2:              l2,
+		config:          cfg,
 	}
 }
 
@@ -53,70 +91,40 @@ func NewTagService(repo repository.TagRepository, threadTag repository.ThreadTag
 func (s *TagService) Get(ctx context.Context, tagID int) (*TagDTO, error) {
 	key := fmt.Sprintf("tag:%d", tagID)
 
-	// L1 Cache
-	if s.l1 != nil {
-		if data, ok := s.l1.Get(key); ok {
-			if data != nil {
-				var dto TagDTO
-				if err := json.Unmarshal(data, &dto); err == nil {
-					return &dto, nil
-				}
-			}
+	return s.cache.Get(ctx, key, func(ctx context.Context) (*TagDTO, error) {
+		t, err := s.repo.GetByID(ctx, tagID)
+		if err != nil {
+			return nil, err
 		}
-	}
-
-	// L2 Cache
-	ctxL2 := context.Background()
-	if v, err := s.l2.Get(ctxL2, key).Bytes(); err == nil {
-		var dto TagDTO
-		if err := dto.UnmarshalBinary(v); err == nil {
-			// Write L1
-			if s.l1 != nil {
-				if bytes, _ := json.Marshal(&dto); bytes != nil {
-					s.l1.Set(key, bytes)
-				}
-			}
-			return &dto, nil
+		if t == nil || t.DeletedAt > 0 {
+			return nil, nil
 		}
-	}
+		return newTagDTO(t), nil
+	})
+}
 
-	// SingleFlight + DB
-	v, err, _ := s.sf.Do(key, func() (interface{}, error) {
-		t, err := s.repo.GetByID(ctx, tagID)
+// GetBySlug 根据 slug 获取 Tag（不存在或在回收站时返回 nil, nil）
+func (s *TagService) GetBySlug(ctx context.Context, slug string) (*TagDTO, error) {
+	key := fmt.Sprintf("tag:slug:%s", slug)
+
+	dto, err := s.cache.Get(ctx, key, func(ctx context.Context) (*TagDTO, error) {
+		t, err := s.repo.GetBySlug(ctx, slug)
 		if err != nil {
 			return nil, err
 		}
 		if t == nil {
 			return nil, nil
 		}
-		dto := &TagDTO{
-			TagID:   t.TagID,
-			Name:    t.Name,
-			Slug:    t.Slug,
-			Threads: t.Threads,
-			View:    t.View,
-			Status:  t.Status,
-		}
-		// Write Cache
-		if bytes, err := dto.MarshalBinary(); err == nil {
-			s.l2.Set(ctxL2, key, bytes, time.Duration(s.config.L2TTL)*time.Second)
-		}
-		// Write L1
-		if s.l1 != nil {
-			if bytes, _ := json.Marshal(&dto); bytes != nil {
-				s.l1.Set(key, bytes)
-			}
-		}
-		return dto, nil
+		return newTagDTO(t), nil
 	})
-
-	if err != nil {
-		return nil, err
-	}
-	if v == nil {
-		return nil, nil
+	if err != nil || dto == nil {
+		return dto, err
 	}
-	return v.(*TagDTO), nil
+
+	// 叠加尚未落库的浏览增量（返回副本，不修改共享的缓存对象）
+	live := *dto
+	live.View += s.pendingViews(ctx, dto.TagID)
+	return &live, nil
 }
 
 // GetByName 根据名称获取 Tag
@@ -125,7 +133,7 @@ func (s *TagService) GetByName(ctx context.Context, name string) (*TagDTO, error
 	if err != nil {
 		return nil, err
 	}
-	if t == nil {
+	if t == nil || t.DeletedAt > 0 {
 		return nil, nil
 	}
 	return &TagDTO{
@@ -184,84 +192,67 @@ func (s *TagService) GetHot(ctx context.Context, limit int) ([]*TagDTO, error) {
 func (s *TagService) GetByThread(ctx context.Context, tid int64) ([]*TagDTO, error) {
 	key := fmt.Sprintf("thread:tags:%d", tid)
 
-	if s.l1 != nil {
-		if data, ok := s.l1.Get(key); ok && data != nil {
-			var list []*TagDTO
-			if err := json.Unmarshal(data, &list); err == nil {
-				return list, nil
-			}
+	list, err := s.threadTagsCache.Get(ctx, key, func(ctx context.Context) (*[]*TagDTO, error) {
+		tagIDs, err := s.threadTag.GetByThread(ctx, tid)
+		if err != nil {
+			return nil, err
 		}
-	}
 
-	if data, err := s.l2.Get(ctx, key).Bytes(); err == nil {
-		var list []*TagDTO
-		if err := json.Unmarshal(data, &list); err == nil {
-			if s.l1 != nil {
-				s.l1.Set(key, data)
-			}
-			return list, nil
+		list := make([]*TagDTO, 0, len(tagIDs))
+		if len(tagIDs) == 0 {
+			return &list, nil
 		}
-	}
-
-	tagIDs, err := s.threadTag.GetByThread(ctx, tid)
-	if err != nil {
-		return nil, err
-	}
-	if len(tagIDs) == 0 {
-		return []*TagDTO{}, nil
-	}
 
-	tags, err := s.repo.GetByIDs(ctx, tagIDs)
-	if err != nil {
-		return nil, err
-	}
-
-	list := make([]*TagDTO, 0, len(tagIDs))
-	tagMap := make(map[int]*model.Tag, len(tags))
-	for _, t := range tags {
-		tagMap[t.TagID] = t
-	}
-	for _, tagID := range tagIDs {
-		t, ok := tagMap[tagID]
-		if !ok {
-			continue
+		tags, err := s.repo.GetByIDs(ctx, tagIDs)
+		if err != nil {
+			return nil, err
 		}
-		list = append(list, &TagDTO{
-			TagID:   t.TagID,
-			Name:    t.Name,
-			Slug:    t.Slug,
-			Threads: t.Threads,
-			View:    t.View,
-			Status:  t.Status,
-		})
-	}
 
-	if data, _ := json.Marshal(list); data != nil {
-		if s.l1 != nil {
-			s.l1.Set(key, data)
+		tagMap := make(map[int]*model.Tag, len(tags))
+		for _, t := range tags {
+			tagMap[t.TagID] = t
 		}
-		s.l2.Set(ctx, key, data, time.Duration(s.config.L2TTL)*time.Second)
+		for _, tagID := range tagIDs {
+			t, ok := tagMap[tagID]
+			if !ok {
+				continue
+			}
+			list = append(list, newTagDTO(t))
+		}
+		return &list, nil
+	})
+	if err != nil {
+		return nil, err
 	}
-
-	return list, nil
+	return *list, nil
 }
 
 func (s *TagService) invalidateThreadTagCache(ctx context.Context, tid int64) {
-	key := fmt.Sprintf("thread:tags:%d", tid)
-	if s.l1 != nil {
-		s.l1.Remove(key)
+	s.threadTagsCache.Delete(ctx, fmt.Sprintf("thread:tags:%d", tid))
+}
+
+func newTagDTO(t *model.Tag) *TagDTO {
+	return &TagDTO{
+		TagID:   t.TagID,
+		Name:    t.Name,
+		Slug:    t.Slug,
+		Threads: t.Threads,
+		View:    t.View,
+		Status:  t.Status,
 	}
-	s.l2.Del(ctx, key)
 }
 
 // Create 创建 Tag
 func (s *TagService) Create(ctx context.Context, name string) (*TagDTO, error) {
-	// 检查是否已存在
-	exist, err := s.repo.GetByName(ctx, name)
+	// 检查是否已存在（含同义词）
+	exist, err := s.resolve(ctx, name)
 	if err != nil {
 		return nil, err
 	}
 	if exist != nil {
+		if err := s.restoreIfTrashed(ctx, exist); err != nil {
+			return nil, err
+		}
 		return &TagDTO{
 			TagID:   exist.TagID,
 			Name:    exist.Name,
@@ -272,58 +263,46 @@ func (s *TagService) Create(ctx context.Context, name string) (*TagDTO, error) {
 		}, nil
 	}
 
-	tag := &model.Tag{
-		Name:    name,
-		Slug:    "",
-		Threads: 0,
-		View:    0,
-		Status:  0,
-	}
-
-	id, err := s.repo.Create(ctx, tag)
+	tag, err := s.createTag(ctx, name)
 	if err != nil {
 		logger.Error("create tag failed", logger.String("error", err.Error()))
 		return nil, err
 	}
+	return newTagDTO(tag), nil
+}
 
-	return &TagDTO{
-		TagID:   id,
-		Name:    name,
-		Slug:    tag.Slug,
-		Threads: 0,
-		View:    0,
-		Status:  0,
-	}, nil
+// createTag 以未占用的 slug 创建标签；slug 被并发请求抢先占用时重新选取后缀，
+// 若对方创建的正是同名标签则直接使用
+func (s *TagService) createTag(ctx context.Context, name string) (*model.Tag, error) {
+	for attempt := 0; ; attempt++ {
+		slug, err := s.uniqueSlug(ctx, name, 0)
+		if err != nil {
+			return nil, err
+		}
+		tag := &model.Tag{Name: name, Slug: slug}
+		id, err := s.repo.Create(ctx, tag)
+		if errors.Is(err, repository.ErrSlugConflict) && attempt < slugRetries {
+			if exist, err := s.resolve(ctx, name); err != nil || exist != nil {
+				return exist, err
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		tag.TagID = id
+		s.cache.Delete(ctx, fmt.Sprintf("tag:%d", id)) // 清除可能存在的负缓存
+		return tag, nil
+	}
 }
 
 // AddToThread 将 Tag 关联到主题
 func (s *TagService) AddToThread(ctx context.Context, tid int64, tagName string) error {
-	// 获取或创建 Tag
-	tag, err := s.repo.GetByName(ctx, tagName)
+	tag, err := s.getOrCreate(ctx, tagName)
 	if err != nil {
 		return err
 	}
 
-	if tag == nil {
-		newTag := &model.Tag{
-			Name:    tagName,
-			Threads: 0,
-			View:    0,
-			Status:  0,
-		}
-		id, err := s.repo.Create(ctx, newTag)
-		if err != nil {
-			return err
-		}
-		tag = &model.Tag{
-			TagID:   id,
-			Name:    tagName,
-			Threads: 0,
-			View:    0,
-			Status:  0,
-		}
-	}
-
 	// 检查是否已关联
 	tagIDs, err := s.threadTag.GetByThread(ctx, tid)
 	if err != nil {
@@ -348,10 +327,127 @@ func (s *TagService) AddToThread(ctx context.Context, tid int64, tagName string)
 	if err := s.repo.IncThreads(ctx, tag.TagID); err != nil {
 		return err
 	}
-	s.invalidateThreadTagCache(ctx, tid)
+	s.afterThreadTagChange(ctx, tid, tag.TagID)
 	return nil
 }
 
+// getOrCreate 按名称获取 Tag（同义词归并到目标标签，回收站中的直接恢复），不存在时创建
+func (s *TagService) getOrCreate(ctx context.Context, tagName string) (*model.Tag, error) {
+	tag, err := s.resolve(ctx, tagName)
+	if err != nil {
+		return nil, err
+	}
+	if tag != nil {
+		if err := s.restoreIfTrashed(ctx, tag); err != nil {
+			return nil, err
+		}
+		return tag, nil
+	}
+	return s.createTag(ctx, tagName)
+}
+
+// ThreadTagsResult 主题标签替换结果
+type ThreadTagsResult struct {
+	Tags    []*TagDTO    `json:"tags"`    // 替换后的标签
+	Added   []int        `json:"added"`   // 新增关联的 tag_id
+	Removed []int        `json:"removed"` // 移除关联的 tag_id
+	Failed  []*TagFailed `json:"failed"`  // 无法使用的标签名（其余标签照常生效）
+}
+
+// TagFailed 单个标签名的失败原因
+type TagFailed struct {
+	Name  string `json:"name"`
+	Error string `json:"error"`
+}
+
+// SetThreadTags 将主题标签替换为 names（增加缺少的、移除多余的），新标签创建、关联与计数在一个事务内完成
+// 新标签名无效时记入 Failed，不影响其它标签；已存在的标签总能使用，因此已有关联不会因校验失败被移除。
+// 数据库错误直接返回，主题标签保持不变；新标签 slug 被并发占用时整体重试
+func (s *TagService) SetThreadTags(ctx context.Context, tid int64, names []string) (*ThreadTagsResult, error) {
+	for attempt := 0; ; attempt++ {
+		result, err := s.setThreadTags(ctx, tid, names)
+		if errors.Is(err, repository.ErrSlugConflict) && attempt < slugRetries {
+			continue
+		}
+		return result, err
+	}
+}
+
+func (s *TagService) setThreadTags(ctx context.Context, tid int64, names []string) (*ThreadTagsResult, error) {
+	result := &ThreadTagsResult{Added: []int{}, Removed: []int{}, Failed: []*TagFailed{}}
+
+	tagIDs := make([]int, 0, len(names))
+	var create []*model.Tag
+	var trashed []int
+	creating := make(map[string]bool)
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		tag, err := s.resolve(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if tag != nil {
+			tagIDs = append(tagIDs, tag.TagID)
+			if tag.DeletedAt > 0 {
+				trashed = append(trashed, tag.TagID)
+			}
+			continue
+		}
+
+		// 新标签：仅在创建时校验
+		if creating[strings.ToLower(name)] {
+			continue
+		}
+		if utf8.RuneCountInString(name) > model.TagNameMaxLen {
+			result.Failed = append(result.Failed, &TagFailed{Name: name, Error: ErrTagNameTooLong.Error()})
+			continue
+		}
+		slug, err := s.uniqueSlug(ctx, name, 0)
+		if errors.Is(err, ErrSlugTaken) {
+			result.Failed = append(result.Failed, &TagFailed{Name: name, Error: err.Error()})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		creating[strings.ToLower(name)] = true
+		create = append(create, &model.Tag{Name: name, Slug: slug})
+	}
+
+	added, removed, err := s.threadTag.Replace(ctx, tid, tagIDs, create)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrThreadNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	result.Added = append(result.Added, added...)
+	result.Removed = append(result.Removed, removed...)
+
+	for _, tag := range create {
+		s.cache.Delete(ctx, fmt.Sprintf("tag:%d", tag.TagID)) // 清除可能存在的负缓存
+	}
+	for _, tagID := range trashed {
+		s.invalidateTag(ctx, tagID) // 已恢复
+	}
+	s.invalidateThreadTagCache(ctx, tid)
+	for _, tagID := range append(added, removed...) {
+		s.cache.Delete(ctx, fmt.Sprintf("tag:%d", tagID)) // 关联数变化
+		for _, hook := range s.tagHooks {
+			hook(ctx, tid, tagID)
+		}
+	}
+
+	result.Tags, err = s.GetByThread(ctx, tid)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 // RemoveFromThread 将 Tag 从主题移除
 func (s *TagService) RemoveFromThread(ctx context.Context, tid int64, tagID int) error {
 	// 删除关联
@@ -368,7 +464,7 @@ func (s *TagService) RemoveFromThread(ctx context.Context, tid int64, tagID int)
 			if err := s.repo.DecThreads(ctx, tagID); err != nil {
 				return err
 			}
-			s.invalidateThreadTagCache(ctx, tid)
+			s.afterThreadTagChange(ctx, tid, tagID)
 			return nil
 		}
 	}
@@ -376,63 +472,339 @@ func (s *TagService) RemoveFromThread(ctx context.Context, tid int64, tagID int)
 	return nil
 }
 
-// FlushCache 刷新缓存
-func (s *TagService) FlushCache(ctx context.Context) error {
-	if s.l1 != nil {
-		s.l1.Flush()
+// resolve 按名称查找标签，名称是同义词时返回其目标标签；都不存在时返回 nil, nil
+func (s *TagService) resolve(ctx context.Context, name string) (*model.Tag, error) {
+	tag, err := s.repo.GetByName(ctx, name)
+	if err != nil || tag != nil {
+		return tag, err
+	}
+	tagID, err := s.aliasRepo.GetTarget(ctx, name)
+	if err != nil || tagID == 0 {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, tagID)
+}
+
+// Rename 重命名标签（slug 不变，保持已有链接）；新名称已被其它标签或同义词占用时返回 ErrTagNameUsed
+func (s *TagService) Rename(ctx context.Context, tagID int, name string) (*TagDTO, error) {
+	tag, err := s.repo.GetByID(ctx, tagID)
+	if err != nil {
+		return nil, err
+	}
+	if tag == nil || tag.DeletedAt > 0 {
+		return nil, ErrTagNotFound
+	}
+	if name == tag.Name {
+		return newTagDTO(tag), nil
+	}
+
+	other, err := s.resolve(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if other != nil && other.TagID != tagID {
+		return nil, ErrTagNameUsed
+	}
+	if other != nil {
+		// 新名称原是自身的同义词，改为正式名称后移除该同义词
+		if _, err := s.aliasRepo.Delete(ctx, name, tagID); err != nil {
+			return nil, err
+		}
+	}
+
+	tag.Name = name
+	if err := s.repo.Update(ctx, tag); err != nil {
+		return nil, err
+	}
+	s.invalidateTag(ctx, tagID)
+	return newTagDTO(tag), nil
+}
+
+// Merge 将标签 from 并入 to：主题关联去重迁移、关联数重算，from 的名称与同义词归到 to
+func (s *TagService) Merge(ctx context.Context, from, to int) error {
+	if from == to {
+		return ErrMergeSelf
+	}
+	src, err := s.repo.GetByID(ctx, from)
+	if err != nil {
+		return err
+	}
+	if src == nil {
+		return ErrTagNotFound
+	}
+
+	tids, err := s.repo.Merge(ctx, from, to)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrTagNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	s.invalidateTagCache(ctx, from, src.Slug)
+	s.invalidateTag(ctx, to)
+	s.invalidateThreadTags(ctx, tids)
+	for _, hook := range s.tagHooks {
+		hook(ctx, 0, from)
+		hook(ctx, 0, to)
 	}
 	return nil
 }
 
-// MarshalBinary 序列化
-func (dto *TagDTO) MarshalBinary() ([]byte, error) {
-	buf := make([]byte, 0)
+// Aliases 获取标签的同义词
+func (s *TagService) Aliases(ctx context.Context, tagID int) ([]string, error) {
+	return s.aliasRepo.GetByTag(ctx, tagID)
+}
 
-	tagID := dto.TagID
-	buf = append(buf, byte(tagID))
-	buf = append(buf, byte(tagID>>8))
-	buf = append(buf, byte(tagID>>16))
-	buf = append(buf, byte(tagID>>24))
+// AddAlias 为标签添加同义词；名称已是其它标签或同义词时返回 ErrTagNameUsed（应改用 Merge）
+func (s *TagService) AddAlias(ctx context.Context, tagID int, alias string) error {
+	tag, err := s.repo.GetByID(ctx, tagID)
+	if err != nil {
+		return err
+	}
+	if tag == nil || tag.DeletedAt > 0 {
+		return ErrTagNotFound
+	}
 
-	nameLen := len(dto.Name)
-	buf = append(buf, byte(nameLen))
-	buf = append(buf, []byte(dto.Name)...)
+	other, err := s.resolve(ctx, alias)
+	if err != nil {
+		return err
+	}
+	if other != nil {
+		if other.TagID == tagID {
+			return nil
+		}
+		return ErrTagNameUsed
+	}
+	return s.aliasRepo.Create(ctx, alias, tagID)
+}
 
-	slugLen := len(dto.Slug)
-	buf = append(buf, byte(slugLen))
-	buf = append(buf, []byte(dto.Slug)...)
+// RemoveAlias 移除同义词，不存在时返回 false
+func (s *TagService) RemoveAlias(ctx context.Context, tagID int, alias string) (bool, error) {
+	return s.aliasRepo.Delete(ctx, alias, tagID)
+}
 
-	buf = append(buf, byte(dto.Status))
+// restoreIfTrashed 再次使用回收站中的标签名时直接恢复原标签，避免同名标签
+func (s *TagService) restoreIfTrashed(ctx context.Context, tag *model.Tag) error {
+	if tag.DeletedAt == 0 {
+		return nil
+	}
+	if _, err := s.Restore(ctx, tag.TagID); err != nil {
+		return err
+	}
+	tag.DeletedAt = 0
+	return nil
+}
 
-	return buf, nil
+// Delete 将 Tag 移入回收站（主题关联保留，恢复后原样可见）
+func (s *TagService) Delete(ctx context.Context, tagID int) error {
+	ok, err := s.repo.Trash(ctx, tagID, int(time.Now().Unix()))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrTagNotFound
+	}
+	s.invalidateTag(ctx, tagID)
+	return nil
+}
+
+// Restore 从回收站恢复 Tag，不在回收站时返回 false
+func (s *TagService) Restore(ctx context.Context, tagID int) (bool, error) {
+	ok, err := s.repo.Restore(ctx, tagID)
+	if err != nil || !ok {
+		return false, err
+	}
+	s.invalidateTag(ctx, tagID)
+	return true, nil
+}
+
+// Purge 彻底删除回收站中的 Tag 及其主题关联，不在回收站时返回 false
+func (s *TagService) Purge(ctx context.Context, tagID int) (bool, error) {
+	// 关联在清除后不可查，先记下受影响的主题
+	tag, err := s.repo.GetByID(ctx, tagID)
+	if err != nil || tag == nil {
+		return false, err
+	}
+	tids, err := s.threadTag.GetByTag(ctx, tagID)
+	if err != nil {
+		return false, err
+	}
+
+	ok, err := s.repo.Purge(ctx, tagID)
+	if err != nil || !ok {
+		return false, err
+	}
+	s.invalidateTagCache(ctx, tagID, tag.Slug)
+	s.invalidateThreadTags(ctx, tids)
+	return true, nil
+}
+
+// invalidateTag 失效标签缓存及其关联主题的标签列表
+func (s *TagService) invalidateTag(ctx context.Context, tagID int) {
+	slug := ""
+	if t, err := s.repo.GetByID(ctx, tagID); err == nil && t != nil {
+		slug = t.Slug
+	}
+	s.invalidateTagCache(ctx, tagID, slug)
+
+	tids, err := s.threadTag.GetByTag(ctx, tagID)
+	if err != nil {
+		s.invalidateThreadTagLists(ctx) // 查不到关联时退化为全部清除
+		return
+	}
+	s.invalidateThreadTags(ctx, tids)
+}
+
+// invalidateTagCache 失效单个标签（按 id 与 slug）的缓存
+func (s *TagService) invalidateTagCache(ctx context.Context, tagID int, slug string) {
+	s.cache.Delete(ctx, fmt.Sprintf("tag:%d", tagID))
+	if slug != "" {
+		s.cache.Delete(ctx, fmt.Sprintf("tag:slug:%s", slug))
+	}
+}
+
+// invalidateThreadTags 批量失效主题标签列表缓存
+func (s *TagService) invalidateThreadTags(ctx context.Context, tids []int64) {
+	const batch = 500
+	for len(tids) > 0 {
+		n := len(tids)
+		if n > batch {
+			n = batch
+		}
+		keys := make([]string, 0, n)
+		for _, tid := range tids[:n] {
+			keys = append(keys, fmt.Sprintf("thread:tags:%d", tid))
+		}
+		s.threadTagsCache.Delete(ctx, keys...)
+		tids = tids[n:]
+	}
+}
+
+// invalidateThreadTagLists 清除所有主题的标签列表缓存
+func (s *TagService) invalidateThreadTagLists(ctx context.Context) {
+	pool.InvalidatePrefix(ctx, "thread:tags:")
+
+	iter := s.l2.Scan(ctx, 0, "thread:tags:*", 100).Iterator()
+	for iter.Next(ctx) {
+		s.l2.Del(ctx, iter.Val())
+	}
 }
 
-// UnmarshalBinary 反序列化
-func (dto *TagDTO) UnmarshalBinary(data []byte) error {
-	if len(data) < 6 {
-		return fmt.Errorf("invalid data length")
+// uniqueSlug 由名称生成拼音 slug，已被占用时追加 "-2"、"-3"…
+// excludeID 为正在修改的标签自身
+func (s *TagService) uniqueSlug(ctx context.Context, name string, excludeID int) (string, error) {
+	base := pinyin.Slug(name, model.TagSlugMaxLen-4) // 预留后缀长度
+	if base == "" {
+		base = "tag"
+	}
+	return s.claimSlug(ctx, base, excludeID)
+}
+
+// claimSlug 返回 base 或带数字后缀的第一个未占用 slug
+func (s *TagService) claimSlug(ctx context.Context, base string, excludeID int) (string, error) {
+	for i := 1; i <= maxSlugSuffix; i++ {
+		slug := base
+		if i > 1 {
+			slug = fmt.Sprintf("%s-%d", base, i)
+		}
+		taken, err := s.repo.SlugTaken(ctx, slug, excludeID)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return slug, nil
+		}
 	}
+	return "", ErrSlugTaken
+}
+
+// maxSlugSuffix 同名 slug 的最大数字后缀
+const maxSlugSuffix = 999
 
-	offset := 0
-	dto.TagID = int(int64(data[0]) | int64(data[1])<<8 | int64(data[2])<<16 | int64(data[3])<<24)
-	offset = 4
+// slugRetries slug 写入时唯一索引冲突的重试次数（每次重新选取后缀）
+const slugRetries = 3
+
+// UpdateSlug 修改标签 slug（汉字自动转拼音，大写转小写），已被占用时返回 ErrSlugTaken
+func (s *TagService) UpdateSlug(ctx context.Context, tagID int, slug string) (*TagDTO, error) {
+	tag, err := s.repo.GetByID(ctx, tagID)
+	if err != nil {
+		return nil, err
+	}
+	if tag == nil || tag.DeletedAt > 0 {
+		return nil, ErrTagNotFound
+	}
 
-	nameLen := int(data[offset])
-	offset++
-	if len(data) < offset+nameLen {
-		return fmt.Errorf("invalid name length")
+	slug = pinyin.Slug(slug, model.TagSlugMaxLen)
+	if slug == "" {
+		return nil, ErrInvalidSlug
+	}
+	if slug != tag.Slug {
+		taken, err := s.repo.SlugTaken(ctx, slug, tagID)
+		if err != nil {
+			return nil, err
+		}
+		if taken {
+			return nil, ErrSlugTaken
+		}
+		err = s.repo.UpdateSlug(ctx, tagID, slug)
+		if errors.Is(err, repository.ErrSlugConflict) {
+			return nil, ErrSlugTaken
+		}
+		if err != nil {
+			return nil, err
+		}
+		s.cache.Delete(ctx, fmt.Sprintf("tag:slug:%s", tag.Slug))
+		s.invalidateTag(ctx, tagID)
 	}
-	dto.Name = string(data[offset : offset+nameLen])
-	offset += nameLen
 
-	slugLen := int(data[offset])
-	offset++
-	if len(data) < offset+slugLen {
-		return fmt.Errorf("invalid slug length")
+	tag.Slug = slug
+	return newTagDTO(tag), nil
+}
+
+// BackfillSlugs 为 slug 为空的历史标签生成 slug，返回处理数量
+func (s *TagService) BackfillSlugs(ctx context.Context) (int, error) {
+	done := 0
+	for {
+		tags, err := s.repo.GetWithoutSlug(ctx, 100)
+		if err != nil {
+			return done, err
+		}
+		for _, t := range tags {
+			slug, err := s.backfillSlug(ctx, t)
+			if err != nil {
+				return done, err
+			}
+			s.cache.Delete(ctx, fmt.Sprintf("tag:%d", t.TagID), fmt.Sprintf("tag:slug:%s", slug))
+			done++
+		}
+		if len(tags) < 100 {
+			if done > 0 {
+				s.invalidateThreadTagLists(ctx)
+			}
+			return done, nil
+		}
 	}
-	dto.Slug = string(data[offset : offset+slugLen])
+}
 
-	dto.Status = int(data[offset+slugLen])
+// backfillSlug 为单个标签写入 slug，与新建标签并发冲突时重新选取后缀
+func (s *TagService) backfillSlug(ctx context.Context, t *model.Tag) (string, error) {
+	for attempt := 0; ; attempt++ {
+		slug, err := s.uniqueSlug(ctx, t.Name, t.TagID)
+		if err != nil {
+			return "", err
+		}
+		err = s.repo.UpdateSlug(ctx, t.TagID, slug)
+		if errors.Is(err, repository.ErrSlugConflict) && attempt < slugRetries {
+			continue
+		}
+		return slug, err
+	}
+}
 
+// FlushCache 刷新缓存
+func (s *TagService) FlushCache(ctx context.Context) error {
+	pool.InvalidatePrefix(ctx, "tag:")
+	pool.InvalidatePrefix(ctx, "thread:tags:")
 	return nil
 }
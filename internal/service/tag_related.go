@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"well_go/internal/core/logger"
+	"well_go/internal/model"
+)
+
+// 相关标签：按已发布主题中的共现次数排序，定时整体重算后写入缓存
+// 共现在内存中统计：分批读取主题与其标签关联（均为单表查询），不做 thread_tag 自连接
+const (
+	relatedTagLimit        = 20                 // 每个标签保留的相关标签数
+	relatedScanBatch       = 1000               // 重算时每批读取的主题数
+	relatedTagSample       = 1000               // 缓存未命中即时统计时读取该标签最近的关联数
+	relatedRefreshLockKey  = "tag:related:lock" // 多实例只由一个实例重算
+	defaultRelatedInterval = time.Hour          // 未设置重算间隔时的默认值
+)
+
+// RelatedTagDTO 相关标签
+type RelatedTagDTO struct {
+	*TagDTO
+	Count int `json:"count"` // 共同出现的主题数
+}
+
+// SetRelatedInterval 设置相关标签重算间隔（缓存有效期为两个间隔，避免重算期间缓存过期）
+func (s *TagService) SetRelatedInterval(interval time.Duration) {
+	s.relatedInterval = interval
+}
+
+func (s *TagService) relatedTTL() time.Duration {
+	interval := s.relatedInterval
+	if interval <= 0 {
+		interval = defaultRelatedInterval
+	}
+	return 2 * interval
+}
+
+// Related 获取相关标签（按共现次数倒序），缓存未命中时即时统计该标签
+func (s *TagService) Related(ctx context.Context, tagID, limit int) ([]*RelatedTagDTO, error) {
+	key := fmt.Sprintf("tag:related:%d", tagID)
+	list, err := s.relatedCache.GetWithTTL(ctx, key, s.relatedTTL(), func(ctx context.Context) (*[]*RelatedTagDTO, error) {
+		sample, err := s.threadTag.GetByTags(ctx, []int{tagID}, relatedTagSample)
+		if err != nil {
+			return nil, err
+		}
+		tids := make([]int64, 0, len(sample))
+		for _, row := range sample {
+			tids = append(tids, row.Tid)
+		}
+		threads, err := s.threadRepo.GetByTIDs(ctx, tids)
+		if err != nil {
+			return nil, err
+		}
+		rows, err := s.threadTag.GetByThreads(ctx, publishedTids(threads))
+		if err != nil {
+			return nil, err
+		}
+		counts := make(map[int]map[int]int)
+		addCooccur(counts, rows)
+		cooccur := topCooccur(map[int]map[int]int{tagID: counts[tagID]}, relatedTagLimit)
+		ids := make([]int, 0, len(cooccur))
+		for _, row := range cooccur {
+			ids = append(ids, row.RelatedID)
+		}
+		tags, err := s.repo.GetByIDs(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		byID := make(map[int]*model.Tag, len(tags))
+		for _, t := range tags {
+			byID[t.TagID] = t
+		}
+		list := buildRelated(cooccur, byID)[tagID]
+		if list == nil {
+			list = []*RelatedTagDTO{}
+		}
+		return &list, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := *list
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// RefreshRelated 重算全部标签的相关标签并写入缓存，返回处理的标签数
+func (s *TagService) RefreshRelated(ctx context.Context) (int, error) {
+	tags, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+	byID := make(map[int]*model.Tag, len(tags))
+	ids := make([]int, 0, len(tags))
+	for _, t := range tags {
+		byID[t.TagID] = t
+		if t.Threads > 0 {
+			ids = append(ids, t.TagID)
+		}
+	}
+
+	counts := make(map[int]map[int]int)
+	var after int64
+	for {
+		threads, err := s.threadRepo.GetBatchAfter(ctx, after, relatedScanBatch)
+		if err != nil {
+			return 0, err
+		}
+		if len(threads) == 0 {
+			break
+		}
+		after = threads[len(threads)-1].Tid
+
+		rows, err := s.threadTag.GetByThreads(ctx, publishedTids(threads))
+		if err != nil {
+			return 0, err
+		}
+		addCooccur(counts, rows)
+		if len(threads) < relatedScanBatch {
+			break
+		}
+	}
+
+	related := buildRelated(topCooccur(counts, relatedTagLimit), byID)
+	ttl := s.relatedTTL()
+	for _, tagID := range ids {
+		list := related[tagID]
+		if list == nil {
+			list = []*RelatedTagDTO{}
+		}
+		s.relatedCache.Set(ctx, fmt.Sprintf("tag:related:%d", tagID), &list, ttl)
+	}
+	return len(ids), nil
+}
+
+// publishedTids 已发布且不是跳转占位的主题 tid
+func publishedTids(threads []*model.Thread) []int64 {
+	tids := make([]int64, 0, len(threads))
+	for _, t := range threads {
+		if t.Status == model.ThreadPublished && t.RedirectTid == 0 {
+			tids = append(tids, t.Tid)
+		}
+	}
+	return tids
+}
+
+// addCooccur 按主题分组标签关联，同一主题内每对不同标签计数加一
+func addCooccur(counts map[int]map[int]int, rows []*model.ThreadTag) {
+	byThread := make(map[int64][]int)
+	for _, row := range rows {
+		byThread[row.Tid] = append(byThread[row.Tid], row.TagID)
+	}
+	for _, tagIDs := range byThread {
+		for _, a := range tagIDs {
+			for _, b := range tagIDs {
+				if a == b {
+					continue
+				}
+				if counts[a] == nil {
+					counts[a] = make(map[int]int)
+				}
+				counts[a][b]++
+			}
+		}
+	}
+}
+
+// topCooccur 每个标签取共现次数最多的 limit 个（次数相同按 tag_id 升序）
+func topCooccur(counts map[int]map[int]int, limit int) []*model.TagCooccur {
+	var rows []*model.TagCooccur
+	for tagID, related := range counts {
+		list := make([]*model.TagCooccur, 0, len(related))
+		for relatedID, n := range related {
+			list = append(list, &model.TagCooccur{TagID: tagID, RelatedID: relatedID, Count: n})
+		}
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Count != list[j].Count {
+				return list[i].Count > list[j].Count
+			}
+			return list[i].RelatedID < list[j].RelatedID
+		})
+		if len(list) > limit {
+			list = list[:limit]
+		}
+		rows = append(rows, list...)
+	}
+	return rows
+}
+
+// buildRelated 按标签分组共现统计，跳过已删除（不在 byID 中）的标签
+func buildRelated(rows []*model.TagCooccur, byID map[int]*model.Tag) map[int][]*RelatedTagDTO {
+	result := make(map[int][]*RelatedTagDTO)
+	for _, row := range rows {
+		t, ok := byID[row.RelatedID]
+		if !ok {
+			continue
+		}
+		result[row.TagID] = append(result[row.TagID], &RelatedTagDTO{TagDTO: newTagDTO(t), Count: row.Count})
+	}
+	return result
+}
+
+// RunRelatedRefresher 定时重算相关标签，阻塞直到 ctx 结束
+// 多实例时通过 Redis 锁保证每个周期只有一个实例执行
+func (s *TagService) RunRelatedRefresher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ok, err := s.l2.SetNX(ctx, relatedRefreshLockKey, 1, interval/2).Result()
+			if err != nil || !ok {
+				continue
+			}
+			n, err := s.RefreshRelated(ctx)
+			if err != nil {
+				logger.Error("tag: refresh related failed", logger.String("error", err.Error()))
+				continue
+			}
+			logger.Info("tag: refreshed related tags", logger.Int("tags", n))
+		}
+	}
+}
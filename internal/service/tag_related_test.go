@@ -0,0 +1,37 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"well_go/internal/model"
+)
+
+func TestCooccur(t *testing.T) {
+	counts := make(map[int]map[int]int)
+	// 分两批累加：1+2 共现 3 次，1+3 共现 1 次，2+3 共现 1 次
+	addCooccur(counts, []*model.ThreadTag{
+		{Tid: 10, TagID: 1}, {Tid: 10, TagID: 2},
+		{Tid: 11, TagID: 1}, {Tid: 11, TagID: 2}, {Tid: 11, TagID: 3},
+		{Tid: 12, TagID: 4}, // 单标签主题不计数
+	})
+	addCooccur(counts, []*model.ThreadTag{{Tid: 13, TagID: 2}, {Tid: 13, TagID: 1}})
+
+	want := map[int]map[int]int{
+		1: {2: 3, 3: 1},
+		2: {1: 3, 3: 1},
+		3: {1: 1, 2: 1},
+	}
+	if !reflect.DeepEqual(counts, want) {
+		t.Fatalf("counts = %v, want %v", counts, want)
+	}
+
+	got := make(map[int][]int)
+	for _, row := range topCooccur(counts, 1) {
+		got[row.TagID] = append(got[row.TagID], row.RelatedID)
+	}
+	// 每个标签取前 1 个，次数相同按 tag_id 升序
+	if want := map[int][]int{1: {2}, 2: {1}, 3: {1}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("top = %v, want %v", got, want)
+	}
+}
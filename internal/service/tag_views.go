@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"well_go/internal/core/logger"
+)
+
+// 标签页浏览量缓冲，与主题浏览量相同：访问时 HINCRBY 到 Redis，定时批量落库
+const (
+	tagViewsPendingKey = "tag:views:pending" // tag_id → 未落库增量
+	tagViewsSeenPrefix = "tag:views:seen:"   // 去重标记 {tag_id}:{visitor}
+)
+
+// SetViewDedupWindow 设置同一访客重复浏览不计数的窗口
+func (s *TagService) SetViewDedupWindow(window time.Duration) {
+	s.viewWindow = window
+}
+
+// RecordView 记录一次标签页浏览，visitor（IP 等）在去重窗口内重复访问不计数
+func (s *TagService) RecordView(ctx context.Context, tagID int, visitor string) error {
+	window := s.viewWindow
+	if window <= 0 {
+		window = defaultViewWindow
+	}
+
+	first, err := s.l2.SetNX(ctx, fmt.Sprintf("%s%d:%s", tagViewsSeenPrefix, tagID, visitor), 1, window).Result()
+	if err != nil || !first {
+		return err
+	}
+	return s.l2.HIncrBy(ctx, tagViewsPendingKey, strconv.Itoa(tagID), 1).Err()
+}
+
+// pendingViews 获取尚未落库的浏览增量（Redis 不可用时视为 0）
+func (s *TagService) pendingViews(ctx context.Context, tagID int) int {
+	n, err := s.l2.HGet(ctx, tagViewsPendingKey, strconv.Itoa(tagID)).Int()
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// FlushViews 将缓冲的浏览量批量写入数据库，返回涉及的标签数
+// 写库失败时把增量加回 Redis，下次重试；不失效标签缓存，读取时叠加未落库增量
+func (s *TagService) FlushViews(ctx context.Context) (int, error) {
+	raw, err := takeViewsScript.Run(ctx, s.l2, []string{tagViewsPendingKey}).StringSlice()
+	if err != nil || len(raw) == 0 {
+		return 0, err
+	}
+
+	views := make(map[int]int64, len(raw)/2)
+	for i := 0; i+1 < len(raw); i += 2 {
+		tagID, err1 := strconv.Atoi(raw[i])
+		n, err2 := strconv.ParseInt(raw[i+1], 10, 64)
+		if err1 != nil || err2 != nil || n <= 0 {
+			continue
+		}
+		views[tagID] = n
+	}
+	if len(views) == 0 {
+		return 0, nil
+	}
+
+	if err := s.repo.AddViews(ctx, views); err != nil {
+		pipe := s.l2.Pipeline()
+		for tagID, n := range views {
+			pipe.HIncrBy(ctx, tagViewsPendingKey, strconv.Itoa(tagID), n)
+		}
+		pipe.Exec(ctx)
+		return 0, err
+	}
+	return len(views), nil
+}
+
+// RunViewFlusher 定时落库浏览量，阻塞直到 ctx 结束；退出前再 flush 一次
+func (s *TagService) RunViewFlusher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if _, err := s.FlushViews(context.Background()); err != nil {
+				logger.Error("tag views: final flush failed", logger.String("error", err.Error()))
+			}
+			return
+		case <-ticker.C:
+			if _, err := s.FlushViews(ctx); err != nil {
+				logger.Error("tag views: flush failed", logger.String("error", err.Error()))
+			}
+		}
+	}
+}
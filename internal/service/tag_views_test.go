@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"well_go/internal/repository"
+)
+
+// fakeTagViewsRepo 仅实现 AddViews 的标签仓库，记录每次落库的增量
+type fakeTagViewsRepo struct {
+	repository.TagRepository
+	flushed []map[int]int64
+	err     error
+}
+
+func (r *fakeTagViewsRepo) AddViews(ctx context.Context, views map[int]int64) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.flushed = append(r.flushed, views)
+	return nil
+}
+
+func TestTagViews(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeTagViewsRepo{}
+	s := &TagService{repo: repo, l2: newTestRedis(t)}
+	s.SetViewDedupWindow(time.Minute)
+
+	for _, v := range []struct {
+		tagID   int
+		visitor string
+	}{{1, "a"}, {1, "a"}, {1, "b"}, {2, "a"}} {
+		if err := s.RecordView(ctx, v.tagID, v.visitor); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if a, b := s.pendingViews(ctx, 1), s.pendingViews(ctx, 2); a != 2 || b != 1 {
+		t.Fatalf("pending = %d, %d, want 2, 1", a, b)
+	}
+
+	// 写库失败：增量加回，下次重试
+	repo.err = errors.New("db down")
+	if _, err := s.FlushViews(ctx); err == nil {
+		t.Fatal("expected flush error")
+	}
+	if got := s.pendingViews(ctx, 1); got != 2 {
+		t.Fatalf("pending after failed flush = %d, want 2", got)
+	}
+
+	repo.err = nil
+	if n, err := s.FlushViews(ctx); err != nil || n != 2 {
+		t.Fatalf("flush = %d, %v, want 2", n, err)
+	}
+	if got := repo.flushed[0]; len(repo.flushed) != 1 || got[1] != 2 || got[2] != 1 {
+		t.Fatalf("flushed = %v", repo.flushed)
+	}
+	if got := s.pendingViews(ctx, 1); got != 0 {
+		t.Fatalf("pending after flush = %d, want 0", got)
+	}
+}
@@ -2,8 +2,12 @@ package service
 
 import (
 	"context"
-	"encoding/json"
+	"encoding/base64"
 	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"well_go/internal/core/config"
@@ -14,66 +18,120 @@ import (
 	"well_go/internal/repository"
 
 	"github.com/redis/go-redis/v9"
-	"golang.org/x/sync/singleflight"
 )
 
-var ErrThreadNotFound = fmt.Errorf("thread not found")
+var (
+	ErrThreadNotFound      = fmt.Errorf("thread not found")
+	ErrInvalidThreadStatus = fmt.Errorf("invalid thread status")
+	ErrInvalidPublishAt    = fmt.Errorf("publish_at must be in the future for scheduled threads")
+	ErrThreadTrashed       = fmt.Errorf("thread is in trash, restore it first")
+	ErrInvalidCursor       = fmt.Errorf("invalid cursor")
+	ErrInvalidSort         = fmt.Errorf("invalid sort, expect lastpost/dateline/views/replies/hot")
+	ErrThreadStatusChanged = repository.ErrThreadStatusChanged
+)
+
+// scheduleBatch 定时发布每批处理数量
+const scheduleBatch = 100
 
 // ThreadService Thread业务服务
 type ThreadService struct {
-	repo     repository.ThreadRepository
-	l1       *pool.BigCache // L1 Cache（零GC）
-	l2       *redis.Client
-	sf       *singleflight.Group
-	l2Config *config.CacheConfig
+	repo       repository.ThreadRepository
+	revRepo    repository.ThreadRevisionRepository
+	threadTag  repository.ThreadTagRepository
+	tagRepo    repository.TagRepository
+	cache      *pool.TieredCache[ThreadDTO]
+	listCache  *pool.TieredCache[[]*ThreadListItem]
+	tidsCache  *pool.TieredCache[[]int64] // 只缓存排序结果的列表（相关主题），展示数据读取时重新获取
+	countCache *pool.TieredCache[int]
+	listGen    *pool.Generation // 按 fid 的主题列表代际
+	l2         *redis.Client
+	l2Config   *config.CacheConfig
+	indexer    ThreadIndexer
+
+	viewWindow time.Duration // 浏览去重窗口
+
+	publishHooks []PublishHook
+	forumHooks   []ForumHook
+}
+
+// PublishHook 主题上线（published=true）/下线回调：版块计数缓存、sitemap、IndexNow 等
+type PublishHook func(ctx context.Context, thread *model.Thread, published bool)
+
+// AddPublishHook 注册主题上下线回调
+func (s *ThreadService) AddPublishHook(hook PublishHook) {
+	s.publishHooks = append(s.publishHooks, hook)
+}
+
+// ForumHook 版块计数变化回调（主题移动）
+type ForumHook func(ctx context.Context, fid int)
+
+// AddForumHook 注册版块计数变化回调
+func (s *ThreadService) AddForumHook(hook ForumHook) {
+	s.forumHooks = append(s.forumHooks, hook)
+}
+
+// ThreadIndexer 主题变更同步（搜索索引）
+type ThreadIndexer interface {
+	IndexThread(ctx context.Context, tid int64)
+	RemoveThread(ctx context.Context, tid int64)
+}
+
+// SetIndexer 设置主题变更同步目标
+func (s *ThreadService) SetIndexer(indexer ThreadIndexer) {
+	s.indexer = indexer
 }
 
 func (s *ThreadService) invalidateThreadCache(tid int64) {
-	key := fmt.Sprintf("thread:%d", tid)
-	if s.l1 != nil {
-		s.l1.Remove(key)
-	}
-	s.l2.Del(context.Background(), key)
+	s.cache.Delete(context.Background(), fmt.Sprintf("thread:%d", tid))
 }
 
 // ThreadDTO Thread数据传输对象
 type ThreadDTO struct {
-	Tid      int64  `json:"tid"`
-	Fid      int    `json:"fid"`
-	Uid      int64  `json:"uid"`
-	Subject  string `json:"subject"`
-	Views    int    `json:"views"`
-	Replies  int    `json:"replies"`
-	Dateline int    `json:"dateline"`
-	Lastpost int    `json:"lastpost"`
-	Status   int    `json:"status"`
-	Message  string `json:"message,omitempty"`
+	Tid         int64  `json:"tid"`
+	Fid         int    `json:"fid"`
+	Uid         int64  `json:"uid"`
+	Subject     string `json:"subject"`
+	Views       int    `json:"views"`
+	Replies     int    `json:"replies"`
+	Dateline    int    `json:"dateline"`
+	Lastpost    int    `json:"lastpost"`
+	Status      int    `json:"status"`
+	Message     string `json:"message,omitempty"`
+	PublishAt   int    `json:"publish_at,omitempty"`
+	RedirectTid int64  `json:"redirect_tid,omitempty"` // 跳转占位：前端应跳转到该主题
 }
 
 // ThreadListItem 列表项
 type ThreadListItem struct {
-	Tid      int64  `json:"tid"`
-	Fid      int    `json:"fid"`
-	Uid      int64  `json:"uid"`
-	Subject  string `json:"subject"`
-	Views    int    `json:"views"`
-	Replies  int    `json:"replies"`
-	Dateline int    `json:"dateline"`
-	Lastpost int    `json:"lastpost"`
-	Status   int    `json:"status"`
+	Tid         int64  `json:"tid"`
+	Fid         int    `json:"fid"`
+	Uid         int64  `json:"uid"`
+	Subject     string `json:"subject"`
+	Views       int    `json:"views"`
+	Replies     int    `json:"replies"`
+	Dateline    int    `json:"dateline"`
+	Lastpost    int    `json:"lastpost"`
+	Status      int    `json:"status"`
+	RedirectTid int64  `json:"redirect_tid,omitempty"`
 }
 
 // NewThreadService 创建ThreadService实例
-func NewThreadService(repo repository.ThreadRepository, l2 *redis.Client, l2Config *config.CacheConfig) *ThreadService {
+func NewThreadService(repo repository.ThreadRepository, revRepo repository.ThreadRevisionRepository, threadTag repository.ThreadTagRepository, tagRepo repository.TagRepository, l2 *redis.Client, l2Config *config.CacheConfig) *ThreadService {
 	// L1使用bigcache（零GC）
 	l1Cache, _ := pool.NewBigCache(l2Config.L1Cap, time.Duration(l2Config.L2TTL)*time.Second)
 
 	return &ThreadService{
-		repo:     repo,
-		l1:       l1Cache,
-		l2:       l2,
-		sf:       &singleflight.Group{},
-		l2Config: l2Config,
+		repo:       repo,
+		revRepo:    revRepo,
+		threadTag:  threadTag,
+		tagRepo:    tagRepo,
+		cache:      newTieredCache[ThreadDTO]("thread", l1Cache, l2, l2Config, pool.BinaryCodec[ThreadDTO, *ThreadDTO]{}),
+		listCache:  newTieredCache[[]*ThreadListItem]("thread_list", l1Cache, l2, l2Config, nil),
+		tidsCache:  newTieredCache[[]int64]("thread_tids", l1Cache, l2, l2Config, nil),
+		countCache: newTieredCache[int]("thread_count", l1Cache, l2, l2Config, nil),
+		listGen:    pool.NewGeneration(l2, "thread:list:gen"),
+		l2:         l2,
+		l2Config:   l2Config,
 	}
 }
 
@@ -81,42 +139,7 @@ func NewThreadService(repo repository.ThreadRepository, l2 *redis.Client, l2Conf
 func (s *ThreadService) Get(ctx context.Context, tid int64) (*ThreadDTO, error) {
 	key := fmt.Sprintf("thread:%d", tid)
 
-	// L1 Cache
-	if s.l1 != nil {
-		if data, ok := s.l1.Get(key); ok {
-			if data != nil {
-				var dto ThreadDTO
-				if err := json.Unmarshal(data, &dto); err == nil {
-					return &dto, nil
-				}
-			}
-		}
-	}
-
-	// L2 Cache
-	ctxL2 := context.Background()
-	if v, err := s.l2.Get(ctxL2, key).Bytes(); err == nil {
-		var dto ThreadDTO
-		if err := dto.UnmarshalBinary(v); err == nil {
-codex/review-go-code-for-headless-backend-ws68yo
-			if s.l1 != nil {
-				if bytes, _ := json.Marshal(&dto); bytes != nil {
-					s.l1.Set(key, bytes)
-				}
-
-			// Write L1
-				if s.l1 != nil {
-					if bytes, _ := json.Marshal(&dto); bytes != nil {
-						s.l1.Set(key, bytes)
-					}
-				}
-				return &dto, nil
-main
-			}
-	}
-
-	// SingleFlight + DB
-	v, err, _ := s.sf.Do(key, func() (interface{}, error) {
+	dto, err := s.cache.Get(ctx, key, func(ctx context.Context) (*ThreadDTO, error) {
 		thread, err := s.repo.GetByID(ctx, tid)
 		if err != nil {
 			return nil, err
@@ -127,128 +150,277 @@ main
 
 		data, _ := s.repo.GetContentByID(ctx, tid)
 		dto := &ThreadDTO{
-			Tid:      thread.Tid,
-			Fid:      thread.Fid,
-			Uid:      thread.Uid,
-			Subject:  thread.Subject,
-			Views:    thread.Views,
-			Replies:  thread.Replies,
-			Dateline: int(thread.Dateline),
-			Lastpost: int(thread.Lastpost),
-			Status:   thread.Status,
+			Tid:         thread.Tid,
+			Fid:         thread.Fid,
+			Uid:         thread.Uid,
+			Subject:     thread.Subject,
+			Views:       thread.Views,
+			Replies:     thread.Replies,
+			Dateline:    int(thread.Dateline),
+			Lastpost:    int(thread.Lastpost),
+			Status:      thread.Status,
+			PublishAt:   thread.PublishAt,
+			RedirectTid: thread.RedirectTid,
 		}
 		if data != nil {
 			dto.Message = data.Message
 		}
+		return dto, nil
+	})
+	if err != nil || dto == nil {
+		return dto, err
+	}
 
-		// Write Cache
-		if bytes, err := dto.MarshalBinary(); err == nil {
-			s.l2.Set(ctxL2, key, bytes, time.Duration(s.l2Config.L2TTL)*time.Second)
-		}
-codex/review-go-code-for-headless-backend-ws68yo
-		if s.l1 != nil {
-			if bytes, _ := json.Marshal(&dto); bytes != nil {
-				s.l1.Set(key, bytes)
-			}
+	// 缓存中是落库时的浏览量，叠加尚未落库的增量（返回副本，不修改共享的缓存对象）
+	live := *dto
+	live.Views += int(s.pendingViews(ctx, tid)[tid])
+	return &live, nil
+}
+
+// List 获取版块Thread列表，sort 取 model.ThreadSort*
+func (s *ThreadService) List(ctx context.Context, fid int, sort string, page, pageSize int) ([]*ThreadListItem, error) {
+	if !model.ValidThreadSort(sort) {
+		return nil, ErrInvalidSort
+	}
+	key := listKey(fid, s.listGen.GetInt64(ctx, int64(fid)), sort, page, pageSize)
+
+	return s.cachedList(ctx, key, func(ctx context.Context) ([]int64, error) {
+		if sort == model.ThreadSortLastpost {
+			return s.repo.GetListTIDsByFid(ctx, fid, (page-1)*pageSize, pageSize)
 		}
+		filter := repository.ThreadListFilter{Fids: []int{fid}, Sort: sort, WithStubs: true}
+		return s.repo.GetListTIDs(ctx, filter, (page-1)*pageSize, pageSize)
+	})
+}
 
-		// Write L1
-			if s.l1 != nil {
-				if bytes, _ := json.Marshal(&dto); bytes != nil {
-					s.l1.Set(key, bytes)
-				}
-			}
-main
+// FeedQuery 跨版块 feed 条件
+type FeedQuery struct {
+	Fids  []int  // 可读版块（全站或某版块及其子孙）；nil 表示不限，空切片表示无可读版块
+	Uid   int64  // 只看某用户的主题，0 表示不限
+	TagID int    // 只看某标签下的主题，0 表示不限
+	Sort  string // model.ThreadSort*
+}
 
-		return dto, nil
+// Feed 跨版块主题列表（全站最新、版块树、用户主题）
+// 缓存 key 使用全局列表代际：任意版块列表失效时 feed 一并失效
+func (s *ThreadService) Feed(ctx context.Context, q FeedQuery, page, pageSize int) ([]*ThreadListItem, error) {
+	if !model.ValidThreadSort(q.Sort) {
+		return nil, ErrInvalidSort
+	}
+	if q.Fids != nil && len(q.Fids) == 0 {
+		return []*ThreadListItem{}, nil
+	}
+
+	tagGen := int64(0)
+	if q.TagID > 0 {
+		tagGen = s.listGen.Get(ctx, tagListGen(q.TagID))
+	}
+	key := feedKey(s.listGen.Get(ctx, listGenAll), tagGen, q, page, pageSize)
+
+	return s.cachedList(ctx, key, func(ctx context.Context) ([]int64, error) {
+		filter := repository.ThreadListFilter{Fids: q.Fids, Uid: q.Uid, TagID: q.TagID, Sort: q.Sort}
+		return s.repo.GetListTIDs(ctx, filter, (page-1)*pageSize, pageSize)
 	})
+}
+
+// FeedCount 与 Feed 条件一致的主题总数，随 feed 代际失效
+func (s *ThreadService) FeedCount(ctx context.Context, q FeedQuery) (int, error) {
+	if !model.ValidThreadSort(q.Sort) {
+		return 0, ErrInvalidSort
+	}
+	if q.Fids != nil && len(q.Fids) == 0 {
+		return 0, nil
+	}
 
+	tagGen := int64(0)
+	if q.TagID > 0 {
+		tagGen = s.listGen.Get(ctx, tagListGen(q.TagID))
+	}
+	key := feedKey(s.listGen.Get(ctx, listGenAll), tagGen, q, 0, 0) + ":count"
+
+	total, err := s.countCache.Get(ctx, key, func(ctx context.Context) (*int, error) {
+		filter := repository.ThreadListFilter{Fids: q.Fids, Uid: q.Uid, TagID: q.TagID, Sort: q.Sort}
+		n, err := s.repo.CountListTIDs(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		return &n, nil
+	})
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
-	if v == nil {
-		return nil, nil
+	return *total, nil
+}
+
+// listKey 版块列表缓存 key，每种排序独立缓存
+func listKey(fid int, gen int64, sort string, page, pageSize int) string {
+	return fmt.Sprintf("thread:list:%d:%d:%s:%d:%d", fid, gen, sort, page, pageSize)
+}
+
+// feedKey feed 缓存 key：全局代际 + 用户 + 标签及其代际 + 可读版块摘要 + 排序
+func feedKey(gen, tagGen int64, q FeedQuery, page, pageSize int) string {
+	return fmt.Sprintf("thread:feed:%d:u%d:t%d.%d:%s:%s:%d:%d",
+		gen, q.Uid, q.TagID, tagGen, fidsDigest(q.Fids), q.Sort, page, pageSize)
+}
+
+// fidsDigest 版块集合摘要（与顺序无关），不同用户组可读范围不同，需要区分缓存
+func fidsDigest(fids []int) string {
+	if fids == nil {
+		return "all"
+	}
+	sorted := append([]int(nil), fids...)
+	sort.Ints(sorted)
+	h := fnv.New64a()
+	for _, fid := range sorted {
+		h.Write([]byte(strconv.Itoa(fid)))
+		h.Write([]byte{','})
 	}
-	return v.(*ThreadDTO), nil
+	return strconv.FormatUint(h.Sum64(), 36)
 }
 
-// List 获取Thread列表
-func (s *ThreadService) List(ctx context.Context, fid int, page, pageSize int) ([]*ThreadListItem, error) {
-	key := fmt.Sprintf("thread:list:%d:%d:%d", fid, page, pageSize)
+// ListAfter 游标分页获取Thread列表，返回下一页游标（没有更多时为空）
+func (s *ThreadService) ListAfter(ctx context.Context, fid int, cursor string, pageSize int) ([]*ThreadListItem, string, error) {
+	lastpost, tid, err := decodeListCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
 
-	if s.l1 != nil {
-		if data, ok := s.l1.Get(key); ok && data != nil {
-			var list []*ThreadListItem
-			if err := json.Unmarshal(data, &list); err == nil {
-				return list, nil
-			}
+	loadTids := func(ctx context.Context) ([]int64, error) {
+		return s.repo.GetListTIDsByFidAfter(ctx, fid, lastpost, tid, pageSize)
+	}
+	// 只缓存第一页；后续页游标随 lastpost 变化几乎不会重复命中，直接回源
+	var list []*ThreadListItem
+	if cursor == "" {
+		key := fmt.Sprintf("thread:list:%d:%d:c0_0:%d", fid, s.listGen.GetInt64(ctx, int64(fid)), pageSize)
+		list, err = s.cachedList(ctx, key, loadTids)
+	} else {
+		var loaded *[]*ThreadListItem
+		if loaded, err = s.loadList(ctx, loadTids); err == nil {
+			list = s.withPendingViews(ctx, *loaded)
 		}
 	}
+	if err != nil {
+		return nil, "", err
+	}
 
-	if data, err := s.l2.Get(ctx, key).Bytes(); err == nil {
-		var list []*ThreadListItem
-		if err := json.Unmarshal(data, &list); err == nil {
-			if s.l1 != nil {
-				s.l1.Set(key, data)
-			}
-			return list, nil
-		}
+	next := ""
+	if len(list) == pageSize {
+		last := list[len(list)-1]
+		next = encodeListCursor(last.Lastpost, last.Tid)
 	}
+	return list, next, nil
+}
 
-	offset := (page - 1) * pageSize
-	tids, err := s.repo.GetListTIDsByFid(ctx, fid, offset, pageSize)
+// cachedList 按 key 缓存主题列表（tids 回源），返回叠加了未落库浏览量的副本
+func (s *ThreadService) cachedList(ctx context.Context, key string, loadTids func(ctx context.Context) ([]int64, error)) ([]*ThreadListItem, error) {
+	list, err := s.listCache.Get(ctx, key, func(ctx context.Context) (*[]*ThreadListItem, error) {
+		return s.loadList(ctx, loadTids)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.withPendingViews(ctx, *list), nil
+}
+
+// loadList 按 tids 回源组装主题列表
+func (s *ThreadService) loadList(ctx context.Context, loadTids func(ctx context.Context) ([]int64, error)) (*[]*ThreadListItem, error) {
+	tids, err := loadTids(ctx)
 	if err != nil {
 		return nil, err
 	}
+
+	list := make([]*ThreadListItem, 0, len(tids))
 	if len(tids) == 0 {
-		return []*ThreadListItem{}, nil
+		return &list, nil
 	}
 
 	threads, err := s.repo.GetByTIDs(ctx, tids)
 	if err != nil {
 		return nil, err
 	}
-
-	list := make([]*ThreadListItem, 0, len(threads))
 	for _, t := range threads {
 		list = append(list, &ThreadListItem{
-			Tid:      t.Tid,
-			Fid:      t.Fid,
-			Uid:      t.Uid,
-			Subject:  t.Subject,
-			Views:    t.Views,
-			Replies:  t.Replies,
-			Dateline: int(t.Dateline),
-			Lastpost: int(t.Lastpost),
-			Status:   t.Status,
+			Tid:         t.Tid,
+			Fid:         t.Fid,
+			Uid:         t.Uid,
+			Subject:     t.Subject,
+			Views:       t.Views,
+			Replies:     t.Replies,
+			Dateline:    int(t.Dateline),
+			Lastpost:    int(t.Lastpost),
+			Status:      t.Status,
+			RedirectTid: t.RedirectTid,
 		})
 	}
+	return &list, nil
+}
 
-	if data, _ := json.Marshal(list); data != nil {
-		if s.l1 != nil {
-			s.l1.Set(key, data)
-		}
-		s.l2.Set(ctx, key, data, time.Duration(s.l2Config.L2TTL)*time.Second)
+// withPendingViews 返回叠加了未落库浏览量的副本（不修改缓存中的条目）
+func (s *ThreadService) withPendingViews(ctx context.Context, list []*ThreadListItem) []*ThreadListItem {
+	tids := make([]int64, 0, len(list))
+	for _, item := range list {
+		tids = append(tids, item.Tid)
 	}
+	pending := s.pendingViews(ctx, tids...)
+	items := make([]*ThreadListItem, 0, len(list))
+	for _, item := range list {
+		live := *item
+		live.Views += int(pending[item.Tid])
+		items = append(items, &live)
+	}
+	return items
+}
 
-	return list, nil
+// encodeListCursor 游标对客户端不透明：base64url("lastpost:tid")
+func encodeListCursor(lastpost int, tid int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d", lastpost, tid)))
+}
+
+// decodeListCursor 空游标表示第一页
+func decodeListCursor(cursor string) (int, int64, error) {
+	if cursor == "" {
+		return 0, 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, 0, ErrInvalidCursor
+	}
+	lp, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return 0, 0, ErrInvalidCursor
+	}
+	lastpost, err1 := strconv.Atoi(lp)
+	tid, err2 := strconv.ParseInt(id, 10, 64)
+	if err1 != nil || err2 != nil || lastpost < 0 || tid < 0 {
+		return 0, 0, ErrInvalidCursor
+	}
+	return lastpost, tid, nil
 }
 
 // Create 创建Thread
-func (s *ThreadService) Create(ctx context.Context, fid int64, uid int64, subject, message string) (*ThreadDTO, error) {
-	now := time.Now().Unix()
+// status 为 ThreadScheduled 时 publishAt 必须晚于当前时间；直接发布时同步版块计数
+func (s *ThreadService) Create(ctx context.Context, fid int64, uid int64, subject, message string, status int, publishAt int) (*ThreadDTO, error) {
+	now := int(time.Now().Unix())
+	if err := validateThreadStatus(status, publishAt, now); err != nil {
+		return nil, err
+	}
+	if status != model.ThreadScheduled {
+		publishAt = 0
+	}
+
 	tid := snowflake.Generate()
 
 	thread := &model.Thread{
-		Tid:      tid,
-		Fid:      int(fid),
-		Uid:      uid,
-		Subject:  subject,
-		Views:    0,
-		Replies:  0,
-		Dateline: int(now),
-		Lastpost: int(now),
-		Status:   0,
+		Tid:       tid,
+		Fid:       int(fid),
+		Uid:       uid,
+		Subject:   subject,
+		Views:     0,
+		Replies:   0,
+		Dateline:  now,
+		Lastpost:  now,
+		Status:    status,
+		PublishAt: publishAt,
 	}
 
 	content := &model.ThreadData{
@@ -260,23 +432,29 @@ func (s *ThreadService) Create(ctx context.Context, fid int64, uid int64, subjec
 		logger.Error("create thread failed", logger.String("error", err.Error()))
 		return nil, err
 	}
+	if status == model.ThreadPublished {
+		s.afterStatusChange(ctx, thread, true)
+	}
 
 	return &ThreadDTO{
-		Tid:      tid,
-		Fid:      thread.Fid,
-		Uid:      thread.Uid,
-		Subject:  subject,
-		Views:    0,
-		Replies:  0,
-		Dateline: int(now),
-		Lastpost: int(now),
-		Status:   0,
-		Message:  message,
+		Tid:       tid,
+		Fid:       thread.Fid,
+		Uid:       thread.Uid,
+		Subject:   subject,
+		Views:     0,
+		Replies:   0,
+		Dateline:  now,
+		Lastpost:  now,
+		Status:    status,
+		Message:   message,
+		PublishAt: publishAt,
 	}, nil
 }
 
 // Update 更新Thread
-func (s *ThreadService) Update(ctx context.Context, tid int64, subject string, status int) error {
+// subject 为空、message / newStatus 为 nil 时保持原值；标题或内容变化时记录修订（uid 为编辑人）。
+// 状态跨越"已发布"边界时走 Publish/Unpublish，保证版块计数、sitemap 等只在上下线时变更
+func (s *ThreadService) Update(ctx context.Context, tid, uid int64, subject string, message *string, newStatus *int, publishAt int) error {
 	thread, err := s.repo.GetByID(ctx, tid)
 	if err != nil {
 		return err
@@ -284,30 +462,210 @@ func (s *ThreadService) Update(ctx context.Context, tid int64, subject string, s
 	if thread == nil {
 		return ErrThreadNotFound
 	}
+	if thread.Status == model.ThreadTrashed {
+		return ErrThreadTrashed
+	}
+
+	if subject == "" {
+		subject = thread.Subject
+	}
+	var rev *model.ThreadRevision
+	if subject != thread.Subject || message != nil {
+		content, err := s.repo.GetContentByID(ctx, tid)
+		if err != nil {
+			return err
+		}
+		current := ""
+		if content != nil {
+			current = content.Message
+		}
+		if message != nil && *message == current {
+			message = nil
+		}
+		if subject != thread.Subject || message != nil {
+			rev = &model.ThreadRevision{Uid: uid, Subject: subject, Message: current, Action: model.RevisionEdit}
+			if message != nil {
+				rev.Message = *message
+			}
+		}
+	}
+
+	now := int(time.Now().Unix())
+	status := thread.Status // 未指定时保持原状态
+	if newStatus != nil {
+		status = *newStatus
+	}
+	if status == model.ThreadScheduled && thread.Status == model.ThreadScheduled && publishAt == 0 {
+		publishAt = thread.PublishAt // 未指定时沿用原定时
+	}
+	if status != thread.Status || publishAt != thread.PublishAt {
+		if err := validateThreadStatus(status, publishAt, now); err != nil {
+			return err
+		}
+	}
+	if status != model.ThreadScheduled {
+		publishAt = 0
+	}
+
+	prevStatus := thread.Status
+	wasPublished := prevStatus == model.ThreadPublished
+	willPublish := status == model.ThreadPublished
 
 	thread.Subject = subject
-	thread.Status = status
-	thread.Lastpost = int(time.Now().Unix())
+	thread.PublishAt = publishAt
+	thread.Lastpost = now
+	if wasPublished == willPublish {
+		thread.Status = status
+	}
 
-	if err := s.repo.Update(ctx, thread); err != nil {
+	if rev != nil {
+		rev.Dateline = now
+		err = s.repo.Edit(ctx, thread, prevStatus, message, rev)
+	} else {
+		err = s.repo.Update(ctx, thread, prevStatus)
+	}
+	if err != nil {
 		return err
 	}
 
+	switch {
+	case !wasPublished && willPublish:
+		_, err = s.Publish(ctx, tid)
+		return err
+	case wasPublished && !willPublish:
+		return s.unpublish(ctx, thread, status)
+	}
+
 	// Invalidate Cache
- codex/review-go-code-for-headless-backend-ws68yo
 	s.invalidateThreadCache(tid)
-=======
-	key := fmt.Sprintf("thread:%d", tid)
-	if s.l1 != nil {
-		s.l1.Remove(key)
+	if willPublish {
+		s.invalidateThreadList(ctx, thread.Fid) // lastpost/标题变化影响列表
+	}
+	if s.indexer != nil {
+		s.indexer.IndexThread(ctx, tid)
+	}
+
+	return nil
+}
+
+// Publish 立即发布主题（审核通过/定时到期），已发布时返回 false
+func (s *ThreadService) Publish(ctx context.Context, tid int64) (bool, error) {
+	ok, err := s.repo.Publish(ctx, tid, int(time.Now().Unix()))
+	if err != nil || !ok {
+		return false, err
+	}
+
+	thread, err := s.repo.GetByID(ctx, tid)
+	if err != nil {
+		return true, err
+	}
+	if thread != nil {
+		s.afterStatusChange(ctx, thread, true)
+	}
+	return true, nil
+}
+
+func (s *ThreadService) unpublish(ctx context.Context, thread *model.Thread, status int) error {
+	ok, err := s.repo.Unpublish(ctx, thread.Tid, status)
+	if err != nil {
+		return err
+	}
+	if ok {
+		thread.Status = status
+		s.afterStatusChange(ctx, thread, false)
+	}
+	return nil
+}
+
+// afterStatusChange 主题上线/下线后的缓存、索引与外部通知
+func (s *ThreadService) afterStatusChange(ctx context.Context, thread *model.Thread, published bool) {
+	s.invalidateThreadCache(thread.Tid)
+	s.invalidateThreadList(ctx, thread.Fid)
+	if s.indexer != nil {
+		s.indexer.IndexThread(ctx, thread.Tid)
+	}
+	for _, hook := range s.publishHooks {
+		hook(ctx, thread, published)
+	}
+}
+
+// listGenAll 全局列表代际（跨版块 feed 使用）
+const listGenAll = "all"
+
+// tagListGen 标签主题列表代际 id
+func tagListGen(tagID int) string {
+	return "tag" + strconv.Itoa(tagID)
+}
+
+// OnThreadTagChange 主题增删标签后使该标签的主题列表及该主题的相关推荐失效
+func (s *ThreadService) OnThreadTagChange(ctx context.Context, tid int64, tagID int) {
+	s.listGen.Bump(ctx, tagListGen(tagID))
+	if tid > 0 {
+		s.tidsCache.Delete(ctx, relatedKey(tid))
 	}
-	s.l2.Del(context.Background(), key)
- main
+}
 
+// invalidateThreadList 使版块所有已缓存的主题列表页及跨版块 feed 失效（代际 +1，旧 key 随 TTL 过期）
+func (s *ThreadService) invalidateThreadList(ctx context.Context, fid int) {
+	s.listGen.BumpInt64(ctx, int64(fid))
+	s.listGen.Bump(ctx, listGenAll)
+}
+
+// RunScheduler 定时发布调度，阻塞直到 ctx 结束
+// 多实例同时运行时由 Publish 的条件更新保证每个主题只发布一次
+func (s *ThreadService) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.publishDue(ctx)
+		}
+	}
+}
+
+// publishDue 分批发布到期主题；发布失败的主题仍会出现在下一批中，
+// 某一批全部失败时停止，留待下次调度重试，避免反复处理同一批
+func (s *ThreadService) publishDue(ctx context.Context) {
+	for {
+		tids, err := s.repo.GetDueScheduled(ctx, int(time.Now().Unix()), scheduleBatch)
+		if err != nil {
+			logger.Error("scheduler: get due threads failed", logger.String("error", err.Error()))
+			return
+		}
+		done := 0
+		for _, tid := range tids {
+			ok, err := s.Publish(ctx, tid)
+			if err != nil {
+				logger.Error("scheduler: publish failed", logger.Int64("tid", tid), logger.String("error", err.Error()))
+				continue
+			}
+			done++
+			if ok {
+				logger.Info("scheduler: thread published", logger.Int64("tid", tid))
+			}
+		}
+		if len(tids) < scheduleBatch || done == 0 {
+			return
+		}
+	}
+}
+
+func validateThreadStatus(status, publishAt, now int) error {
+	// 回收站只能通过 Delete/Restore 进出，以便维护计数与恢复原状态
+	if !model.ValidThreadStatus(status) || status == model.ThreadTrashed {
+		return ErrInvalidThreadStatus
+	}
+	if status == model.ThreadScheduled && publishAt <= now {
+		return ErrInvalidPublishAt
+	}
 	return nil
 }
 
-// Delete 删除Thread
+// Delete 将Thread移入回收站（到期由 TrashService 彻底清除）
 func (s *ThreadService) Delete(ctx context.Context, tid int64) error {
 	thread, err := s.repo.GetByID(ctx, tid)
 	if err != nil {
@@ -317,49 +675,50 @@ func (s *ThreadService) Delete(ctx context.Context, tid int64) error {
 		return ErrThreadNotFound
 	}
 
-	if err := s.repo.Delete(ctx, tid); err != nil {
+	ok, err := s.repo.Trash(ctx, tid, int(time.Now().Unix()))
+	if err != nil || !ok {
 		return err
 	}
 
-	// Invalidate Cache
-codex/review-go-code-for-headless-backend-ws68yo
-	s.invalidateThreadCache(tid)
+	thread.Status = model.ThreadTrashed
+	s.afterStatusChange(ctx, thread, false)
+	return nil
+}
 
-	key := fmt.Sprintf("thread:%d", tid)
-	if s.l1 != nil {
-		s.l1.Remove(key)
+// Restore 从回收站恢复Thread到删除前的状态，不在回收站时返回 false
+func (s *ThreadService) Restore(ctx context.Context, tid int64) (bool, error) {
+	ok, err := s.repo.Restore(ctx, tid)
+	if err != nil || !ok {
+		return false, err
 	}
-	s.l2.Del(context.Background(), key)
-main
 
-	return nil
+	thread, err := s.repo.GetByID(ctx, tid)
+	if err != nil {
+		return true, err
+	}
+	if thread != nil {
+		s.afterStatusChange(ctx, thread, thread.Status == model.ThreadPublished)
+	}
+	return true, nil
 }
 
-// IncViews 增加浏览量
-func (s *ThreadService) IncViews(ctx context.Context, tid int64) error {
-	if err := s.repo.IncViews(ctx, tid); err != nil {
-		return err
+// Purge 彻底删除回收站中的Thread，不在回收站时返回 false
+func (s *ThreadService) Purge(ctx context.Context, tid int64) (bool, error) {
+	ok, err := s.repo.Purge(ctx, tid)
+	if err != nil || !ok {
+		return false, err
 	}
 
-	// Invalidate Cache
-codex/review-go-code-for-headless-backend-ws68yo
 	s.invalidateThreadCache(tid)
-
-	key := fmt.Sprintf("thread:%d", tid)
-	if s.l1 != nil {
-		s.l1.Remove(key)
+	if s.indexer != nil {
+		s.indexer.RemoveThread(ctx, tid)
 	}
-	s.l2.Del(context.Background(), key)
- main
-
-	return nil
+	return true, nil
 }
 
 // FlushCache 刷新缓存
 func (s *ThreadService) FlushCache(ctx context.Context) error {
-	if s.l1 != nil {
-		s.l1.Flush()
-	}
-	// Redis flush 需要单独处理
+	// 广播到所有实例的 L1；Redis flush 需要单独处理
+	pool.InvalidatePrefix(ctx, "thread:")
 	return nil
 }
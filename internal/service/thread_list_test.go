@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"testing"
+
+	"well_go/internal/core/config"
+	"well_go/internal/core/logger"
+	"well_go/internal/model"
+	"well_go/internal/repository"
+)
+
+var allThreadSorts = []string{
+	model.ThreadSortLastpost,
+	model.ThreadSortDateline,
+	model.ThreadSortViews,
+	model.ThreadSortReplies,
+	model.ThreadSortHot,
+}
+
+func TestFidsDigest(t *testing.T) {
+	if got := fidsDigest(nil); got != "all" {
+		t.Fatalf("fidsDigest(nil) = %q, want all", got)
+	}
+	// 与顺序无关
+	if a, b := fidsDigest([]int{3, 1, 2}), fidsDigest([]int{1, 2, 3}); a != b {
+		t.Fatalf("order dependent: %q != %q", a, b)
+	}
+
+	seen := map[string][]int{}
+	for _, fids := range [][]int{{}, {1}, {1, 2}, {1, 2, 3}, {12, 3}, {1, 23}, {2}} {
+		d := fidsDigest(fids)
+		if d == "all" {
+			t.Errorf("fidsDigest(%v) collides with nil", fids)
+		}
+		if prev, ok := seen[d]; ok {
+			t.Errorf("fidsDigest(%v) = fidsDigest(%v) = %q", fids, prev, d)
+		}
+		seen[d] = fids
+	}
+}
+
+func TestListKeysPerSort(t *testing.T) {
+	seen := map[string]string{}
+	check := func(key, desc string) {
+		t.Helper()
+		if prev, ok := seen[key]; ok {
+			t.Errorf("%s and %s share key %q", desc, prev, key)
+		}
+		seen[key] = desc
+	}
+
+	for _, sort := range allThreadSorts {
+		check(listKey(1, 0, sort, 1, 20), "list "+sort)
+		check(feedKey(0, 0, FeedQuery{Sort: sort}, 1, 20), "feed "+sort)
+		check(feedKey(0, 0, FeedQuery{Fids: []int{1}, Sort: sort}, 1, 20), "forum feed "+sort)
+		check(feedKey(0, 0, FeedQuery{Uid: 7, Sort: sort}, 1, 20), "user feed "+sort)
+		check(feedKey(0, 3, FeedQuery{TagID: 5, Sort: sort}, 1, 20), "tag feed "+sort)
+	}
+
+	// 代际变化后 key 随之变化，旧缓存不再命中
+	if listKey(1, 0, model.ThreadSortHot, 1, 20) == listKey(1, 1, model.ThreadSortHot, 1, 20) {
+		t.Error("list key ignores generation")
+	}
+	if feedKey(0, 3, FeedQuery{TagID: 5}, 1, 20) == feedKey(0, 4, FeedQuery{TagID: 5}, 1, 20) {
+		t.Error("feed key ignores tag generation")
+	}
+}
+
+func TestListCursor(t *testing.T) {
+	for _, c := range []struct {
+		lastpost int
+		tid      int64
+	}{{0, 0}, {1700000000, 1}, {1700000000, snowflakeUID}} {
+		lastpost, tid, err := decodeListCursor(encodeListCursor(c.lastpost, c.tid))
+		if err != nil || lastpost != c.lastpost || tid != c.tid {
+			t.Errorf("round trip (%d, %d) = (%d, %d, %v)", c.lastpost, c.tid, lastpost, tid, err)
+		}
+	}
+
+	if lastpost, tid, err := decodeListCursor(""); err != nil || lastpost != 0 || tid != 0 {
+		t.Fatalf("empty cursor = (%d, %d, %v)", lastpost, tid, err)
+	}
+
+	b64 := base64.RawURLEncoding.EncodeToString
+	for _, cursor := range []string{
+		"not base64!",
+		b64([]byte("1700000000")),
+		b64([]byte("abc:1")),
+		b64([]byte("1700000000:x")),
+		b64([]byte("-1:5")),
+		b64([]byte("1700000000:-5")),
+		b64([]byte("1700000000:99999999999999999999")),
+		encodeListCursor(1700000000, 5) + "A",
+	} {
+		if _, _, err := decodeListCursor(cursor); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("decodeListCursor(%q) err = %v, want ErrInvalidCursor", cursor, err)
+		}
+	}
+}
+
+// cursorThreadRepo 按 (lastpost, tid) 倒序分页的内存仓库，与 SQL 条件一致
+type cursorThreadRepo struct {
+	repository.ThreadRepository
+	threads []*model.Thread // 已按 lastpost DESC, tid DESC 排序
+	queries int
+}
+
+func (r *cursorThreadRepo) GetListTIDsByFidAfter(ctx context.Context, fid int, lastpost int, tid int64, limit int) ([]int64, error) {
+	r.queries++
+	var tids []int64
+	for _, t := range r.threads {
+		first := lastpost == 0 && tid == 0
+		if first || t.Lastpost < lastpost || (t.Lastpost == lastpost && t.Tid < tid) {
+			tids = append(tids, t.Tid)
+		}
+		if len(tids) == limit {
+			break
+		}
+	}
+	return tids, nil
+}
+
+func (r *cursorThreadRepo) GetByTIDs(ctx context.Context, tids []int64) ([]*model.Thread, error) {
+	byTid := make(map[int64]*model.Thread, len(r.threads))
+	for _, t := range r.threads {
+		byTid[t.Tid] = t
+	}
+	threads := make([]*model.Thread, 0, len(tids))
+	for _, tid := range tids {
+		threads = append(threads, byTid[tid])
+	}
+	return threads, nil
+}
+
+// TestListAfterTies lastpost 相同的主题按 tid 分页，不重复不遗漏；只缓存第一页
+func TestListAfterTies(t *testing.T) {
+	if err := logger.Init(&config.LoggingConfig{Level: "error"}); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	repo := &cursorThreadRepo{}
+	for tid := int64(7); tid >= 1; tid-- {
+		lastpost := 100
+		if tid <= 2 {
+			lastpost = 50
+		}
+		repo.threads = append(repo.threads, &model.Thread{Tid: tid, Fid: 1, Lastpost: lastpost})
+	}
+	s := NewThreadService(repo, nil, nil, nil, newTestRedis(t), &config.CacheConfig{L1Cap: 8, L2TTL: 60})
+
+	var got []int64
+	cursor := ""
+	for page := 0; page < 10; page++ {
+		list, next, err := s.ListAfter(ctx, 1, cursor, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, item := range list {
+			got = append(got, item.Tid)
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if want := []int64{7, 6, 5, 4, 3, 2, 1}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("paged tids = %v, want %v", got, want)
+	}
+
+	// 第一页命中缓存，后续页每次回源
+	queries := repo.queries
+	if _, _, err := s.ListAfter(ctx, 1, "", 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.ListAfter(ctx, 1, encodeListCursor(100, 6), 2); err != nil {
+		t.Fatal(err)
+	}
+	if repo.queries != queries+1 {
+		t.Fatalf("queries = %d, want %d (first page cached, later pages not)", repo.queries, queries+1)
+	}
+}
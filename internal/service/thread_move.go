@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"well_go/internal/core/snowflake"
+)
+
+var ErrMoveBatchTooLarge = fmt.Errorf("too many threads, at most %d per move", maxMoveBatch)
+
+// maxMoveBatch 单次批量移动的主题数上限
+const maxMoveBatch = 100
+
+// MoveResult 主题移动结果
+type MoveResult struct {
+	Moved   []int64 `json:"moved"`
+	Skipped []int64 `json:"skipped"` // 不存在、已在目标版块或本身是跳转占位
+}
+
+// Move 将主题移动到 toFid，两侧版块计数在同一事务中对账
+// redirect=true 时在原版块为已发布主题保留跳转占位
+func (s *ThreadService) Move(ctx context.Context, tids []int64, toFid int, redirect bool) (*MoveResult, error) {
+	seen := make(map[int64]struct{}, len(tids))
+	unique := make([]int64, 0, len(tids))
+	for _, tid := range tids {
+		if _, ok := seen[tid]; ok || tid <= 0 {
+			continue
+		}
+		seen[tid] = struct{}{}
+		unique = append(unique, tid)
+	}
+	if len(unique) > maxMoveBatch {
+		return nil, ErrMoveBatchTooLarge
+	}
+
+	var stubs map[int64]int64
+	if redirect {
+		stubs = make(map[int64]int64, len(unique))
+		for _, tid := range unique {
+			stubs[tid] = snowflake.Generate()
+		}
+	}
+
+	moved, err := s.repo.Move(ctx, unique, toFid, stubs)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrForumNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MoveResult{Moved: make([]int64, 0, len(moved)), Skipped: []int64{}}
+	fids := map[int]struct{}{toFid: {}}
+	for _, t := range moved {
+		result.Moved = append(result.Moved, t.Tid)
+		delete(seen, t.Tid)
+		fids[t.Fid] = struct{}{}
+
+		s.invalidateThreadCache(t.Tid)
+		if s.indexer != nil {
+			s.indexer.IndexThread(ctx, t.Tid)
+		}
+	}
+	for _, tid := range unique {
+		if _, ok := seen[tid]; ok {
+			result.Skipped = append(result.Skipped, tid)
+		}
+	}
+
+	if len(moved) > 0 {
+		for fid := range fids {
+			s.invalidateThreadList(ctx, fid)
+			for _, hook := range s.forumHooks {
+				hook(ctx, fid)
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"well_go/internal/model"
+	"well_go/internal/repository"
+)
+
+// 相关主题：共同标签（按稀有度加权）为主，同版块加权，越旧衰减越多；不足时用同版块最新主题补齐
+const (
+	relatedThreadLimit    = 20    // 每个主题缓存的相关主题数
+	relatedPerTag         = 500   // 每个共同标签最多读取的最近关联数
+	relatedCandidates     = 200   // 按标签得分取前 N 个加载主题数据参与排序
+	relatedSameForumBoost = 1.5   // 同版块得分倍数
+	relatedHalfLifeDays   = 180.0 // 得分随最后回复时间衰减的半衰期（天）
+)
+
+// relatedCandidate 相关主题候选
+type relatedCandidate struct {
+	Tid      int64
+	Fid      int
+	Lastpost int
+	Score    float64 // 共同标签得分之和，标签关联主题越少权重越高
+}
+
+func relatedKey(tid int64) string {
+	return fmt.Sprintf("thread:related:%d", tid)
+}
+
+// Related 获取相关主题：只缓存排序后的 tid（主题标签变化时失效），
+// 主题数据按 tid 从主题缓存读取，已下线、删除、移动的主题立即反映
+func (s *ThreadService) Related(ctx context.Context, tid int64) ([]*ThreadListItem, error) {
+	tids, err := s.tidsCache.Get(ctx, relatedKey(tid), func(ctx context.Context) (*[]int64, error) {
+		tids, err := s.relatedTids(ctx, tid)
+		if err != nil {
+			return nil, err
+		}
+		return &tids, nil
+	})
+	if err != nil || tids == nil {
+		return nil, err
+	}
+	return s.publishedItems(ctx, *tids)
+}
+
+// publishedItems 按 tids 顺序返回已发布主题的列表项：优先读主题缓存，未命中的一次查库
+func (s *ThreadService) publishedItems(ctx context.Context, tids []int64) ([]*ThreadListItem, error) {
+	keys := make([]string, 0, len(tids))
+	for _, tid := range tids {
+		keys = append(keys, fmt.Sprintf("thread:%d", tid))
+	}
+	cached := s.cache.GetMulti(ctx, keys)
+
+	items := make(map[int64]*ThreadListItem, len(tids))
+	var missing []int64
+	for i, tid := range tids {
+		dto, ok := cached[keys[i]]
+		if !ok {
+			missing = append(missing, tid)
+			continue
+		}
+		if dto != nil { // nil 为负缓存：主题已不存在
+			items[tid] = &ThreadListItem{
+				Tid:         dto.Tid,
+				Fid:         dto.Fid,
+				Uid:         dto.Uid,
+				Subject:     dto.Subject,
+				Views:       dto.Views,
+				Replies:     dto.Replies,
+				Dateline:    dto.Dateline,
+				Lastpost:    dto.Lastpost,
+				Status:      dto.Status,
+				RedirectTid: dto.RedirectTid,
+			}
+		}
+	}
+	if len(missing) > 0 {
+		threads, err := s.repo.GetByTIDs(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range threads {
+			items[t.Tid] = &ThreadListItem{
+				Tid:         t.Tid,
+				Fid:         t.Fid,
+				Uid:         t.Uid,
+				Subject:     t.Subject,
+				Views:       t.Views,
+				Replies:     t.Replies,
+				Dateline:    int(t.Dateline),
+				Lastpost:    int(t.Lastpost),
+				Status:      t.Status,
+				RedirectTid: t.RedirectTid,
+			}
+		}
+	}
+
+	pending := s.pendingViews(ctx, tids...)
+	list := make([]*ThreadListItem, 0, len(tids))
+	for _, tid := range tids {
+		item := items[tid]
+		if item == nil || item.Status != model.ThreadPublished || item.RedirectTid > 0 {
+			continue
+		}
+		item.Views += int(pending[tid])
+		list = append(list, item)
+	}
+	return list, nil
+}
+
+// relatedTids 计算相关主题 tid（已排序）
+func (s *ThreadService) relatedTids(ctx context.Context, tid int64) ([]int64, error) {
+	thread, err := s.repo.GetByID(ctx, tid)
+	if err != nil || thread == nil {
+		return nil, err
+	}
+
+	candidates, err := s.relatedCandidates(ctx, tid)
+	if err != nil {
+		return nil, err
+	}
+	tids := rankRelated(candidates, thread.Fid, time.Now(), relatedThreadLimit)
+	if len(tids) >= relatedThreadLimit {
+		return tids, nil
+	}
+
+	// 标签太少时用同版块最新主题补齐
+	latest, err := s.repo.GetListTIDs(ctx, repository.ThreadListFilter{
+		Fids: []int{thread.Fid},
+		Sort: model.ThreadSortLastpost,
+	}, 0, relatedThreadLimit+1)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[int64]bool, len(tids)+1)
+	seen[tid] = true
+	for _, id := range tids {
+		seen[id] = true
+	}
+	for _, id := range latest {
+		if len(tids) >= relatedThreadLimit {
+			break
+		}
+		if !seen[id] {
+			seen[id] = true
+			tids = append(tids, id)
+		}
+	}
+	return tids, nil
+}
+
+// relatedCandidates 与 tid 有共同标签的已发布主题：逐表读取标签、关联与主题，在内存中计分
+// 每个共同标签贡献 1/ln(threads+2)，热门标签权重低、冷门标签权重高
+func (s *ThreadService) relatedCandidates(ctx context.Context, tid int64) ([]*relatedCandidate, error) {
+	tagIDs, err := s.threadTag.GetByThread(ctx, tid)
+	if err != nil || len(tagIDs) == 0 {
+		return nil, err
+	}
+	tags, err := s.tagRepo.GetByIDs(ctx, tagIDs) // 只返回未删除的标签
+	if err != nil || len(tags) == 0 {
+		return nil, err
+	}
+	weights := make(map[int]float64, len(tags))
+	live := make([]int, 0, len(tags))
+	for _, t := range tags {
+		weights[t.TagID] = 1 / math.Log(float64(t.Threads)+2)
+		live = append(live, t.TagID)
+	}
+
+	rows, err := s.threadTag.GetByTags(ctx, live, relatedPerTag)
+	if err != nil {
+		return nil, err
+	}
+	scores := make(map[int64]float64, len(rows))
+	for _, row := range rows {
+		if row.Tid != tid {
+			scores[row.Tid] += weights[row.TagID]
+		}
+	}
+
+	// 只为标签得分最高的候选加载主题数据
+	tids := make([]int64, 0, len(scores))
+	for id := range scores {
+		tids = append(tids, id)
+	}
+	sort.Slice(tids, func(i, j int) bool {
+		if scores[tids[i]] != scores[tids[j]] {
+			return scores[tids[i]] > scores[tids[j]]
+		}
+		return tids[i] > tids[j]
+	})
+	if len(tids) > relatedCandidates {
+		tids = tids[:relatedCandidates]
+	}
+
+	threads, err := s.repo.GetByTIDs(ctx, tids)
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]*relatedCandidate, 0, len(threads))
+	for _, t := range threads {
+		if t.Status != model.ThreadPublished || t.RedirectTid > 0 {
+			continue
+		}
+		candidates = append(candidates, &relatedCandidate{Tid: t.Tid, Fid: t.Fid, Lastpost: t.Lastpost, Score: scores[t.Tid]})
+	}
+	return candidates, nil
+}
+
+// rankRelated 按 标签得分 × 同版块加权 × 时间衰减 排序，取前 limit 个
+func rankRelated(candidates []*relatedCandidate, fid int, now time.Time, limit int) []int64 {
+	type scored struct {
+		tid   int64
+		score float64
+	}
+	list := make([]scored, 0, len(candidates))
+	for _, c := range candidates {
+		score := c.Score
+		if c.Fid == fid {
+			score *= relatedSameForumBoost
+		}
+		ageDays := now.Sub(time.Unix(int64(c.Lastpost), 0)).Hours() / 24
+		if ageDays > 0 {
+			score *= math.Exp2(-ageDays / relatedHalfLifeDays)
+		}
+		list = append(list, scored{c.Tid, score})
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].score > list[j].score })
+
+	if len(list) > limit {
+		list = list[:limit]
+	}
+	tids := make([]int64, 0, len(list))
+	for _, item := range list {
+		tids = append(tids, item.tid)
+	}
+	return tids
+}
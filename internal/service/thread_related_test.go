@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"well_go/internal/model"
+	"well_go/internal/repository"
+)
+
+func TestRankRelated(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	day := 24 * 3600
+	recent := int(now.Unix()) - day
+
+	tests := []struct {
+		name       string
+		candidates []*relatedCandidate
+		limit      int
+		want       []int64
+	}{
+		{
+			// 共同标签越稀有得分越高
+			name: "rarity",
+			candidates: []*relatedCandidate{
+				{Tid: 1, Fid: 2, Lastpost: recent, Score: 0.1},
+				{Tid: 2, Fid: 2, Lastpost: recent, Score: 1.0},
+				{Tid: 3, Fid: 2, Lastpost: recent, Score: 0.5},
+			},
+			limit: 10,
+			want:  []int64{2, 3, 1},
+		},
+		{
+			// 同版块 ×1.5 可以超过得分略高的其它版块主题，但不超过 1.5 倍
+			name: "same forum boost",
+			candidates: []*relatedCandidate{
+				{Tid: 1, Fid: 2, Lastpost: recent, Score: 1.2},
+				{Tid: 2, Fid: 1, Lastpost: recent, Score: 1.0},
+				{Tid: 3, Fid: 2, Lastpost: recent, Score: 1.6},
+			},
+			limit: 10,
+			want:  []int64{3, 2, 1},
+		},
+		{
+			// 半衰期 180 天：一年前的主题得分衰减到约 1/4
+			name: "recency decay",
+			candidates: []*relatedCandidate{
+				{Tid: 1, Fid: 2, Lastpost: int(now.Unix()) - 365*day, Score: 2.5},
+				{Tid: 2, Fid: 2, Lastpost: recent, Score: 1.0},
+				{Tid: 3, Fid: 2, Lastpost: int(now.Unix()) - 90*day, Score: 1.0},
+			},
+			limit: 10,
+			want:  []int64{2, 3, 1},
+		},
+		{
+			name: "limit and stable ties",
+			candidates: []*relatedCandidate{
+				{Tid: 1, Fid: 2, Lastpost: recent, Score: 1.0},
+				{Tid: 2, Fid: 2, Lastpost: recent, Score: 1.0},
+				{Tid: 3, Fid: 2, Lastpost: recent, Score: 1.0},
+			},
+			limit: 2,
+			want:  []int64{1, 2},
+		},
+		{name: "empty", limit: 10, want: []int64{}},
+	}
+	for _, tt := range tests {
+		if got := rankRelated(tt.candidates, 1, now, tt.limit); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: rankRelated = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// relatedThreadTagRepo 内存中的主题标签关联
+type relatedThreadTagRepo struct {
+	repository.ThreadTagRepository
+	rows []*model.ThreadTag
+}
+
+// relatedTagRepo 内存中的标签（不含已删除）
+type relatedTagRepo struct {
+	repository.TagRepository
+	tags map[int]*model.Tag
+}
+
+func (r *relatedThreadTagRepo) GetByThread(ctx context.Context, tid int64) ([]int, error) {
+	var ids []int
+	for _, row := range r.rows {
+		if row.Tid == tid {
+			ids = append(ids, row.TagID)
+		}
+	}
+	return ids, nil
+}
+
+func (r *relatedThreadTagRepo) GetByTags(ctx context.Context, tagIDs []int, perTag int) ([]*model.ThreadTag, error) {
+	var list []*model.ThreadTag
+	for _, id := range tagIDs {
+		n := 0
+		for _, row := range r.rows {
+			if row.TagID == id && n < perTag {
+				list = append(list, row)
+				n++
+			}
+		}
+	}
+	return list, nil
+}
+
+func (r *relatedTagRepo) GetByIDs(ctx context.Context, tagIDs []int) ([]*model.Tag, error) {
+	var tags []*model.Tag
+	for _, id := range tagIDs {
+		if t, ok := r.tags[id]; ok {
+			tags = append(tags, t)
+		}
+	}
+	return tags, nil
+}
+
+func TestRelatedCandidates(t *testing.T) {
+	tags := &relatedTagRepo{tags: map[int]*model.Tag{
+		1: {TagID: 1, Threads: 2},   // 冷门
+		2: {TagID: 2, Threads: 500}, // 热门
+		// 3 已删除，不参与计分
+	}}
+	threadTags := &relatedThreadTagRepo{}
+	for _, row := range [][2]int64{{1, 1}, {1, 2}, {1, 3}, {10, 1}, {11, 2}, {12, 1}, {12, 2}, {13, 1}, {14, 3}} {
+		threadTags.rows = append(threadTags.rows, &model.ThreadTag{Tid: row[0], TagID: int(row[1])})
+	}
+	threads := &cursorThreadRepo{threads: []*model.Thread{
+		{Tid: 10, Fid: 1, Status: model.ThreadPublished},
+		{Tid: 11, Fid: 1, Status: model.ThreadPublished},
+		{Tid: 12, Fid: 1, Status: model.ThreadDraft},
+		{Tid: 13, Fid: 1, Status: model.ThreadPublished, RedirectTid: 10},
+		{Tid: 14, Fid: 1, Status: model.ThreadPublished},
+	}}
+	s := &ThreadService{repo: threads, threadTag: threadTags, tagRepo: tags}
+
+	got, err := s.relatedCandidates(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scores := make(map[int64]float64, len(got))
+	for _, c := range got {
+		scores[c.Tid] = c.Score
+	}
+	if len(scores) != 2 || scores[10] <= scores[11] || scores[11] <= 0 {
+		t.Fatalf("candidates = %v, want 10 (rare tag) above 11, no self/draft/stub/deleted tag", scores)
+	}
+}
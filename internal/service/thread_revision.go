@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"well_go/internal/model"
+	"well_go/internal/pkg/diff"
+)
+
+var ErrRevisionNotFound = fmt.Errorf("revision not found")
+
+// RevisionDTO 修订记录数据传输对象
+type RevisionDTO struct {
+	Rev      int    `json:"rev"`
+	Uid      int64  `json:"uid"`
+	Subject  string `json:"subject"`
+	Message  string `json:"message,omitempty"`
+	Action   int    `json:"action"`
+	FromRev  int    `json:"from_rev,omitempty"`
+	Dateline int    `json:"dateline"`
+}
+
+// RevisionDiff 两个修订版本之间的差异
+type RevisionDiff struct {
+	From        int                `json:"from"`
+	To          int                `json:"to"`
+	SubjectFrom string             `json:"subject_from"`
+	SubjectTo   string             `json:"subject_to"`
+	Lines       []RevisionDiffLine `json:"lines"`
+	Added       int                `json:"added"`
+	Removed     int                `json:"removed"`
+}
+
+// RevisionDiffLine 内容差异行，op: "=" 未变 / "+" 新增 / "-" 删除
+type RevisionDiffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+func newRevisionDTO(r *model.ThreadRevision) *RevisionDTO {
+	return &RevisionDTO{
+		Rev:      r.Rev,
+		Uid:      r.Uid,
+		Subject:  r.Subject,
+		Message:  r.Message,
+		Action:   r.Action,
+		FromRev:  r.FromRev,
+		Dateline: r.Dateline,
+	}
+}
+
+// Revisions 分页获取主题修订列表（按版本倒序，不含内容）
+func (s *ThreadService) Revisions(ctx context.Context, tid int64, page, pageSize int) ([]*RevisionDTO, int, error) {
+	total, err := s.revRepo.Count(ctx, tid)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	revs, err := s.revRepo.GetByTid(ctx, tid, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	list := make([]*RevisionDTO, 0, len(revs))
+	for _, r := range revs {
+		list = append(list, newRevisionDTO(r))
+	}
+	return list, total, nil
+}
+
+// Revision 获取单个修订（含内容）
+func (s *ThreadService) Revision(ctx context.Context, tid int64, rev int) (*RevisionDTO, error) {
+	r, err := s.revRepo.Get(ctx, tid, rev)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, ErrRevisionNotFound
+	}
+	return newRevisionDTO(r), nil
+}
+
+// DiffRevisions 对比两个修订版本（from → to）
+func (s *ThreadService) DiffRevisions(ctx context.Context, tid int64, from, to int) (*RevisionDiff, error) {
+	a, err := s.revRepo.Get(ctx, tid, from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.revRepo.Get(ctx, tid, to)
+	if err != nil {
+		return nil, err
+	}
+	if a == nil || b == nil {
+		return nil, ErrRevisionNotFound
+	}
+
+	lines := diff.Lines(a.Message, b.Message)
+	result := &RevisionDiff{
+		From:        from,
+		To:          to,
+		SubjectFrom: a.Subject,
+		SubjectTo:   b.Subject,
+		Lines:       make([]RevisionDiffLine, 0, len(lines)),
+	}
+	result.Added, result.Removed = diff.Stats(lines)
+	for _, l := range lines {
+		result.Lines = append(result.Lines, RevisionDiffLine{Op: string(l.Op), Text: l.Text})
+	}
+	return result, nil
+}
+
+// Rollback 将主题标题和内容回滚到指定版本，回滚本身记为一次新修订
+func (s *ThreadService) Rollback(ctx context.Context, tid int64, rev int, uid int64) (*RevisionDTO, error) {
+	thread, err := s.repo.GetByID(ctx, tid)
+	if err != nil {
+		return nil, err
+	}
+	if thread == nil {
+		return nil, ErrThreadNotFound
+	}
+	if thread.Status == model.ThreadTrashed {
+		return nil, ErrThreadTrashed
+	}
+
+	target, err := s.revRepo.Get(ctx, tid, rev)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, ErrRevisionNotFound
+	}
+
+	now := int(time.Now().Unix())
+	thread.Subject = target.Subject
+	thread.Lastpost = now
+	revision := &model.ThreadRevision{
+		Uid:      uid,
+		Subject:  target.Subject,
+		Message:  target.Message,
+		Action:   model.RevisionRollback,
+		FromRev:  target.Rev,
+		Dateline: now,
+	}
+	if err := s.repo.Edit(ctx, thread, thread.Status, &target.Message, revision); err != nil {
+		return nil, err
+	}
+
+	s.invalidateThreadCache(tid)
+	if thread.Status == model.ThreadPublished {
+		s.invalidateThreadList(ctx, thread.Fid)
+	}
+	if s.indexer != nil {
+		s.indexer.IndexThread(ctx, tid)
+	}
+
+	dto := newRevisionDTO(revision)
+	dto.Message = ""
+	return dto, nil
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"well_go/internal/core/logger"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 浏览量缓冲：访问时 HINCRBY 到 Redis，定时批量落库
+const (
+	viewsPendingKey   = "thread:views:pending" // tid → 未落库增量
+	viewsSeenPrefix   = "thread:views:seen:"   // 去重标记 {tid}:{visitor}
+	defaultViewWindow = 30 * time.Minute       // 未设置去重窗口时的默认值
+)
+
+// takeViewsScript 原子地取出并清空待落库增量，多实例并发 flush 时每个增量只会被取走一次
+var takeViewsScript = redis.NewScript(`
+local v = redis.call('HGETALL', KEYS[1])
+redis.call('DEL', KEYS[1])
+return v`)
+
+// SetViewDedupWindow 设置同一访客重复浏览不计数的窗口
+func (s *ThreadService) SetViewDedupWindow(window time.Duration) {
+	s.viewWindow = window
+}
+
+// RecordView 记录一次浏览，visitor（IP 等）在去重窗口内重复访问不计数
+func (s *ThreadService) RecordView(ctx context.Context, tid int64, visitor string) error {
+	window := s.viewWindow
+	if window <= 0 {
+		window = defaultViewWindow
+	}
+
+	first, err := s.l2.SetNX(ctx, fmt.Sprintf("%s%d:%s", viewsSeenPrefix, tid, visitor), 1, window).Result()
+	if err != nil || !first {
+		return err
+	}
+	return s.l2.HIncrBy(ctx, viewsPendingKey, strconv.FormatInt(tid, 10), 1).Err()
+}
+
+// pendingViews 获取尚未落库的浏览增量（Redis 不可用时视为 0）
+func (s *ThreadService) pendingViews(ctx context.Context, tids ...int64) map[int64]int64 {
+	result := make(map[int64]int64, len(tids))
+	if len(tids) == 0 {
+		return result
+	}
+
+	fields := make([]string, 0, len(tids))
+	for _, tid := range tids {
+		fields = append(fields, strconv.FormatInt(tid, 10))
+	}
+	values, err := s.l2.HMGet(ctx, viewsPendingKey, fields...).Result()
+	if err != nil {
+		return result
+	}
+	for i, v := range values {
+		str, ok := v.(string)
+		if !ok {
+			continue
+		}
+		if n, err := strconv.ParseInt(str, 10, 64); err == nil {
+			result[tids[i]] = n
+		}
+	}
+	return result
+}
+
+// FlushViews 将缓冲的浏览量批量写入数据库，返回涉及的主题数
+// 写库失败时把增量加回 Redis，下次重试。不失效主题/列表缓存：读取时叠加未落库增量，
+// 缓存中的浏览量随缓存自然过期刷新，避免每个落库周期清空所有被浏览主题及其版块列表的缓存
+func (s *ThreadService) FlushViews(ctx context.Context) (int, error) {
+	raw, err := takeViewsScript.Run(ctx, s.l2, []string{viewsPendingKey}).StringSlice()
+	if err != nil || len(raw) == 0 {
+		return 0, err
+	}
+
+	views := make(map[int64]int64, len(raw)/2)
+	for i := 0; i+1 < len(raw); i += 2 {
+		tid, err1 := strconv.ParseInt(raw[i], 10, 64)
+		n, err2 := strconv.ParseInt(raw[i+1], 10, 64)
+		if err1 != nil || err2 != nil || n <= 0 {
+			continue
+		}
+		views[tid] = n
+	}
+	if len(views) == 0 {
+		return 0, nil
+	}
+
+	if err := s.repo.AddViews(ctx, views); err != nil {
+		pipe := s.l2.Pipeline()
+		for tid, n := range views {
+			pipe.HIncrBy(ctx, viewsPendingKey, strconv.FormatInt(tid, 10), n)
+		}
+		pipe.Exec(ctx)
+		return 0, err
+	}
+	return len(views), nil
+}
+
+// RunViewFlusher 定时落库浏览量，阻塞直到 ctx 结束；退出前再 flush 一次
+func (s *ThreadService) RunViewFlusher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if _, err := s.FlushViews(context.Background()); err != nil {
+				logger.Error("views: final flush failed", logger.String("error", err.Error()))
+			}
+			return
+		case <-ticker.C:
+			if _, err := s.FlushViews(ctx); err != nil {
+				logger.Error("views: flush failed", logger.String("error", err.Error()))
+			}
+		}
+	}
+}
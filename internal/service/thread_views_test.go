@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"well_go/internal/repository"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// fakeThreadRepo 仅实现 AddViews 的主题仓库，记录每次落库的增量
+type fakeThreadRepo struct {
+	repository.ThreadRepository
+	flushed []map[int64]int64
+	err     error
+}
+
+func (r *fakeThreadRepo) AddViews(ctx context.Context, views map[int64]int64) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.flushed = append(r.flushed, views)
+	return nil
+}
+
+func TestRecordViewDedup(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	l2 := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer l2.Close()
+
+	s := &ThreadService{repo: &fakeThreadRepo{}, l2: l2}
+	s.SetViewDedupWindow(time.Minute)
+
+	record := func(tid int64, visitor string) {
+		t.Helper()
+		if err := s.RecordView(ctx, tid, visitor); err != nil {
+			t.Fatal(err)
+		}
+	}
+	record(1, "10.0.0.1")
+	record(1, "10.0.0.1") // 窗口内重复
+	record(1, "10.0.0.2")
+	record(2, "10.0.0.1") // 不同主题分别去重
+	if got := s.pendingViews(ctx, 1, 2); got[1] != 2 || got[2] != 1 {
+		t.Fatalf("pending = %v, want 1:2 2:1", got)
+	}
+
+	mr.FastForward(time.Minute)
+	record(1, "10.0.0.1")
+	if got := s.pendingViews(ctx, 1)[1]; got != 3 {
+		t.Fatalf("pending after window = %d, want 3", got)
+	}
+}
+
+func TestFlushViews(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeThreadRepo{}
+	s := &ThreadService{repo: repo, l2: newTestRedis(t)}
+
+	for i, tid := range []int64{1, 1, 2, 3, 3, 3} {
+		if err := s.RecordView(ctx, tid, string(rune('a'+i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 写库失败：增量加回，下次重试
+	repo.err = errors.New("db down")
+	if _, err := s.FlushViews(ctx); err == nil {
+		t.Fatal("expected flush error")
+	}
+	if got := s.pendingViews(ctx, 1, 2, 3); got[1] != 2 || got[2] != 1 || got[3] != 3 {
+		t.Fatalf("pending after failed flush = %v", got)
+	}
+
+	repo.err = nil
+	n, err := s.FlushViews(ctx)
+	if err != nil || n != 3 {
+		t.Fatalf("flush = %d, %v, want 3", n, err)
+	}
+	if len(repo.flushed) != 1 {
+		t.Fatalf("flushed %d batches, want 1", len(repo.flushed))
+	}
+	if got := repo.flushed[0]; got[1] != 2 || got[2] != 1 || got[3] != 3 {
+		t.Fatalf("flushed = %v", got)
+	}
+	if got := s.pendingViews(ctx, 1, 2, 3); len(got) != 0 {
+		t.Fatalf("pending after flush = %v, want empty", got)
+	}
+
+	// 没有增量时不写库
+	if n, err := s.FlushViews(ctx); err != nil || n != 0 || len(repo.flushed) != 1 {
+		t.Fatalf("empty flush = %d, %v", n, err)
+	}
+}
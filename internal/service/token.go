@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"well_go/internal/core/config"
+	"well_go/internal/model"
+	"well_go/internal/pkg/jwtkey"
+	"well_go/internal/pkg/util"
+	"well_go/internal/repository"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrInvalidRefreshToken = fmt.Errorf("invalid or expired refresh token")
+	ErrRefreshTokenReused  = fmt.Errorf("refresh token reuse detected, session revoked")
+	ErrUserDisabled        = fmt.Errorf("user is disabled")
+)
+
+// 令牌状态（Redis）：
+//
+//	auth:session:{sid}        会话存在即有效，值为 uid；登出/撤销时删除，access token 随之失效
+//	auth:user:{uid}:sessions  用户的会话 sid 集合（撤销全部会话用）
+//	auth:refresh:{sha256}     refresh token 记录（uid/sid/used），使用后标记 used 保留到过期以识别重放
+//	auth:deny:{jti}           access token 黑名单，保留到该 token 过期
+const (
+	sessionKeyPrefix  = "auth:session:"
+	refreshKeyPrefix  = "auth:refresh:"
+	denyKeyPrefix     = "auth:deny:"
+	userSessionsKeyFm = "auth:user:%d:sessions"
+)
+
+// takeRefreshScript 原子地取出 refresh token 并标记已使用；返回 {uid, sid, "ok"|"reused"}，不存在返回 nil
+var takeRefreshScript = redis.NewScript(`
+local r = redis.call('HMGET', KEYS[1], 'uid', 'sid', 'used')
+if not r[1] then return nil end
+if r[3] == '1' then return {r[1], r[2], 'reused'} end
+redis.call('HSET', KEYS[1], 'used', '1')
+return {r[1], r[2], 'ok'}`)
+
+// TokenService 令牌签发、轮换与撤销
+type TokenService struct {
+	userRepo repository.UserRepository
+	l2       *redis.Client
+	cfg      *config.JWTConfig
+	keys     *jwtkey.KeySet
+}
+
+// NewTokenService 创建 TokenService 实例，access token 由 keys 的当前签名密钥签发
+func NewTokenService(userRepo repository.UserRepository, l2 *redis.Client, cfg *config.JWTConfig, keys *jwtkey.KeySet) *TokenService {
+	return &TokenService{userRepo: userRepo, l2: l2, cfg: cfg, keys: keys}
+}
+
+func (s *TokenService) accessTTL() time.Duration {
+	return time.Duration(s.cfg.Expiry) * time.Second
+}
+
+func (s *TokenService) refreshTTL() time.Duration {
+	return time.Duration(s.cfg.RefreshExpiry) * time.Second
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Issue 登录后为用户创建新会话并签发令牌
+func (s *TokenService) Issue(ctx context.Context, user *model.User) (*model.TokenPair, error) {
+	sid, err := util.GenerateRandomString(16)
+	if err != nil {
+		return nil, err
+	}
+
+	pipe := s.l2.TxPipeline()
+	pipe.Set(ctx, sessionKeyPrefix+sid, user.Uid, s.refreshTTL())
+	pipe.SAdd(ctx, fmt.Sprintf(userSessionsKeyFm, user.Uid), sid)
+	pipe.Expire(ctx, fmt.Sprintf(userSessionsKeyFm, user.Uid), s.refreshTTL())
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	return s.issuePair(ctx, user, sid)
+}
+
+// issuePair 在会话 sid 下签发 access token 与新的 refresh token
+func (s *TokenService) issuePair(ctx context.Context, user *model.User, sid string) (*model.TokenPair, error) {
+	jti, err := util.GenerateRandomString(16)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"uid":      user.Uid,
+		"username": user.Username,
+		"role":     user.Role,
+		"sid":      sid,
+		"jti":      jti,
+		"iat":      now.Unix(),
+		"exp":      now.Add(s.accessTTL()).Unix(),
+	}
+	access, err := s.keys.Sign(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	refresh, err := util.GenerateRandomString(32)
+	if err != nil {
+		return nil, err
+	}
+	key := refreshKeyPrefix + hashRefreshToken(refresh)
+	pipe := s.l2.TxPipeline()
+	pipe.HSet(ctx, key, "uid", user.Uid, "sid", sid, "used", 0)
+	pipe.Expire(ctx, key, s.refreshTTL())
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	return &model.TokenPair{
+		Token:        access,
+		ExpiresIn:    s.cfg.Expiry,
+		RefreshToken: refresh,
+	}, nil
+}
+
+// Refresh 用 refresh token 换取新的令牌对（轮换：旧 refresh token 立即作废）
+// 已使用过的 refresh token 再次出现视为被盗用，撤销整个会话
+func (s *TokenService) Refresh(ctx context.Context, refreshToken string) (*model.TokenPair, error) {
+	res, err := takeRefreshScript.Run(ctx, s.l2, []string{refreshKeyPrefix + hashRefreshToken(refreshToken)}).StringSlice()
+	if err == redis.Nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	uid, err := strconv.ParseInt(res[0], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	sid := res[1]
+	if res[2] == "reused" {
+		s.revokeSession(ctx, uid, sid)
+		return nil, ErrRefreshTokenReused
+	}
+
+	// 会话已登出/撤销
+	alive, err := s.l2.Expire(ctx, sessionKeyPrefix+sid, s.refreshTTL()).Result()
+	if err != nil {
+		return nil, err
+	}
+	if !alive {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// 角色/状态以数据库为准
+	user, err := s.userRepo.GetByID(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.Status != 0 {
+		s.revokeSession(ctx, uid, sid)
+		return nil, ErrUserDisabled
+	}
+	s.l2.Expire(ctx, fmt.Sprintf(userSessionsKeyFm, uid), s.refreshTTL())
+	return s.issuePair(ctx, user, sid)
+}
+
+// Logout 注销当前会话：会话删除（其 refresh token 不可再用），当前 access token 加入黑名单
+func (s *TokenService) Logout(ctx context.Context, uid int64, sid, jti string, exp int64) error {
+	if err := s.Deny(ctx, jti, exp); err != nil {
+		return err
+	}
+	return s.revokeSession(ctx, uid, sid)
+}
+
+// Deny 将 access token 加入黑名单直到其过期
+func (s *TokenService) Deny(ctx context.Context, jti string, exp int64) error {
+	ttl := time.Until(time.Unix(exp, 0))
+	if jti == "" || ttl <= 0 {
+		return nil
+	}
+	return s.l2.Set(ctx, denyKeyPrefix+jti, 1, ttl).Err()
+}
+
+// RevokeAll 撤销用户全部会话（所有设备需重新登录）
+func (s *TokenService) RevokeAll(ctx context.Context, uid int64) (int, error) {
+	setKey := fmt.Sprintf(userSessionsKeyFm, uid)
+	sids, err := s.l2.SMembers(ctx, setKey).Result()
+	if err != nil {
+		return 0, err
+	}
+	keys := make([]string, 0, len(sids)+1)
+	for _, sid := range sids {
+		keys = append(keys, sessionKeyPrefix+sid)
+	}
+	keys = append(keys, setKey)
+	n, err := s.l2.Del(ctx, keys...).Result()
+	if err != nil {
+		return 0, err
+	}
+	if n > 0 {
+		n-- // 不计会话集合本身
+	}
+	return int(n), nil
+}
+
+func (s *TokenService) revokeSession(ctx context.Context, uid int64, sid string) error {
+	pipe := s.l2.TxPipeline()
+	pipe.Del(ctx, sessionKeyPrefix+sid)
+	pipe.SRem(ctx, fmt.Sprintf(userSessionsKeyFm, uid), sid)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Revoked access token 是否已失效（会话已撤销或 jti 在黑名单中），供 JWTMW 调用
+func (s *TokenService) Revoked(ctx context.Context, sid, jti string) (bool, error) {
+	if sid == "" || jti == "" {
+		return true, nil // 未绑定会话的旧令牌
+	}
+	vals, err := s.l2.MGet(ctx, sessionKeyPrefix+sid, denyKeyPrefix+jti).Result()
+	if err != nil {
+		return false, err
+	}
+	return vals[0] == nil || vals[1] != nil, nil
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"well_go/internal/core/config"
+	"well_go/internal/model"
+	"well_go/internal/pkg/jwtkey"
+	"well_go/internal/repository"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// snowflakeUID 超出 float64 精度的 uid
+const snowflakeUID int64 = 1981234567890123457
+
+// fakeUserRepo 仅实现 GetByID/GetByUsername 的内存用户仓库
+type fakeUserRepo struct {
+	repository.UserRepository
+	users map[int64]*model.User
+}
+
+func (r *fakeUserRepo) GetByID(ctx context.Context, uid int64) (*model.User, error) {
+	return r.users[uid], nil
+}
+
+func (r *fakeUserRepo) GetByUsername(ctx context.Context, username string) (*model.User, error) {
+	for _, u := range r.users {
+		if u.Username == username {
+			return u, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeUserRepo) UpdateLastvisit(ctx context.Context, uid int64, timestamp int) error {
+	return nil
+}
+
+func newTestRedis(t *testing.T) *redis.Client {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func newTestTokenService(t *testing.T, l2 *redis.Client, users *fakeUserRepo) *TokenService {
+	t.Helper()
+	keys, err := jwtkey.Load(jwtkey.Options{Secret: "test-secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewTokenService(users, l2, &config.JWTConfig{Expiry: 900, RefreshExpiry: 3600}, keys)
+}
+
+// sessionOf 解析 access token 中的 sid/jti
+func sessionOf(t *testing.T, s *TokenService, pair *model.TokenPair) (sid, jti string) {
+	t.Helper()
+	claims, err := s.keys.Parse(pair.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sid, _ = claims["sid"].(string)
+	jti, _ = claims["jti"].(string)
+	return sid, jti
+}
+
+func assertRevoked(t *testing.T, s *TokenService, pair *model.TokenPair, want bool) {
+	t.Helper()
+	sid, jti := sessionOf(t, s, pair)
+	revoked, err := s.Revoked(context.Background(), sid, jti)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revoked != want {
+		t.Fatalf("revoked = %v, want %v", revoked, want)
+	}
+}
+
+func TestTokenRefreshRotation(t *testing.T) {
+	ctx := context.Background()
+	user := &model.User{Uid: snowflakeUID, Username: "alice"}
+	s := newTestTokenService(t, newTestRedis(t), &fakeUserRepo{users: map[int64]*model.User{user.Uid: user}})
+
+	first, err := s.Issue(ctx, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertRevoked(t, s, first, false)
+
+	second, err := s.Refresh(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Fatal("refresh token not rotated")
+	}
+	sid1, _ := sessionOf(t, s, first)
+	sid2, _ := sessionOf(t, s, second)
+	if sid1 != sid2 {
+		t.Fatal("refresh must stay in the same session")
+	}
+
+	// 旧 refresh token 重放：整个会话被撤销，轮换出的新令牌一并失效
+	if _, err := s.Refresh(ctx, first.RefreshToken); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("reuse: %v", err)
+	}
+	if _, err := s.Refresh(ctx, second.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("refresh after reuse: %v", err)
+	}
+	assertRevoked(t, s, first, true)
+	assertRevoked(t, s, second, true)
+
+	if _, err := s.Refresh(ctx, "garbage"); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("unknown token: %v", err)
+	}
+}
+
+func TestTokenRevokeAll(t *testing.T) {
+	ctx := context.Background()
+	alice := &model.User{Uid: snowflakeUID, Username: "alice"}
+	bob := &model.User{Uid: snowflakeUID + 1, Username: "bob"}
+	s := newTestTokenService(t, newTestRedis(t), &fakeUserRepo{users: map[int64]*model.User{alice.Uid: alice, bob.Uid: bob}})
+
+	laptop, _ := s.Issue(ctx, alice)
+	phone, _ := s.Issue(ctx, alice)
+	other, _ := s.Issue(ctx, bob)
+
+	n, err := s.RevokeAll(ctx, alice.Uid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Fatalf("revoked = %d, want 2", n)
+	}
+	assertRevoked(t, s, laptop, true)
+	assertRevoked(t, s, phone, true)
+	assertRevoked(t, s, other, false)
+	if _, err := s.Refresh(ctx, phone.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("refresh after revoke-all: %v", err)
+	}
+}
+
+func TestTokenLogout(t *testing.T) {
+	ctx := context.Background()
+	user := &model.User{Uid: snowflakeUID, Username: "alice"}
+	s := newTestTokenService(t, newTestRedis(t), &fakeUserRepo{users: map[int64]*model.User{user.Uid: user}})
+
+	current, _ := s.Issue(ctx, user)
+	kept, _ := s.Issue(ctx, user)
+	sid, jti := sessionOf(t, s, current)
+	if err := s.Logout(ctx, user.Uid, sid, jti, 0); err != nil {
+		t.Fatal(err)
+	}
+	assertRevoked(t, s, current, true)
+	assertRevoked(t, s, kept, false)
+
+	// 注销的会话已从用户会话集合移除
+	if n, _ := s.RevokeAll(ctx, user.Uid); n != 1 {
+		t.Fatalf("remaining sessions = %d, want 1", n)
+	}
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"well_go/internal/core/logger"
+	"well_go/internal/repository"
+)
+
+var ErrInvalidTrashType = fmt.Errorf("invalid trash type, expect thread/forum/tag")
+
+// 回收站类型
+const (
+	TrashThread = "thread"
+	TrashForum  = "forum"
+	TrashTag    = "tag"
+)
+
+// purgeBatch 到期清除每批处理数量
+const purgeBatch = 100
+
+// TrashService 回收站：列表、恢复、彻底删除与到期自动清除
+type TrashService struct {
+	threadRepo repository.ThreadRepository
+	forumRepo  repository.ForumRepository
+	tagRepo    repository.TagRepository
+	threadSvc  *ThreadService
+	forumSvc   *ForumService
+	tagSvc     *TagService
+	retention  time.Duration
+}
+
+// TrashItem 回收站条目
+type TrashItem struct {
+	ID        int64  `json:"id"`
+	Title     string `json:"title"`
+	Fid       int    `json:"fid,omitempty"`
+	DeletedAt int    `json:"deleted_at"`
+	PurgeAt   int    `json:"purge_at"` // 预计自动清除时间
+}
+
+// PurgeResult 一次清除的数量统计
+type PurgeResult struct {
+	Threads int `json:"threads"`
+	Forums  int `json:"forums"`
+	Tags    int `json:"tags"`
+}
+
+// NewTrashService 创建 TrashService 实例，retention 为回收站保留时长
+func NewTrashService(threadRepo repository.ThreadRepository, forumRepo repository.ForumRepository, tagRepo repository.TagRepository,
+	threadSvc *ThreadService, forumSvc *ForumService, tagSvc *TagService, retention time.Duration) *TrashService {
+	return &TrashService{
+		threadRepo: threadRepo,
+		forumRepo:  forumRepo,
+		tagRepo:    tagRepo,
+		threadSvc:  threadSvc,
+		forumSvc:   forumSvc,
+		tagSvc:     tagSvc,
+		retention:  retention,
+	}
+}
+
+// List 分页获取回收站条目（按删除时间倒序）
+func (s *TrashService) List(ctx context.Context, kind string, page, pageSize int) ([]*TrashItem, int, error) {
+	offset := (page - 1) * pageSize
+	keep := int(s.retention / time.Second)
+
+	switch kind {
+	case TrashThread:
+		total, err := s.threadRepo.CountTrashed(ctx)
+		if err != nil {
+			return nil, 0, err
+		}
+		threads, err := s.threadRepo.GetTrashed(ctx, offset, pageSize)
+		if err != nil {
+			return nil, 0, err
+		}
+		list := make([]*TrashItem, 0, len(threads))
+		for _, t := range threads {
+			list = append(list, &TrashItem{ID: t.Tid, Title: t.Subject, Fid: t.Fid, DeletedAt: t.DeletedAt, PurgeAt: t.DeletedAt + keep})
+		}
+		return list, total, nil
+
+	case TrashForum:
+		total, err := s.forumRepo.CountTrashed(ctx)
+		if err != nil {
+			return nil, 0, err
+		}
+		forums, err := s.forumRepo.GetTrashed(ctx, offset, pageSize)
+		if err != nil {
+			return nil, 0, err
+		}
+		list := make([]*TrashItem, 0, len(forums))
+		for _, f := range forums {
+			list = append(list, &TrashItem{ID: int64(f.Fid), Title: f.Name, Fid: f.Parent, DeletedAt: f.DeletedAt, PurgeAt: f.DeletedAt + keep})
+		}
+		return list, total, nil
+
+	case TrashTag:
+		total, err := s.tagRepo.CountTrashed(ctx)
+		if err != nil {
+			return nil, 0, err
+		}
+		tags, err := s.tagRepo.GetTrashed(ctx, offset, pageSize)
+		if err != nil {
+			return nil, 0, err
+		}
+		list := make([]*TrashItem, 0, len(tags))
+		for _, t := range tags {
+			list = append(list, &TrashItem{ID: int64(t.TagID), Title: t.Name, DeletedAt: t.DeletedAt, PurgeAt: t.DeletedAt + keep})
+		}
+		return list, total, nil
+	}
+	return nil, 0, ErrInvalidTrashType
+}
+
+// Restore 恢复回收站条目，不在回收站时返回 false
+func (s *TrashService) Restore(ctx context.Context, kind string, id int64) (bool, error) {
+	switch kind {
+	case TrashThread:
+		return s.threadSvc.Restore(ctx, id)
+	case TrashForum:
+		return s.forumSvc.Restore(ctx, int(id))
+	case TrashTag:
+		return s.tagSvc.Restore(ctx, int(id))
+	}
+	return false, ErrInvalidTrashType
+}
+
+// Purge 立即彻底删除回收站条目，不在回收站（或版块仍有主题/子版块）时返回 false
+func (s *TrashService) Purge(ctx context.Context, kind string, id int64) (bool, error) {
+	switch kind {
+	case TrashThread:
+		ok, err := s.threadSvc.Purge(ctx, id)
+		if ok {
+			s.tagSvc.invalidateThreadTagCache(ctx, id)
+		}
+		return ok, err
+	case TrashForum:
+		return s.forumSvc.Purge(ctx, int(id))
+	case TrashTag:
+		return s.tagSvc.Purge(ctx, int(id))
+	}
+	return false, ErrInvalidTrashType
+}
+
+// PurgeExpired 清除超过保留期的条目
+// 顺序：主题 → 标签 → 版块（版块需在其主题清除后才能删除）
+func (s *TrashService) PurgeExpired(ctx context.Context) (*PurgeResult, error) {
+	before := int(time.Now().Add(-s.retention).Unix())
+	result := &PurgeResult{}
+
+	for {
+		tids, err := s.threadRepo.GetExpiredTrash(ctx, before, purgeBatch)
+		if err != nil {
+			return result, err
+		}
+		for _, tid := range tids {
+			ok, err := s.Purge(ctx, TrashThread, tid)
+			if err != nil {
+				return result, err
+			}
+			if ok {
+				result.Threads++
+			}
+		}
+		if len(tids) < purgeBatch {
+			break
+		}
+	}
+
+	for {
+		tagIDs, err := s.tagRepo.GetExpiredTrash(ctx, before, purgeBatch)
+		if err != nil {
+			return result, err
+		}
+		for _, tagID := range tagIDs {
+			ok, err := s.tagSvc.Purge(ctx, tagID)
+			if err != nil {
+				return result, err
+			}
+			if ok {
+				result.Tags++
+			}
+		}
+		if len(tagIDs) < purgeBatch {
+			break
+		}
+	}
+
+	// 仍有主题/子版块的版块会被保留（Purge 返回 false），只处理一批以免反复扫描同一批
+	fids, err := s.forumRepo.GetExpiredTrash(ctx, before, purgeBatch)
+	if err != nil {
+		return result, err
+	}
+	for _, fid := range fids {
+		ok, err := s.forumSvc.Purge(ctx, fid)
+		if err != nil {
+			return result, err
+		}
+		if ok {
+			result.Forums++
+		}
+	}
+
+	return result, nil
+}
+
+// RunPurger 定时清除到期条目，阻塞直到 ctx 结束
+// 多实例同时运行时由各 Purge 的条件删除保证幂等
+func (s *TrashService) RunPurger(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			result, err := s.PurgeExpired(ctx)
+			if err != nil {
+				logger.Error("trash: purge expired failed", logger.String("error", err.Error()))
+				continue
+			}
+			if result.Threads+result.Forums+result.Tags > 0 {
+				logger.Info("trash: purged expired items",
+					logger.Int("threads", result.Threads),
+					logger.Int("forums", result.Forums),
+					logger.Int("tags", result.Tags))
+			}
+		}
+	}
+}
@@ -2,10 +2,10 @@ package service
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"sort"
+	"sync"
 	"time"
 
 	"well_go/internal/core/config"
@@ -15,49 +15,80 @@ import (
 	"well_go/internal/pkg/pool"
 	"well_go/internal/repository"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/redis/go-redis/v9"
 	"golang.org/x/crypto/bcrypt"
-	"golang.org/x/sync/singleflight"
 )
 
 // UserService 用户服务
 type UserService struct {
-	repo   repository.UserRepository
-	l1     *pool.BigCache // L1 Cache（零GC）
-	l2     *redis.Client
-	sf     *singleflight.Group
-	l2Cfg  *config.CacheConfig
-	jwtCfg *config.JWTConfig
+	repo         repository.UserRepository
+	cache        *pool.TieredCache[model.UserDTO]
+	profileCache *pool.TieredCache[model.UserProfile]
+	l2           *redis.Client
+	l2Cfg        *config.CacheConfig
+	tokens       *TokenService
+	guard        *LoginGuard
+}
+
+var ErrLoginFailed = fmt.Errorf("用户名或密码错误")
+
+// dummyHash 用户不存在时也做一次 bcrypt 比较，使响应耗时与密码错误一致
+var (
+	dummyHash     []byte
+	dummyHashOnce sync.Once
+)
+
+func compareDummyPassword(password string) {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = bcrypt.GenerateFromPassword([]byte("well-dummy-password"), bcrypt.DefaultCost)
+	})
+	_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
 }
 
 // NewUserService 创建用户服务
-func NewUserService(repo repository.UserRepository, redisClient *redis.Client, cacheCfg *config.CacheConfig, jwtCfg *config.JWTConfig) *UserService {
+func NewUserService(repo repository.UserRepository, redisClient *redis.Client, cacheCfg *config.CacheConfig, tokens *TokenService, guard *LoginGuard) *UserService {
 	l1Cache, _ := pool.NewBigCache(cacheCfg.L1Cap, time.Duration(cacheCfg.L2TTL)*time.Second)
 	return &UserService{
-		repo:   repo,
-		l1:     l1Cache,
-		l2:     redisClient,
-		sf:     &singleflight.Group{},
-		l2Cfg:  cacheCfg,
-		jwtCfg: jwtCfg,
+		repo:         repo,
+		cache:        newTieredCache[model.UserDTO]("user", l1Cache, redisClient, cacheCfg, nil),
+		profileCache: newTieredCache[model.UserProfile]("user_profile", l1Cache, redisClient, cacheCfg, nil),
+		l2:           redisClient,
+		l2Cfg:        cacheCfg,
+		tokens:       tokens,
+		guard:        guard,
 	}
 }
 
-// Login 用户登录
-func (s *UserService) Login(ctx context.Context, username, password string) (*model.LoginResponse, error) {
+// Login 用户登录，ip 为客户端地址（失败计数用）
+// 用户不存在与密码错误返回相同错误；退避或锁定期间直接返回 *LoginThrottledError，不校验密码
+func (s *UserService) Login(ctx context.Context, username, password, ip string) (*model.LoginResponse, error) {
+	if err := s.guard.Check(ctx, username, ip); err != nil {
+		var throttled *LoginThrottledError
+		if errors.As(err, &throttled) {
+			return nil, err
+		}
+		logger.Error("login: check throttle error", logger.String("error", err.Error()))
+		return nil, errors.New("系统错误")
+	}
+
 	user, err := s.repo.GetByUsername(ctx, username)
 	if err != nil {
 		logger.Error("login: get user error", logger.String("error", err.Error()))
 		return nil, errors.New("系统错误")
 	}
-	if user == nil {
-		return nil, errors.New("用户名或密码错误")
-	}
 
 	// 验证密码
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		return nil, errors.New("用户名或密码错误")
+	if user == nil {
+		compareDummyPassword(password)
+	}
+	if user == nil || bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
+		if err := s.guard.Fail(ctx, username, ip); err != nil {
+			logger.Error("login: record failure error", logger.String("error", err.Error()))
+		}
+		return nil, ErrLoginFailed
+	}
+	if err := s.guard.Success(ctx, username); err != nil {
+		logger.Warn("login: reset failures error", logger.String("error", err.Error()))
 	}
 
 	// 检查状态
@@ -70,7 +101,7 @@ func (s *UserService) Login(ctx context.Context, username, password string) (*mo
 	go s.repo.UpdateLastvisit(context.Background(), user.Uid, now)
 
 	// 生成Token
-	token, err := generateJWT(user.Uid, user.Role, s.jwtCfg)
+	pair, err := s.tokens.Issue(ctx, user)
 	if err != nil {
 		logger.Error("login: generate token error", logger.String("error", err.Error()))
 		return nil, errors.New("系统错误")
@@ -87,8 +118,8 @@ func (s *UserService) Login(ctx context.Context, username, password string) (*mo
 	}
 
 	return &model.LoginResponse{
-		Token: token,
-		User:  *dto,
+		TokenPair: *pair,
+		User:      *dto,
 	}, nil
 }
 
@@ -148,108 +179,47 @@ func (s *UserService) Register(ctx context.Context, req *model.RegisterRequest)
 func (s *UserService) GetProfile(ctx context.Context, uid int64) (*model.UserProfile, error) {
 	key := fmt.Sprintf("user:profile:%d", uid)
 
-	// L1
-	if s.l1 != nil {
-		if data, ok := s.l1.Get(key); ok {
-			if data != nil {
-				var profile model.UserProfile
-				if err := json.Unmarshal(data, &profile); err == nil {
-					return &profile, nil
-				}
-			}
+	profile, err := s.profileCache.Get(ctx, key, func(ctx context.Context) (*model.UserProfile, error) {
+		user, err := s.repo.GetByID(ctx, uid)
+		if err != nil {
+			return nil, err
 		}
-	}
-
-	// L2
-	if data, err := s.l2.Get(ctx, key).Bytes(); err == nil {
-		if data != nil {
-			var profile model.UserProfile
-			if err := json.Unmarshal(data, &profile); err == nil {
-				if s.l1 != nil {
-					s.l1.Set(key, data)
-				}
-				return &profile, nil
-			}
+		if user == nil {
+			return nil, nil
 		}
-	}
-
-	// DB
-	user, err := s.repo.GetByID(ctx, uid)
+		return &model.UserProfile{
+			UserDTO:   *newUserDTO(user),
+			Lastvisit: user.Lastvisit,
+		}, nil
+	})
 	if err != nil {
 		logger.Error("getprofile: get user error", logger.String("error", err.Error()))
 		return nil, errors.New("系统错误")
 	}
-	if user == nil {
+	if profile == nil {
 		return nil, errors.New("用户不存在")
 	}
-
-	dto := &model.UserDTO{
-		Uid:      user.Uid,
-		Username: user.Username,
-		Email:    user.Email,
-		Avatar:   user.Avatar,
-		Role:     user.Role,
-		Status:   user.Status,
-		Dateline: user.Dateline,
-	}
-
-	profile := &model.UserProfile{
-		UserDTO:   *dto,
-		Lastvisit: user.Lastvisit,
-	}
-
-	// Write Cache
-	if bytes, _ := json.Marshal(profile); bytes != nil {
-		if s.l1 != nil {
-			s.l1.Set(key, bytes)
-		}
-		s.l2.Set(ctx, key, bytes, time.Duration(s.l2Cfg.L2TTL)*time.Second)
-	}
-
 	return profile, nil
 }
 
 // GetUserByID 根据ID获取用户
 func (s *UserService) GetUserByID(ctx context.Context, uid int64) (*model.UserDTO, error) {
-	key := fmt.Sprintf("user:%d", uid)
-
-	// L1
-	if s.l1 != nil {
-		if data, ok := s.l1.Get(key); ok {
-			if data != nil {
-				var dto model.UserDTO
-				if err := json.Unmarshal(data, &dto); err == nil {
-					return &dto, nil
-				}
-			}
+	dto, err := s.cache.Get(ctx, userCacheKey(uid), func(ctx context.Context) (*model.UserDTO, error) {
+		user, err := s.repo.GetByID(ctx, uid)
+		if err != nil {
+			return nil, err
 		}
-	}
-
-	user, err := s.repo.GetByID(ctx, uid)
+		if user == nil {
+			return nil, nil
+		}
+		return newUserDTO(user), nil
+	})
 	if err != nil {
 		return nil, errors.New("系统错误")
 	}
-	if user == nil {
+	if dto == nil {
 		return nil, errors.New("用户不存在")
 	}
-
-	dto := &model.UserDTO{
-		Uid:      user.Uid,
-		Username: user.Username,
-		Email:    user.Email,
-		Avatar:   user.Avatar,
-		Role:     user.Role,
-		Status:   user.Status,
-		Dateline: user.Dateline,
-	}
-
-	// Write Cache
-	if bytes, _ := json.Marshal(dto); bytes != nil {
-		if s.l1 != nil {
-			s.l1.Set(key, bytes)
-		}
-	}
-
 	return dto, nil
 }
 
@@ -261,8 +231,7 @@ func (s *UserService) GetUsersByIDs(ctx context.Context, uids []int64) (map[int6
 	}
 
 	unique := make(map[int64]struct{}, len(uids))
-	missing := make([]int64, 0, len(uids))
-
+	keys := make([]string, 0, len(uids))
 	for _, uid := range uids {
 		if uid <= 0 {
 			continue
@@ -271,18 +240,21 @@ func (s *UserService) GetUsersByIDs(ctx context.Context, uids []int64) (map[int6
 			continue
 		}
 		unique[uid] = struct{}{}
+		keys = append(keys, userCacheKey(uid))
+	}
 
-		key := fmt.Sprintf("user:%d", uid)
-		if s.l1 != nil {
-			if data, ok := s.l1.Get(key); ok && data != nil {
-				var dto model.UserDTO
-				if err := json.Unmarshal(data, &dto); err == nil {
-					result[uid] = &dto
-					continue
-				}
-			}
+	// L1 + L2 批量命中（负缓存命中的 uid 直接跳过）
+	cached := s.cache.GetMulti(ctx, keys)
+	missing := make([]int64, 0, len(keys))
+	for uid := range unique {
+		dto, ok := cached[userCacheKey(uid)]
+		if !ok {
+			missing = append(missing, uid)
+			continue
+		}
+		if dto != nil {
+			result[uid] = dto
 		}
-		missing = append(missing, uid)
 	}
 
 	if len(missing) == 0 {
@@ -298,34 +270,26 @@ func (s *UserService) GetUsersByIDs(ctx context.Context, uids []int64) (map[int6
 	}
 
 	for _, u := range users {
-		dto := &model.UserDTO{
-			Uid:      u.Uid,
-			Username: u.Username,
-			Email:    u.Email,
-			Avatar:   u.Avatar,
-			Role:     u.Role,
-			Status:   u.Status,
-			Dateline: u.Dateline,
-		}
+		dto := newUserDTO(u)
 		result[u.Uid] = dto
-
-		if data, _ := json.Marshal(dto); data != nil {
-			if s.l1 != nil {
-				s.l1.Set(fmt.Sprintf("user:%d", u.Uid), data)
-			}
-		}
+		s.cache.Set(ctx, userCacheKey(u.Uid), dto, 0)
 	}
 
 	return result, nil
 }
 
-// generateJWT 生成JWT（简化版）
-func generateJWT(uid int64, role int, cfg *config.JWTConfig) (string, error) {
-	claims := jwt.MapClaims{
-		"uid":  uid,
-		"role": role,
-		"exp":  time.Now().Add(time.Duration(cfg.Expiry) * time.Second).Unix(),
+func userCacheKey(uid int64) string {
+	return fmt.Sprintf("user:%d", uid)
+}
+
+func newUserDTO(u *model.User) *model.UserDTO {
+	return &model.UserDTO{
+		Uid:      u.Uid,
+		Username: u.Username,
+		Email:    u.Email,
+		Avatar:   u.Avatar,
+		Role:     u.Role,
+		Status:   u.Status,
+		Dateline: u.Dateline,
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(cfg.Secret))
 }
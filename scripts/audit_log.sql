@@ -0,0 +1,11 @@
+-- 审计日志：安全相关事件（登录锁定/解锁等），只追加
+CREATE TABLE IF NOT EXISTS audit_log (
+  id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+  action VARCHAR(32) NOT NULL,               -- 如 login.lockout / login.unlock
+  uid BIGINT UNSIGNED NOT NULL DEFAULT 0,    -- 操作人，0 为系统
+  target VARCHAR(100) NOT NULL DEFAULT '',   -- 用户名或 IP
+  ip VARCHAR(45) NOT NULL DEFAULT '',        -- 请求来源 IP
+  detail VARCHAR(255) NOT NULL DEFAULT '',
+  dateline INT UNSIGNED NOT NULL,
+  KEY idx_action_id (action, id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
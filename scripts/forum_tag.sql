@@ -10,9 +10,11 @@ CREATE TABLE IF NOT EXISTS forum (
   today INT UNSIGNED NOT NULL DEFAULT 0,
   posts INT UNSIGNED NOT NULL DEFAULT 0,
   status TINYINT UNSIGNED NOT NULL DEFAULT 0,
+  deleted_at INT UNSIGNED NOT NULL DEFAULT 0,  -- 移入回收站时间
   created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
   updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
   KEY idx_parent (parent),
+  KEY idx_deleted_at (deleted_at),
   KEY idx_order (`order`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
 
@@ -24,9 +26,11 @@ CREATE TABLE IF NOT EXISTS tag (
   threads INT UNSIGNED NOT NULL DEFAULT 0,
   `view` INT UNSIGNED NOT NULL DEFAULT 0,
   status TINYINT UNSIGNED NOT NULL DEFAULT 0,
+  deleted_at INT UNSIGNED NOT NULL DEFAULT 0,  -- 移入回收站时间
   created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
   updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-  KEY idx_slug (slug),
+  UNIQUE KEY uk_slug (slug),
+  KEY idx_deleted_at (deleted_at),
   KEY idx_threads (threads),
   KEY idx_view (`view`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -42,6 +46,14 @@ CREATE TABLE IF NOT EXISTS thread_tag (
   UNIQUE KEY uk_tid_tagid (tid, tag_id)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
 
+-- TagAlias 同义词表（alias → tag_id）
+CREATE TABLE IF NOT EXISTS tag_alias (
+  alias VARCHAR(30) NOT NULL PRIMARY KEY,
+  tag_id INT UNSIGNED NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  KEY idx_tag_id (tag_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
 -- ForumAccess 权限表
 CREATE TABLE IF NOT EXISTS forum_access (
   id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
@@ -65,9 +77,11 @@ CREATE TABLE IF NOT EXISTS tag (
   threads INT UNSIGNED NOT NULL DEFAULT 0,
   view INT UNSIGNED NOT NULL DEFAULT 0,
   status TINYINT UNSIGNED NOT NULL DEFAULT 0,
+  deleted_at INT UNSIGNED NOT NULL DEFAULT 0,  -- 移入回收站时间
   created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
   updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-  KEY idx_slug (slug),
+  UNIQUE KEY uk_slug (slug),
+  KEY idx_deleted_at (deleted_at),
   KEY idx_threads (threads),
   KEY idx_view (view)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
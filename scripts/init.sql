@@ -11,11 +11,19 @@ CREATE TABLE IF NOT EXISTS thread (
   replies INT UNSIGNED NOT NULL DEFAULT 0,
   dateline INT UNSIGNED NOT NULL,
   lastpost INT UNSIGNED NOT NULL,
-  status TINYINT UNSIGNED NOT NULL DEFAULT 0,
+  status TINYINT UNSIGNED NOT NULL DEFAULT 0,  -- 0 已发布, 1 草稿, 2 待审核, 3 定时发布, 4 隐藏, 5 回收站
+  publish_at INT UNSIGNED NOT NULL DEFAULT 0,  -- 定时发布时间
+  deleted_at INT UNSIGNED NOT NULL DEFAULT 0,  -- 移入回收站时间
+  prev_status TINYINT UNSIGNED NOT NULL DEFAULT 0,  -- 移入回收站前的状态
+  redirect_tid BIGINT UNSIGNED NOT NULL DEFAULT 0,  -- 移动后的跳转占位，指向新主题
   created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
   updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
   KEY idx_fid_lastpost (fid, lastpost),
-  KEY idx_uid (uid)
+  KEY idx_fid_dateline (fid, dateline),
+  KEY idx_status_dateline (status, dateline),
+  KEY idx_uid (uid),
+  KEY idx_status_publish_at (status, publish_at),
+  KEY idx_deleted_at (deleted_at)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
 
 -- Thread 内容表
@@ -25,3 +33,17 @@ CREATE TABLE IF NOT EXISTS thread_data (
   created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
   updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- Thread 修订记录（每次编辑后的完整快照）
+CREATE TABLE IF NOT EXISTS thread_revision (
+  id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+  tid BIGINT UNSIGNED NOT NULL,
+  rev INT UNSIGNED NOT NULL,                  -- 主题内递增版本号
+  uid BIGINT UNSIGNED NOT NULL,               -- 编辑人
+  subject VARCHAR(120) NOT NULL,
+  message MEDIUMTEXT,
+  action TINYINT UNSIGNED NOT NULL DEFAULT 1, -- 0 创建, 1 编辑, 2 回滚
+  from_rev INT UNSIGNED NOT NULL DEFAULT 0,   -- 回滚来源版本
+  dateline INT UNSIGNED NOT NULL,
+  UNIQUE KEY uk_tid_rev (tid, rev)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
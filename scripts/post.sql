@@ -0,0 +1,15 @@
+-- WellCMS Go Headless - Post Table Schema
+-- 回帖表（主题首帖内容仍在 thread_data）
+
+CREATE TABLE IF NOT EXISTS post (
+  pid BIGINT UNSIGNED PRIMARY KEY,
+  tid BIGINT UNSIGNED NOT NULL,
+  uid BIGINT UNSIGNED NOT NULL,
+  message MEDIUMTEXT,
+  dateline INT UNSIGNED NOT NULL,
+  status TINYINT UNSIGNED NOT NULL DEFAULT 0,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  KEY idx_tid_pid (tid, pid),
+  KEY idx_uid (uid)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
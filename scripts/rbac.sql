@@ -0,0 +1,31 @@
+-- 角色权限：角色 = 一组权限字符串；用户角色可全站生效（fid=0）或限定版块（版主，含子版块）
+CREATE TABLE IF NOT EXISTS role (
+  role_id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+  name VARCHAR(32) NOT NULL,
+  title VARCHAR(60) NOT NULL DEFAULT '',
+  permissions VARCHAR(1024) NOT NULL DEFAULT '',  -- 逗号分隔，支持 "*" 与 "thread.*"
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  UNIQUE KEY uk_name (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS user_role (
+  uid BIGINT UNSIGNED NOT NULL,
+  role_id INT UNSIGNED NOT NULL,
+  fid INT UNSIGNED NOT NULL DEFAULT 0,  -- 0 全站，>0 仅该版块及其子版块
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (uid, role_id, fid),
+  KEY idx_role_id (role_id),
+  KEY idx_fid (fid)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 内置角色
+INSERT INTO role (role_id, name, title, permissions) VALUES
+  (1, 'admin', '管理员', '*'),
+  (2, 'editor', '编辑', 'thread.*,post.*,tag.*,trash.manage'),
+  (3, 'moderator', '版主', 'thread.update,thread.delete,thread.move,thread.publish,post.update,post.delete')
+ON DUPLICATE KEY UPDATE role_id = role_id;
+
+-- 原 user.role=1 的管理员迁移为 admin 角色
+INSERT IGNORE INTO user_role (uid, role_id, fid)
+SELECT uid, 1, 0 FROM `user` WHERE role = 1;
@@ -0,0 +1,14 @@
+-- 回收站：主题/版块/标签软删除，到期后由定时任务彻底清除
+-- 主题移入回收站时 status=5，prev_status 记录原状态用于恢复
+ALTER TABLE thread
+  ADD COLUMN deleted_at INT UNSIGNED NOT NULL DEFAULT 0 AFTER publish_at,
+  ADD COLUMN prev_status TINYINT UNSIGNED NOT NULL DEFAULT 0 AFTER deleted_at,
+  ADD KEY idx_deleted_at (deleted_at);
+
+ALTER TABLE forum
+  ADD COLUMN deleted_at INT UNSIGNED NOT NULL DEFAULT 0 AFTER status,
+  ADD KEY idx_deleted_at (deleted_at);
+
+ALTER TABLE tag
+  ADD COLUMN deleted_at INT UNSIGNED NOT NULL DEFAULT 0 AFTER status,
+  ADD KEY idx_deleted_at (deleted_at);
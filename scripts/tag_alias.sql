@@ -0,0 +1,7 @@
+-- 标签同义词：添加同义词名称的标签时归并到目标标签（如 golang → go）
+CREATE TABLE IF NOT EXISTS tag_alias (
+  alias VARCHAR(30) NOT NULL PRIMARY KEY,
+  tag_id INT UNSIGNED NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  KEY idx_tag_id (tag_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
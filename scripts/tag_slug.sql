@@ -0,0 +1,5 @@
+-- 标签 slug 唯一：先回填历史空 slug（go run ./cmd/tagslug），再加唯一索引
+-- 并发创建同一 slug 时由唯一索引拒绝，服务端改用下一个数字后缀重试
+ALTER TABLE tag
+  DROP KEY idx_slug,
+  ADD UNIQUE KEY uk_slug (slug);
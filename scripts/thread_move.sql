@@ -0,0 +1,3 @@
+-- 主题移动：原版块可保留跳转占位（redirect_tid 指向被移动的主题）
+ALTER TABLE thread
+  ADD COLUMN redirect_tid BIGINT UNSIGNED NOT NULL DEFAULT 0 AFTER prev_status;
@@ -0,0 +1,13 @@
+-- 主题修订记录：编辑历史、差异对比与回滚
+CREATE TABLE IF NOT EXISTS thread_revision (
+  id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+  tid BIGINT UNSIGNED NOT NULL,
+  rev INT UNSIGNED NOT NULL,                  -- 主题内递增版本号
+  uid BIGINT UNSIGNED NOT NULL,               -- 编辑人
+  subject VARCHAR(120) NOT NULL,
+  message MEDIUMTEXT,
+  action TINYINT UNSIGNED NOT NULL DEFAULT 1, -- 0 创建, 1 编辑, 2 回滚
+  from_rev INT UNSIGNED NOT NULL DEFAULT 0,   -- 回滚来源版本
+  dateline INT UNSIGNED NOT NULL,
+  UNIQUE KEY uk_tid_rev (tid, rev)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,4 @@
+-- 列表排序：dateline 索引供 newest 排序与 hot 排序的时间窗口使用
+ALTER TABLE thread
+  ADD KEY idx_fid_dateline (fid, dateline),
+  ADD KEY idx_status_dateline (status, dateline);
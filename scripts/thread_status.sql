@@ -0,0 +1,5 @@
+-- 主题状态流转：草稿/待审核/定时发布/已发布/隐藏/回收站
+-- status: 0 已发布, 1 草稿, 2 待审核, 3 定时发布, 4 隐藏, 5 回收站
+ALTER TABLE thread
+  ADD COLUMN publish_at INT UNSIGNED NOT NULL DEFAULT 0 AFTER status,
+  ADD KEY idx_status_publish_at (status, publish_at);